@@ -1,30 +1,42 @@
 package common
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // 定义 Token 的秘钥 (生产环境应该从 Config 读取，这里先演示)
 // ⚠️ 注意：这个 key 绝对不能泄露，一旦泄露，别人就能伪造身份
 var jwtKey = []byte("my_secret_key_todo_app") 
 
-// 自定义 Claims (载荷)，这里我们只存 UserID
+// 自定义 Claims (载荷)，存用户的公开 ID 和签发时的 Token 版本
+// 用户的 TokenVersion 变化后（如注销账号、强制改密），旧 Token 全部失效；
+// RegisteredClaims.ID（jti）标识单个会话，退出登录时只作废这一个
 type MyCustomClaims struct {
-	UserID uint `json:"user_id"`
+	UserID       string `json:"user_id"`
+	TokenVersion uint   `json:"token_version"`
 	jwt.RegisteredClaims
 }
 
+// TokenTTL Token 的有效期，Cookie 登录时 Cookie 的有效期与之相同
+const TokenTTL = 24 * time.Hour
+
 // 1. 生成 Token
-func GenerateToken(userID uint) (string, error) {
+func GenerateToken(userID string, tokenVersion uint) (string, error) {
 	// 设置有效期，比如 24 小时
-	expirationTime := time.Now().Add(24 * time.Hour)
+	expirationTime := time.Now().Add(TokenTTL)
 
 	claims := &MyCustomClaims{
-		UserID: userID,
+		UserID:       userID,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			Issuer:    "go-todo",
 		},
@@ -51,4 +63,17 @@ func ParseToken(tokenString string) (*MyCustomClaims, error) {
 	}
 
 	return nil, errors.New("invalid token")
-}
\ No newline at end of file
+}
+
+// CSRFToken Cookie 登录时与会话绑定的 CSRF Token：用密钥对会话的 Token 签名，
+// 其他会话（包括攻击者自己登录得到的）的 CSRF Token 不能用在这个会话上
+func CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, jwtKey)
+	mac.Write([]byte("csrf:" + sessionToken))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CheckCSRFToken csrfToken 是否属于 sessionToken 对应的会话
+func CheckCSRFToken(sessionToken, csrfToken string) bool {
+	return hmac.Equal([]byte(CSRFToken(sessionToken)), []byte(csrfToken))
+}
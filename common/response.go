@@ -1,6 +1,8 @@
 package common
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -27,10 +29,28 @@ func Success(c *gin.Context, data interface{}) {
 }
 
 // 失败返回
+// 请求的 context 已经结束时，数据库等操作返回的错误只是 context 结束的结果，
+// 不管调用方传入什么错误码，都统一返回 504（超过处理时限）或 503（请求被取消）
 func Error(c *gin.Context, code int, msg string) {
+	if c.Request != nil {
+		if ctxCode, ctxMsg, ok := ContextError(c.Request.Context().Err()); ok {
+			code, msg = ctxCode, ctxMsg
+		}
+	}
 	c.JSON(http.StatusOK, Response{
 		Code: code,
 		Msg:  msg,
 		Data: nil,
 	})
-}
\ No newline at end of file
+}
+
+// ContextError 把 context 结束的错误转换成错误码和提示，不是 context 错误时 ok 为 false
+func ContextError(err error) (code int, msg string, ok bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return 504, "请求处理超时，请稍后重试", true
+	case errors.Is(err, context.Canceled):
+		return 503, "请求已取消", true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// CORSConfig 跨域设置
+type CORSConfig struct {
+	// 允许的来源，如 "https://app.example.com"；"https://*.example.com" 匹配所有子域名，
+	// "*" 允许任意来源（此时不允许携带凭据）。为空时不允许跨域
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// 浏览器中的脚本可以读取的响应头
+	ExposedHeaders []string
+	// 是否允许携带 Cookie 等凭据
+	AllowCredentials bool
+	// 预检结果的缓存时间
+	MaxAge time.Duration
+}
+
+// CORS 一组接口的跨域设置：读取 cors.*，cors.groups.<name>.* 中设置的项覆盖同名的默认值
+func CORS(name string) CORSConfig {
+	viper.SetDefault("cors.allowed_origins", []string{})
+	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE"})
+	// X-Share-Password 用于打开有密码的分享，Last-Event-ID 用于事件流断线重连
+	viper.SetDefault("cors.allowed_headers", []string{"Authorization", "Content-Type", "Idempotency-Key", "X-Request-ID", "X-CSRF-Token", "X-Share-Password", "Last-Event-ID", "traceparent"})
+	viper.SetDefault("cors.exposed_headers", []string{"X-Request-ID", "Idempotent-Replayed", "RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "traceparent", "Content-Disposition"})
+	viper.SetDefault("cors.allow_credentials", true)
+	viper.SetDefault("cors.max_age", "10m")
+
+	key := func(k string) string {
+		if group := "cors.groups." + name + "." + k; name != "" && viper.IsSet(group) {
+			return group
+		}
+		return "cors." + k
+	}
+	return CORSConfig{
+		AllowedOrigins:   stringList(key("allowed_origins")),
+		AllowedMethods:   stringList(key("allowed_methods")),
+		AllowedHeaders:   stringList(key("allowed_headers")),
+		ExposedHeaders:   stringList(key("exposed_headers")),
+		AllowCredentials: viper.GetBool(key("allow_credentials")),
+		MaxAge:           viper.GetDuration(key("max_age")),
+	}
+}
+
+// stringList 读取列表配置；环境变量中可以用逗号或空格分隔
+func stringList(key string) []string {
+	var list []string
+	for _, item := range viper.GetStringSlice(key) {
+		for _, s := range strings.Split(item, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+// MatchOrigin 来源是否与 AllowedOrigins 中列出的某一项匹配，不区分大小写；不考虑 "*"。
+// 带 * 的来源匹配一段或多段域名（或端口），* 只能对应字母、数字、点和连字符：
+// https://*.example.com 匹配 https://a.example.com 和 https://a.b.example.com，
+// 不匹配 https://example.com 和 https://evil.com/.example.com
+func (c CORSConfig) MatchOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.AllowedOrigins {
+		allowed = strings.ToLower(strings.TrimSuffix(allowed, "/"))
+		if allowed == "*" {
+			continue
+		}
+		prefix, suffix, ok := strings.Cut(allowed, "*")
+		if !ok {
+			if origin == allowed {
+				return true
+			}
+			continue
+		}
+		if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+		if strings.Trim(origin[len(prefix):len(origin)-len(suffix)], "abcdefghijklmnopqrstuvwxyz0123456789.-") == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -2,13 +2,23 @@ package config
 
 import (
 	"fmt"
-	"go-todo/models"
+	"go-todo/metrics"
+	"go-todo/migrations"
+	"go-todo/tracing"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/glebarez/sqlite"
 	"github.com/spf13/viper"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
@@ -22,38 +32,214 @@ func InitConfig() {
     viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_")) // 将 database.host 映射为 DATABASE_HOST
     // -----------------------
 
-    if err := viper.ReadInConfig(); err != nil {
+    err := viper.ReadInConfig()
+    // 日志级别和格式可能来自配置文件，读取之后再初始化日志
+    InitLogger()
+    if err != nil {
         // 如果找不到配置文件且没有环境变量，才报错
-        fmt.Printf("警告: 未找到配置文件: %v，将尝试从环境变量读取\n", err)
+        slog.Warn("未找到配置文件，将尝试从环境变量读取", "error", err)
     }
 }
 
-func ConnectDatabase() {
-	// 从 Viper 获取配置
+// 支持的数据库驱动
+const (
+	// DriverSQLite 本地 SQLite 文件，不依赖任何外部服务
+	DriverSQLite = "sqlite"
+	// DriverMySQL MySQL
+	DriverMySQL = "mysql"
+	// DriverPostgres PostgreSQL
+	DriverPostgres = "postgres"
+)
+
+// DatabaseDriver 数据库驱动：sqlite、mysql（默认）或 postgres
+func DatabaseDriver() string {
+	viper.SetDefault("database.driver", DriverMySQL)
+	return viper.GetString("database.driver")
+}
+
+// DatabaseDSN 数据库连接串。配置了 database.dsn 时原样使用，否则根据驱动用各项配置拼接
+func DatabaseDSN(driver string) (string, error) {
+	if dsn := viper.GetString("database.dsn"); dsn != "" {
+		return dsn, nil
+	}
+
 	username := viper.GetString("database.username")
 	password := viper.GetString("database.password")
 	host := viper.GetString("database.host")
 	port := viper.GetInt("database.port")
 	dbname := viper.GetString("database.dbname")
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		username, password, host, port, dbname)
+	switch driver {
+	case DriverSQLite:
+		viper.SetDefault("database.path", "go-todo.db")
+		path := viper.GetString("database.path")
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return "", err
+			}
+		}
+		// 打开外键约束；WAL 模式下读写互不阻塞；写锁冲突时等待而不是立即报错
+		return path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", nil
+	case DriverMySQL:
+		if port == 0 {
+			port = 3306
+		}
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			username, password, host, port, dbname), nil
+	case DriverPostgres:
+		if port == 0 {
+			port = 5432
+		}
+		viper.SetDefault("database.sslmode", "disable")
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(username, password),
+			Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+			Path:     "/" + dbname,
+			RawQuery: url.Values{"sslmode": {viper.GetString("database.sslmode")}}.Encode(),
+		}
+		return dsn.String(), nil
+	default:
+		return "", fmt.Errorf("不支持的数据库类型: %s", driver)
+	}
+}
+
+// PoolConfig 数据库连接池配置
+type PoolConfig struct {
+	// 最大打开连接数，0 表示不限制
+	MaxOpenConns int
+	// 最大空闲连接数
+	MaxIdleConns int
+	// 连接最长使用时间，0 表示不限制
+	ConnMaxLifetime time.Duration
+	// 连接最长空闲时间，0 表示不限制
+	ConnMaxIdleTime time.Duration
+}
 
-	database, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+// DatabasePool 连接池配置，默认最多 25 个连接、保留 10 个空闲连接，连接使用 30 分钟、空闲 5 分钟后关闭
+func DatabasePool() PoolConfig {
+	viper.SetDefault("database.max_open_conns", 25)
+	viper.SetDefault("database.max_idle_conns", 10)
+	viper.SetDefault("database.conn_max_lifetime", "30m")
+	viper.SetDefault("database.conn_max_idle_time", "5m")
+	return PoolConfig{
+		MaxOpenConns:    viper.GetInt("database.max_open_conns"),
+		MaxIdleConns:    viper.GetInt("database.max_idle_conns"),
+		ConnMaxLifetime: viper.GetDuration("database.conn_max_lifetime"),
+		ConnMaxIdleTime: viper.GetDuration("database.conn_max_idle_time"),
+	}
+}
+
+// openDatabase 按驱动打开数据库并设置连接池
+func openDatabase(driver string) (*gorm.DB, error) {
+	dsn, err := DatabaseDSN(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialector gorm.Dialector
+	switch driver {
+	case DriverSQLite:
+		dialector = sqlite.Open(dsn)
+	case DriverMySQL:
+		dialector = mysql.Open(dsn)
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+	}
+
+	database, err := gorm.Open(dialector, &gorm.Config{
+		Logger: GormLogger(),
 	})
+	if err != nil {
+		return nil, err
+	}
 
+	sqlDB, err := database.DB()
 	if err != nil {
-		fmt.Printf("数据库连接失败详情: %v\n", err)
+		return nil, err
+	}
+	pool := DatabasePool()
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	return database, nil
+}
+
+// ConnectDatabase 根据 database.driver 连接数据库
+func ConnectDatabase() {
+	driver := DatabaseDriver()
+	database, err := openDatabase(driver)
+	if err != nil {
+		slog.Error("数据库连接失败", "driver", driver, "error", err)
 		panic("🔥 无法连接数据库！")
 	}
 
-	err = database.AutoMigrate(&models.User{},&models.Todo{})
-    
-    if err != nil {
-        fmt.Printf("自动迁移失败: %v\n", err)
+    // SQL 耗时和连接池统计输出到 /metrics
+    if err := metrics.InstrumentGORM(database); err != nil {
+        slog.Warn("注册数据库指标失败", "error", err)
+    }
+    // 每条 SQL 一个 span
+    if err := tracing.InstrumentGORM(database); err != nil {
+        slog.Warn("注册数据库链路追踪失败", "error", err)
     }
-
     DB = database
-    fmt.Println("✅ 数据库连接成功，表结构同步完成！")
-}
\ No newline at end of file
+    slog.Info("数据库连接成功", "driver", driver)
+}
+
+// CloseDatabase 关闭数据库连接池，服务退出前调用
+func CloseDatabase() error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// 启动时处理未执行迁移的方式
+const (
+	// MigrateCheck 有未执行的迁移时拒绝启动，需要先运行 migrate up
+	MigrateCheck = "check"
+	// MigrateAuto 启动时自动执行未执行的迁移
+	MigrateAuto = "auto"
+)
+
+// MigrateMode 启动时如何处理未执行的迁移。
+// SQLite 是单机零依赖模式，默认 auto，直接运行二进制即可；其他数据库默认 check
+func MigrateMode() string {
+	if DatabaseDriver() == DriverSQLite {
+		viper.SetDefault("database.migrate", MigrateAuto)
+	} else {
+		viper.SetDefault("database.migrate", MigrateCheck)
+	}
+	return viper.GetString("database.migrate")
+}
+
+// EnsureMigrated 在开始服务之前确认表结构是最新的：
+// auto 模式下执行未执行的迁移，check 模式下有未执行的迁移时返回错误
+func EnsureMigrated() error {
+	m, err := migrations.New(DB)
+	if err != nil {
+		return err
+	}
+	switch MigrateMode() {
+	case MigrateAuto:
+		done, err := m.Up()
+		for _, mig := range done {
+			slog.Info("已执行迁移", "version", mig.Version, "name", mig.Name)
+		}
+		return err
+	case MigrateCheck:
+		pending, err := m.Pending()
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			last := pending[len(pending)-1]
+			return fmt.Errorf("有 %d 个迁移尚未执行（最新为 %04d_%s），请先运行 migrate up", len(pending), last.Version, last.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("database.migrate 只能是 %s 或 %s", MigrateCheck, MigrateAuto)
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"go-todo/events"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Events 实时事件中心，InitEvents 会按配置重新创建
+var Events = events.NewHub(1000)
+
+// InitEvents 按 events.replay_size 创建事件中心
+func InitEvents() {
+	viper.SetDefault("events.replay_size", 1000)
+	Events = events.NewHub(viper.GetInt("events.replay_size"))
+}
+
+// EventsHeartbeat 事件流的心跳间隔，默认 15 秒
+func EventsHeartbeat() time.Duration {
+	viper.SetDefault("events.heartbeat", "15s")
+	return viper.GetDuration("events.heartbeat")
+}
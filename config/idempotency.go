@@ -0,0 +1,13 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// IdempotencyTTL Idempotency-Key 的保存时间，默认 24 小时
+func IdempotencyTTL() time.Duration {
+	viper.SetDefault("idempotency.ttl", "24h")
+	return viper.GetDuration("idempotency.ttl")
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"go-todo/logging"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm/logger"
+)
+
+// InitLogger 按 log.level（debug、info、warn、error，默认 info）和
+// log.format（json 或 text，默认 json）设置 slog 的默认日志，输出到标准输出
+func InitLogger() {
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.format", logging.FormatJSON)
+	level := logging.ParseLevel(viper.GetString("log.level"))
+	slog.SetDefault(logging.New(os.Stdout, level, viper.GetString("log.format")))
+}
+
+// GormLogger GORM 使用的日志：log.level 为 debug 时记录每条 SQL，否则只记录出错和
+// 超过 log.slow_query（默认 200ms）的 SQL。SQL 中的参数默认用 ? 代替，避免把密码哈希等数据
+// 写进日志，log.sql_params 为 true 时记录参数
+func GormLogger() logger.Interface {
+	viper.SetDefault("log.slow_query", "200ms")
+	level := logger.Warn
+	if logging.ParseLevel(viper.GetString("log.level")) <= slog.LevelDebug {
+		level = logger.Info
+	}
+	return logger.NewSlogLogger(slog.Default(), logger.Config{
+		LogLevel:                  level,
+		SlowThreshold:             viper.GetDuration("log.slow_query"),
+		ParameterizedQueries:      !viper.GetBool("log.sql_params"),
+		IgnoreRecordNotFoundError: true,
+	})
+}
@@ -0,0 +1,22 @@
+package config
+
+import "github.com/spf13/viper"
+
+// MetricsEnabled 是否开放 /metrics，默认开放
+func MetricsEnabled() bool {
+	viper.SetDefault("metrics.enabled", true)
+	return viper.GetBool("metrics.enabled")
+}
+
+// MetricsToken 抓取 /metrics 需要携带的 Bearer Token。
+// /metrics 暴露了接口和数据库的使用情况，没有配置时默认不开放
+func MetricsToken() string {
+	return viper.GetString("metrics.token")
+}
+
+// MetricsAllowUnauthenticated 没有配置 metrics.token 时是否仍然开放 /metrics，
+// 只应该在 /metrics 无法从公网访问时打开，默认关闭
+func MetricsAllowUnauthenticated() bool {
+	viper.SetDefault("metrics.allow_unauthenticated", false)
+	return viper.GetBool("metrics.allow_unauthenticated")
+}
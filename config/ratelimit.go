@@ -0,0 +1,59 @@
+package config
+
+import (
+	"go-todo/ratelimit"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// RateLimiter 限流令牌桶的存储，InitRateLimiter 会按配置重新创建
+var RateLimiter ratelimit.Store = ratelimit.NewMemoryStore()
+
+// InitRateLimiter 按 ratelimit.store 创建令牌桶存储：memory（默认，只在单个实例内限流）
+// 或 database（保存在数据库中，多个实例共享），需要在 ConnectDatabase 之后调用
+func InitRateLimiter() {
+	viper.SetDefault("ratelimit.store", "memory")
+	if viper.GetString("ratelimit.store") == "database" {
+		RateLimiter = ratelimit.NewDatabaseStore(DB)
+		return
+	}
+	RateLimiter = ratelimit.NewMemoryStore()
+}
+
+// RateLimit 一组接口的限流设置，读取 ratelimit.<name>.requests/period/burst，
+// requests 为 0 时不限流；ratelimit.enabled 为 false 时所有分组都不限流
+func RateLimit(name string) ratelimit.Limit {
+	viper.SetDefault("ratelimit.enabled", true)
+	// 登录注册按 IP 限制，防止暴力破解
+	viper.SetDefault("ratelimit.auth.requests", 10)
+	viper.SetDefault("ratelimit.auth.period", "1m")
+	viper.SetDefault("ratelimit.auth.burst", 10)
+	// 分享链接按 IP 限制
+	viper.SetDefault("ratelimit.public.requests", 60)
+	viper.SetDefault("ratelimit.public.period", "1m")
+	viper.SetDefault("ratelimit.public.burst", 30)
+	// 登录后的接口按用户限制
+	viper.SetDefault("ratelimit.api.requests", 600)
+	viper.SetDefault("ratelimit.api.period", "1m")
+	viper.SetDefault("ratelimit.api.burst", 100)
+
+	if !viper.GetBool("ratelimit.enabled") {
+		return ratelimit.Limit{}
+	}
+	period := viper.GetDuration("ratelimit." + name + ".period")
+	if period == 0 {
+		period = time.Minute
+	}
+	return ratelimit.Limit{
+		Requests: viper.GetInt("ratelimit." + name + ".requests"),
+		Period:   period,
+		Burst:    viper.GetInt("ratelimit." + name + ".burst"),
+	}
+}
+
+// TrustedProxies 可以信任其 X-Forwarded-For 的反向代理地址（IP 或 CIDR）。
+// 默认不信任任何代理，客户端 IP 使用 TCP 连接的地址，避免伪造请求头绕过按 IP 的限流
+func TrustedProxies() []string {
+	return viper.GetStringSlice("server.trusted_proxies")
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// RequestTimeout 一组接口的请求处理时限，读取 server.timeouts.<name>，
+// 没有单独配置时使用 server.timeouts.default（默认 10 秒）；0 表示不限制
+func RequestTimeout(name string) time.Duration {
+	viper.SetDefault("server.timeouts.default", "10s")
+	// 附件上传下载和数据导出需要更长的时间
+	viper.SetDefault("server.timeouts.attachments", "2m")
+	viper.SetDefault("server.timeouts.export", "1m")
+	// 探针自己也有超时，检查需要尽快返回
+	viper.SetDefault("server.timeouts.health", "5s")
+
+	key := "server.timeouts." + name
+	if !viper.IsSet(key) {
+		key = "server.timeouts.default"
+	}
+	return viper.GetDuration(key)
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	// 监听地址，如 ":8080"
+	Addr string
+	// 读取请求头的时限
+	ReadHeaderTimeout time.Duration
+	// 读取整个请求（包括请求体）的时限
+	ReadTimeout time.Duration
+	// 写响应的时限，需要比最长的请求处理时限更长，事件流不受限制
+	WriteTimeout time.Duration
+	// keep-alive 连接的最长空闲时间
+	IdleTimeout time.Duration
+	// 收到退出信号后等待进行中的请求完成的最长时间
+	ShutdownTimeout time.Duration
+	// TLS 证书和私钥文件，都为空时使用 HTTP
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// Server HTTP 服务配置，默认监听 8080 端口
+func Server() ServerConfig {
+	viper.SetDefault("server.port", "8080")
+	viper.SetDefault("server.read_header_timeout", "10s")
+	viper.SetDefault("server.read_timeout", "2m")
+	viper.SetDefault("server.write_timeout", "3m")
+	viper.SetDefault("server.idle_timeout", "2m")
+	viper.SetDefault("server.shutdown_timeout", "30s")
+	return ServerConfig{
+		Addr:              ":" + viper.GetString("server.port"),
+		ReadHeaderTimeout: viper.GetDuration("server.read_header_timeout"),
+		ReadTimeout:       viper.GetDuration("server.read_timeout"),
+		WriteTimeout:      viper.GetDuration("server.write_timeout"),
+		IdleTimeout:       viper.GetDuration("server.idle_timeout"),
+		ShutdownTimeout:   viper.GetDuration("server.shutdown_timeout"),
+		TLSCertFile:       viper.GetString("server.tls.cert_file"),
+		TLSKeyFile:        viper.GetString("server.tls.key_file"),
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// SessionCookieConfig Cookie 登录设置
+type SessionCookieConfig struct {
+	// 是否允许 Cookie 登录，关闭时只能使用 Authorization 头
+	Enabled bool
+	// 保存 Token 的 HttpOnly Cookie
+	Name string
+	// 保存 CSRF Token 的 Cookie，前端脚本可以读取
+	CSRFName string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// SessionCookie 读取 session.cookie.*
+func SessionCookie() SessionCookieConfig {
+	viper.SetDefault("session.cookie.enabled", false)
+	viper.SetDefault("session.cookie.name", "session")
+	viper.SetDefault("session.cookie.csrf_name", "csrf_token")
+	viper.SetDefault("session.cookie.secure", true)
+	viper.SetDefault("session.cookie.same_site", "lax")
+
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(viper.GetString("session.cookie.same_site")) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		// 前端和 API 不是同一个站点时需要，浏览器要求同时设置 Secure
+		sameSite = http.SameSiteNoneMode
+	}
+	return SessionCookieConfig{
+		Enabled:  viper.GetBool("session.cookie.enabled"),
+		Name:     viper.GetString("session.cookie.name"),
+		CSRFName: viper.GetString("session.cookie.csrf_name"),
+		Domain:   viper.GetString("session.cookie.domain"),
+		Secure:   viper.GetBool("session.cookie.secure"),
+		SameSite: sameSite,
+	}
+}
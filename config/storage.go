@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"go-todo/storage"
+	"log/slog"
+
+	"github.com/spf13/viper"
+)
+
+// Storage 附件存储后端
+var Storage storage.Storage
+
+// ConnectStorage 根据 storage.driver 初始化附件存储：local（默认）或 s3
+func ConnectStorage() {
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.local.path", "uploads")
+	viper.SetDefault("storage.s3.region", "us-east-1")
+
+	var err error
+	driver := viper.GetString("storage.driver")
+	switch driver {
+	case "local":
+		Storage, err = storage.NewLocal(viper.GetString("storage.local.path"))
+	case "s3":
+		Storage, err = storage.NewS3(storage.S3Config{
+			Endpoint:  viper.GetString("storage.s3.endpoint"),
+			AccessKey: viper.GetString("storage.s3.access_key"),
+			SecretKey: viper.GetString("storage.s3.secret_key"),
+			Bucket:    viper.GetString("storage.s3.bucket"),
+			Region:    viper.GetString("storage.s3.region"),
+			UseSSL:    viper.GetBool("storage.s3.use_ssl"),
+		})
+	default:
+		err = fmt.Errorf("不支持的存储类型: %s", driver)
+	}
+
+	if err != nil {
+		slog.Error("附件存储初始化失败", "driver", driver, "error", err)
+		panic("🔥 无法初始化附件存储！")
+	}
+	slog.Info("附件存储初始化完成", "driver", driver)
+}
+
+// AttachmentMaxSize 单个附件的最大字节数，默认 10MB
+func AttachmentMaxSize() int64 {
+	viper.SetDefault("attachments.max_size", 10<<20)
+	return viper.GetInt64("attachments.max_size")
+}
+
+// AttachmentAllowedTypes 允许上传的 MIME 类型
+func AttachmentAllowedTypes() []string {
+	viper.SetDefault("attachments.allowed_types", []string{
+		"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain",
+	})
+	return viper.GetStringSlice("attachments.allowed_types")
+}
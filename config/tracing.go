@@ -0,0 +1,37 @@
+package config
+
+import (
+	"context"
+	"go-todo/tracing"
+
+	"github.com/spf13/viper"
+)
+
+// tracingShutdown 发送缓冲中的 span，由 InitTracing 设置
+var tracingShutdown = func(context.Context) error { return nil }
+
+// InitTracing 按 tracing.* 配置链路追踪，默认不导出（tracing.exporter=none），
+// 但仍然传递请求头中的 traceparent。version 写入 span 的 service.version
+func InitTracing(version string) error {
+	viper.SetDefault("tracing.exporter", tracing.ExporterNone)
+	viper.SetDefault("tracing.service_name", "go-todo")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:       viper.GetString("tracing.exporter"),
+		Endpoint:       viper.GetString("tracing.endpoint"),
+		Insecure:       viper.GetBool("tracing.insecure"),
+		SampleRatio:    viper.GetFloat64("tracing.sample_ratio"),
+		ServiceName:    viper.GetString("tracing.service_name"),
+		ServiceVersion: version,
+	})
+	if err != nil {
+		return err
+	}
+	tracingShutdown = shutdown
+	return nil
+}
+
+// ShutdownTracing 发送缓冲中的 span，服务退出前调用
+func ShutdownTracing(ctx context.Context) error {
+	return tracingShutdown(ctx)
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// WebhookMaxAttempts 投递失败后的最大尝试次数，默认 8 次
+func WebhookMaxAttempts() int {
+	viper.SetDefault("webhooks.max_attempts", 8)
+	return viper.GetInt("webhooks.max_attempts")
+}
+
+// WebhookTimeout 单次投递的超时时间，默认 10 秒
+func WebhookTimeout() time.Duration {
+	viper.SetDefault("webhooks.timeout", "10s")
+	return viper.GetDuration("webhooks.timeout")
+}
+
+// WebhookPollInterval 投递队列的轮询间隔，默认 1 秒
+func WebhookPollInterval() time.Duration {
+	viper.SetDefault("webhooks.poll_interval", "1s")
+	return viper.GetDuration("webhooks.poll_interval")
+}
+
+// WebhookAllowPrivate 是否允许投递到内网和本机地址，默认不允许，避免被用来访问内部服务
+func WebhookAllowPrivate() bool {
+	return viper.GetBool("webhooks.allow_private")
+}
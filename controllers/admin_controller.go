@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"go-todo/common"
+	"go-todo/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var adminService = service.AdminService{}
+
+// AdminController 管理员的用户管理接口
+type AdminController struct {
+	users *service.UserService
+}
+
+// NewAdminController 创建 AdminController，users 用来解析路径中的用户 ID
+func NewAdminController(users *service.UserService) *AdminController {
+	return &AdminController{users: users}
+}
+
+// SetRoleRequest 修改角色请求
+// @Description 修改用户角色
+type SetRoleRequest struct {
+	// 角色：user 或 admin
+	Role string `json:"role" binding:"required" example:"admin"`
+}
+
+// ListUsers 用户列表
+// @Summary 列出/搜索用户
+// @Description 管理员分页查看用户，可按用户名或显示名称搜索
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param q query string false "搜索关键字"
+// @Param page query int false "页码，默认为 1"
+// @Param pageSize query int false "每页数量，默认为 10，最多 100"
+// @Success 200 {object} map[string]interface{} "用户列表和分页信息"
+// @Failure 403 {object} common.Response "权限不足"
+// @Router /admin/users [get]
+func (h *AdminController) ListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	users, total, err := adminService.ListUsers(c.Query("q"), page, pageSize)
+	if err != nil {
+		common.Error(c, 500, "查询失败")
+		return
+	}
+	common.Success(c, gin.H{
+		"data":     users,
+		"page":     page,
+		"pageSize": pageSize,
+		"total":    total,
+	})
+}
+
+// DisableUser 禁用账号
+// @Summary 禁用账号
+// @Description 禁用后该用户无法登录，已签发的 Token 也立即失效
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "用户 ID"
+// @Success 200 {object} common.Response "操作成功"
+// @Failure 400 {object} common.Response "操作失败"
+// @Failure 404 {object} common.Response "用户不存在"
+// @Router /admin/users/{id}/disable [post]
+func (h *AdminController) DisableUser(c *gin.Context) {
+	h.setUserDisabled(c, true)
+}
+
+// EnableUser 启用账号
+// @Summary 启用账号
+// @Description 重新启用被禁用的账号
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "用户 ID"
+// @Success 200 {object} common.Response "操作成功"
+// @Failure 400 {object} common.Response "操作失败"
+// @Failure 404 {object} common.Response "用户不存在"
+// @Router /admin/users/{id}/enable [post]
+func (h *AdminController) EnableUser(c *gin.Context) {
+	h.setUserDisabled(c, false)
+}
+
+func (h *AdminController) setUserDisabled(c *gin.Context, disabled bool) {
+	adminID, _ := c.Get("userID")
+	id, ok := userIDParam(c, h.users, "id")
+	if !ok {
+		return
+	}
+	if err := adminService.SetDisabled(adminID.(uint), id, disabled); err != nil {
+		adminError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": c.Param("id"), "disabled": disabled})
+}
+
+// SetUserRole 修改用户角色
+// @Summary 修改用户角色
+// @Description 设置用户为普通用户或管理员
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "用户 ID"
+// @Param request body SetRoleRequest true "角色"
+// @Success 200 {object} common.Response "操作成功"
+// @Failure 400 {object} common.Response "操作失败"
+// @Failure 404 {object} common.Response "用户不存在"
+// @Router /admin/users/{id}/role [put]
+func (h *AdminController) SetUserRole(c *gin.Context) {
+	adminID, _ := c.Get("userID")
+	id, ok := userIDParam(c, h.users, "id")
+	if !ok {
+		return
+	}
+	var req SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数验证失败")
+		return
+	}
+	if err := adminService.SetRole(adminID.(uint), id, req.Role); err != nil {
+		adminError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": c.Param("id"), "role": req.Role})
+}
+
+// ForcePasswordReset 强制重置密码
+// @Summary 强制用户重置密码
+// @Description 作废该用户所有 Token，重新登录后必须先修改密码才能访问其他接口
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "用户 ID"
+// @Success 200 {object} common.Response "操作成功"
+// @Failure 404 {object} common.Response "用户不存在"
+// @Router /admin/users/{id}/force-password-reset [post]
+func (h *AdminController) ForcePasswordReset(c *gin.Context) {
+	id, ok := userIDParam(c, h.users, "id")
+	if !ok {
+		return
+	}
+	if err := adminService.ForcePasswordReset(id); err != nil {
+		adminError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": c.Param("id")})
+}
+
+// GetUsageStats 使用统计
+// @Summary 查看使用统计
+// @Description 用户数、任务数、近 7 天新增等统计信息
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {object} service.UsageStats "统计信息"
+// @Failure 403 {object} common.Response "权限不足"
+// @Router /admin/stats [get]
+func (h *AdminController) GetUsageStats(c *gin.Context) {
+	stats, err := adminService.Stats()
+	if err != nil {
+		common.Error(c, 500, "统计失败")
+		return
+	}
+	common.Success(c, stats)
+}
+
+func adminError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrUserNotFound) {
+		common.Error(c, 404, err.Error())
+		return
+	}
+	common.Error(c, 400, err.Error())
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"go-todo/common"
+	"go-todo/config"
+	"go-todo/service"
+	"go-todo/storage"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var attachmentService = service.AttachmentService{}
+
+// multipartOverhead 给 multipart 边界和表单头预留的额外字节
+const multipartOverhead = 1 << 20
+
+// GetAttachments 附件列表
+// @Summary 获取任务的附件
+// @Tags Attachments
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Success 200 {array} models.Attachment "附件列表"
+// @Failure 404 {object} common.Response "任务不存在"
+// @Router /todos/{id}/attachments [get]
+func GetAttachments(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	attachments, err := attachmentService.List(c.Request.Context(), userID.(uint), c.Param("id"))
+	if err != nil {
+		attachmentError(c, err)
+		return
+	}
+	common.Success(c, attachments)
+}
+
+// UploadAttachment 上传附件
+// @Summary 上传附件
+// @Description 需要任务的编辑权限；文件类型按内容识别，大小和类型受 attachments.* 配置限制
+// @Tags Attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Param file formData file true "附件文件"
+// @Success 200 {object} models.Attachment "上传成功"
+// @Failure 400 {object} common.Response "缺少文件"
+// @Failure 403 {object} common.Response "没有编辑权限"
+// @Failure 404 {object} common.Response "任务不存在"
+// @Failure 413 {object} common.Response "文件过大"
+// @Failure 415 {object} common.Response "不支持的文件类型"
+// @Router /todos/{id}/attachments [post]
+func UploadAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.AttachmentMaxSize()+multipartOverhead)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			attachmentError(c, service.ErrFileTooLarge)
+			return
+		}
+		common.Error(c, 400, "请通过 file 字段上传文件")
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		common.Error(c, 400, "读取上传文件失败")
+		return
+	}
+	defer f.Close()
+
+	attachment, err := attachmentService.Upload(c.Request.Context(), userID.(uint), c.Param("id"), fh.Filename, f, fh.Size)
+	if err != nil {
+		attachmentError(c, err)
+		return
+	}
+	common.Success(c, attachment)
+}
+
+// DownloadAttachment 下载附件
+// @Summary 下载附件
+// @Tags Attachments
+// @Produce octet-stream
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Param attachmentID path int true "附件 ID"
+// @Success 200 {file} file "附件内容"
+// @Failure 404 {object} common.Response "附件不存在"
+// @Router /todos/{id}/attachments/{attachmentID} [get]
+func DownloadAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	attachmentID, ok := idParam(c, "attachmentID")
+	if !ok {
+		return
+	}
+	attachment, rc, err := attachmentService.Open(c.Request.Context(), userID.(uint), c.Param("id"), attachmentID)
+	if err != nil {
+		attachmentError(c, err)
+		return
+	}
+	defer rc.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, rc, map[string]string{
+		"Content-Disposition":    disposition,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment 删除附件
+// @Summary 删除附件
+// @Description 需要任务的编辑权限，存储中的文件会一起删除
+// @Tags Attachments
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Param attachmentID path int true "附件 ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 403 {object} common.Response "没有编辑权限"
+// @Failure 404 {object} common.Response "附件不存在"
+// @Router /todos/{id}/attachments/{attachmentID} [delete]
+func DeleteAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	attachmentID, ok := idParam(c, "attachmentID")
+	if !ok {
+		return
+	}
+	if err := attachmentService.Delete(c.Request.Context(), userID.(uint), c.Param("id"), attachmentID); err != nil {
+		attachmentError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": attachmentID})
+}
+
+func attachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge):
+		common.Error(c, 413, fmt.Sprintf("文件过大，最大 %d 字节", config.AttachmentMaxSize()))
+	case errors.Is(err, service.ErrFileType):
+		common.Error(c, 415, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		common.Error(c, 403, "没有权限操作该任务的附件")
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, storage.ErrNotFound):
+		common.Error(c, 404, "任务或附件不存在")
+	default:
+		common.Error(c, 500, "附件操作失败")
+	}
+}
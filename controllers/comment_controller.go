@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"errors"
+	"go-todo/common"
+	"go-todo/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var commentService = service.CommentService{}
+
+// CommentRequest 发表/修改评论请求
+// @Description 评论正文，支持 Markdown 和 @username 提及
+type CommentRequest struct {
+	// 评论正文（Markdown）
+	Body string `json:"body" binding:"required" example:"已经提交了，@jane_doe 帮忙看一下"`
+}
+
+// SharedCommentRequest 访客评论请求
+// @Description 通过可评论的分享链接发表评论
+type SharedCommentRequest struct {
+	// 访客名称，不填显示为"访客"
+	Name string `json:"name" example:"客户张三"`
+	// 评论正文（Markdown）
+	Body string `json:"body" binding:"required" example:"这里的文案需要再改一下"`
+}
+
+// GetComments 评论列表
+// @Summary 获取任务的评论
+// @Tags Comments
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Success 200 {array} models.Comment "评论列表"
+// @Failure 404 {object} common.Response "任务不存在"
+// @Router /todos/{id}/comments [get]
+func GetComments(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	comments, err := commentService.List(c.Request.Context(), userID.(uint), c.Param("id"))
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+	common.Success(c, comments)
+}
+
+// CreateComment 发表评论
+// @Summary 发表评论
+// @Description 能查看任务的用户都可以评论，正文中的 @username 会被解析为对应用户
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Param request body CommentRequest true "评论内容"
+// @Success 200 {object} models.Comment "发表成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "任务不存在"
+// @Router /todos/{id}/comments [post]
+func CreateComment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "评论内容不能为空")
+		return
+	}
+	comment, err := commentService.Create(c.Request.Context(), userID.(uint), c.Param("id"), req.Body)
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+	common.Success(c, comment)
+}
+
+// UpdateComment 修改评论
+// @Summary 修改评论
+// @Description 只有作者本人可以修改
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Param commentID path int true "评论 ID"
+// @Param request body CommentRequest true "评论内容"
+// @Success 200 {object} models.Comment "修改成功"
+// @Failure 403 {object} common.Response "不是作者"
+// @Failure 404 {object} common.Response "评论不存在"
+// @Router /todos/{id}/comments/{commentID} [put]
+func UpdateComment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	commentID, ok := idParam(c, "commentID")
+	if !ok {
+		return
+	}
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "评论内容不能为空")
+		return
+	}
+	comment, err := commentService.Update(c.Request.Context(), userID.(uint), c.Param("id"), commentID, req.Body)
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+	common.Success(c, comment)
+}
+
+// DeleteComment 删除评论
+// @Summary 删除评论
+// @Description 作者本人、个人任务的所有者或工作区 owner 可以删除
+// @Tags Comments
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Param commentID path int true "评论 ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 403 {object} common.Response "没有权限"
+// @Failure 404 {object} common.Response "评论不存在"
+// @Router /todos/{id}/comments/{commentID} [delete]
+func DeleteComment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	commentID, ok := idParam(c, "commentID")
+	if !ok {
+		return
+	}
+	if err := commentService.Delete(c.Request.Context(), userID.(uint), c.Param("id"), commentID); err != nil {
+		commentError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": commentID})
+}
+
+// GetSharedComments 分享中的评论
+// @Summary 查看分享任务的评论（无需登录）
+// @Description 只返回作者名称，不返回作者的用户 ID
+// @Tags Public
+// @Produce json
+// @Param token path string true "分享 token"
+// @Param todoID path string true "任务 ID"
+// @Param X-Share-Password header string false "访问密码"
+// @Success 200 {array} models.Comment "评论列表"
+// @Failure 401 {object} common.Response "需要访问密码"
+// @Failure 404 {object} common.Response "分享或任务不存在"
+// @Router /public/shares/{token}/todos/{todoID}/comments [get]
+func GetSharedComments(c *gin.Context) {
+	link, ok := resolveShare(c)
+	if !ok {
+		return
+	}
+	comments, err := commentService.ListShared(c.Request.Context(), link, c.Param("todoID"))
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+	common.Success(c, comments)
+}
+
+// CreateSharedComment 访客评论
+// @Summary 通过分享链接发表评论（无需登录）
+// @Description 只有 comment 权限的分享链接可以评论
+// @Tags Public
+// @Accept json
+// @Produce json
+// @Param token path string true "分享 token"
+// @Param todoID path string true "任务 ID"
+// @Param X-Share-Password header string false "访问密码"
+// @Param request body SharedCommentRequest true "评论内容"
+// @Success 200 {object} models.Comment "发表成功"
+// @Failure 401 {object} common.Response "需要访问密码"
+// @Failure 403 {object} common.Response "分享链接不允许评论"
+// @Failure 404 {object} common.Response "分享或任务不存在"
+// @Router /public/shares/{token}/todos/{todoID}/comments [post]
+func CreateSharedComment(c *gin.Context) {
+	link, ok := resolveShare(c)
+	if !ok {
+		return
+	}
+	var req SharedCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "评论内容不能为空")
+		return
+	}
+	comment, err := commentService.CreateShared(c.Request.Context(), link, c.Param("todoID"), req.Name, req.Body)
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+	common.Success(c, comment)
+}
+
+func commentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		common.Error(c, 403, "没有权限操作该评论")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		common.Error(c, 404, "任务或评论不存在")
+	default:
+		common.Error(c, 400, err.Error())
+	}
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-todo/common"
+	"go-todo/config"
+	"go-todo/events"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// upgrader WebSocket 升级器；来源在 StreamEventsWS 中按认证方式检查（见 websocketOriginAllowed）
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// websocketOriginAllowed 浏览器建立 WebSocket 连接时会自动携带 Cookie，并且不受 CORS 限制。
+// Cookie 登录的连接只接受同源或 cors.allowed_origins 中明确列出的来源（"*" 不算），
+// 防止其他网站借用户的会话读取事件（跨站 WebSocket 劫持）；用 Token 认证的连接不限制来源
+func websocketOriginAllowed(c *gin.Context) bool {
+	origin := c.GetHeader("Origin")
+	if origin == "" || !c.GetBool("cookieAuth") {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, c.Request.Host) {
+		return true
+	}
+	return config.CORS("events").MatchOrigin(origin)
+}
+
+// lastEventID 读取断线重连时客户端最后收到的事件 ID
+func lastEventID(c *gin.Context) uint64 {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	id, _ := strconv.ParseUint(raw, 10, 64)
+	return id
+}
+
+// StreamEvents 事件流（SSE）
+// @Summary 订阅任务变更事件（Server-Sent Events）
+// @Description 推送 todo.created / todo.updated / todo.deleted 事件，定期发送心跳注释。
+// @Description 重连时通过 Last-Event-ID 请求头（或 last_event_id 参数）补发错过的事件；
+// @Description 错过的事件已不在缓冲区时推送 reset 事件，客户端需重新拉取任务列表。
+// @Description EventSource 不能设置请求头，可以用 access_token 参数传 Token
+// @Tags Events
+// @Produce text/event-stream
+// @Param Authorization header string false "Bearer Token"
+// @Param access_token query string false "Token（用于浏览器 EventSource）"
+// @Param Last-Event-ID header string false "最后收到的事件 ID"
+// @Success 200 {object} events.Event "事件流"
+// @Failure 401 {object} common.Response "未登录"
+// @Router /events [get]
+func StreamEvents(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	sub, missed := config.Events.Subscribe(userID.(uint), lastEventID(c))
+	defer sub.Close()
+
+	// 事件流是长连接，不受 server.write_timeout 限制
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 Nginx 的响应缓冲
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	for _, e := range missed {
+		writeSSE(c, e)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(config.EventsHeartbeat())
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				return // 消费太慢被断开或服务正在退出，客户端会自动重连
+			}
+			writeSSE(c, e)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeSSE(c *gin.Context, e events.Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
+
+// StreamEventsWS 事件流（WebSocket）
+// @Summary 订阅任务变更事件（WebSocket）
+// @Description 与 SSE 相同的事件，每条消息是一个 JSON 事件；服务端定期发送 ping 帧。
+// @Description 重连时用 last_event_id 参数补发错过的事件，浏览器可以用 access_token 参数传 Token
+// @Tags Events
+// @Param Authorization header string false "Bearer Token"
+// @Param access_token query string false "Token（用于浏览器 WebSocket）"
+// @Param last_event_id query int false "最后收到的事件 ID"
+// @Success 101 {object} events.Event "切换到 WebSocket 协议"
+// @Failure 401 {object} common.Response "未登录"
+// @Failure 403 {object} common.Response "Cookie 登录时来源不在 cors.allowed_origins 中"
+// @Router /events/ws [get]
+func StreamEventsWS(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	if !websocketOriginAllowed(c) {
+		common.Error(c, 403, "不允许从该来源建立连接")
+		return
+	}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // Upgrade 已经写回了错误响应
+	}
+	defer conn.Close()
+	// 接管后的连接可能还带着 http.Server 设置的读写时限，下面按心跳重新设置
+	conn.NetConn().SetDeadline(time.Time{})
+
+	sub, missed := config.Events.Subscribe(userID.(uint), lastEventID(c))
+	defer sub.Close()
+
+	heartbeat := config.EventsHeartbeat()
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// 读循环只用来处理 pong 和关闭帧，超过两个心跳周期没有响应就断开
+	conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, e := range missed {
+		if err := conn.WriteJSON(e); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				code, reason := websocket.CloseTryAgainLater, "too slow"
+				if config.Events.Closed() {
+					code, reason = websocket.CloseServiceRestart, "server shutting down"
+				}
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(heartbeat))
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeat)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestWebsocketOriginAllowed Cookie 登录的 WebSocket 连接只接受同源或允许列表中的来源
+func TestWebsocketOriginAllowed(t *testing.T) {
+	tests := []struct {
+		name       string
+		origin     string
+		cookieAuth bool
+		want       bool
+	}{
+		{"非浏览器客户端", "", true, true},
+		{"Token 认证", "https://evil.com", false, true},
+		{"Cookie 登录同源", "http://api.example.com", true, true},
+		{"Cookie 登录其他来源", "https://evil.com", true, false},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "http://api.example.com/api/v1/events/ws", nil)
+		if tt.origin != "" {
+			c.Request.Header.Set("Origin", tt.origin)
+		}
+		if tt.cookieAuth {
+			c.Set("cookieAuth", true)
+		}
+		if got := websocketOriginAllowed(c); got != tt.want {
+			t.Errorf("%s: 期望 %v，但得到了 %v", tt.name, tt.want, got)
+		}
+	}
+}
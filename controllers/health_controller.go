@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"go-todo/common"
+	"go-todo/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthController 健康检查和运行状态接口。
+// 这些接口不在 /api/v1 下，也不出现在 Swagger 文档中
+type HealthController struct {
+	health *service.HealthService
+}
+
+// NewHealthController 创建 HealthController
+func NewHealthController(health *service.HealthService) *HealthController {
+	return &HealthController{health: health}
+}
+
+// Healthz 存活检查：进程能处理 HTTP 请求就返回 200，不检查依赖，
+// 避免数据库故障时编排系统反复重启所有实例
+func (h *HealthController) Healthz(c *gin.Context) {
+	common.Success(c, gin.H{"status": "ok"})
+}
+
+// Readyz 就绪检查：数据库可以连接、迁移都已执行、后台任务都在运行时返回 200，
+// 否则返回 HTTP 503（探针只看状态码），响应中带上每一项检查的结果
+func (h *HealthController) Readyz(c *gin.Context) {
+	readiness := h.health.Ready(c.Request.Context())
+	if !readiness.Ready {
+		c.JSON(http.StatusServiceUnavailable, common.Response{Code: 503, Msg: "服务未就绪", Data: readiness})
+		return
+	}
+	common.Success(c, readiness)
+}
+
+// GetStatus 管理员查看的运行状态：构建版本、Git 提交、运行时长、连接池统计、后台任务和就绪检查
+func (h *HealthController) GetStatus(c *gin.Context) {
+	common.Success(c, h.health.Status(c.Request.Context()))
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"go-todo/common"
+	"go-todo/models"
+	"go-todo/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var accountService = service.AccountService{}
+
+// UserInfo 当前登录用户信息
+// @Description 当前登录用户的基本信息与个人资料
+type UserInfo struct {
+	// 用户 ID
+	ID string `json:"id" example:"01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"`
+	// 用户名
+	Username string `json:"username" example:"john_doe"`
+	// 角色：user 或 admin
+	Role string `json:"role" example:"user"`
+	// 是否需要先修改密码
+	MustResetPassword bool `json:"must_reset_password" example:"false"`
+	// 注册时间
+	CreatedAt time.Time `json:"created_at"`
+	// 个人资料与偏好
+	Profile models.Profile `json:"profile"`
+}
+
+// GetMe 获取当前用户
+// @Summary 获取当前登录用户
+// @Description 返回当前 Token 对应的用户信息及个人偏好
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {object} UserInfo "获取成功"
+// @Failure 404 {object} common.Response "用户不存在"
+// @Router /me [get]
+func (h *UserController) GetMe(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	user, err := h.users.GetByID(c.Request.Context(), userID.(uint))
+	if err != nil {
+		common.Error(c, 404, "用户不存在")
+		return
+	}
+	common.Success(c, UserInfo{
+		ID:                user.PublicID,
+		Username:          user.Username,
+		Role:              user.Role,
+		MustResetPassword: user.MustResetPassword,
+		CreatedAt:         user.CreatedAt,
+		Profile:           user.Profile,
+	})
+}
+
+// UpdateProfile 更新个人资料
+// @Summary 更新个人资料与偏好
+// @Description 整体更新显示名称、时区、语言、默认项目、每周起始日和默认排序
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param profile body models.Profile true "个人资料"
+// @Success 200 {object} models.Profile "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /me/profile [put]
+func (h *UserController) UpdateProfile(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var profile models.Profile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		common.Error(c, 400, "参数格式错误")
+		return
+	}
+
+	profile, err := h.users.UpdateProfile(c.Request.Context(), userID.(uint), profile)
+	if err != nil {
+		common.Error(c, 400, err.Error())
+		return
+	}
+	common.Success(c, profile)
+}
+
+// ChangePasswordRequest 修改密码请求
+// @Description 修改密码需要提供旧密码
+type ChangePasswordRequest struct {
+	// 旧密码
+	OldPassword string `json:"old_password" binding:"required" example:"password123"`
+	// 新密码
+	NewPassword string `json:"new_password" binding:"required,min=6" example:"newpassword456"`
+}
+
+// DeleteAccountRequest 注销账号请求
+// @Description 注销账号前需要再次输入密码确认
+type DeleteAccountRequest struct {
+	// 当前密码
+	Password string `json:"password" binding:"required" example:"password123"`
+}
+
+// ChangePassword 修改密码
+// @Summary 修改密码
+// @Description 校验旧密码后设置新密码，其他设备上的 Token 全部失效，返回新的 Token；被管理员强制重置密码的用户只能访问此接口。
+// @Description Cookie 登录时新 Token 写入会话 Cookie，响应返回新的 CSRF Token
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param request body ChangePasswordRequest true "旧密码和新密码"
+// @Success 200 {object} map[string]string "修改成功，返回新 Token 或新的 CSRF Token"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 403 {object} common.Response "旧密码错误"
+// @Router /me/password [put]
+func (h *UserController) ChangePassword(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数验证失败: "+err.Error())
+		return
+	}
+
+	token, err := h.users.ChangePassword(c.Request.Context(), userID.(uint), req.OldPassword, req.NewPassword)
+	if errors.Is(err, service.ErrWrongPassword) {
+		common.Error(c, 403, "旧密码错误")
+		return
+	}
+	if err != nil {
+		common.Error(c, 500, "修改密码失败")
+		return
+	}
+	// Cookie 登录的会话 Token 已经失效，换成新的会话 Cookie，否则浏览器会被登出
+	if c.GetBool("cookieAuth") {
+		csrfToken := common.CSRFToken(token)
+		h.setSessionCookies(c, token, csrfToken, int(common.TokenTTL.Seconds()))
+		common.Success(c, gin.H{"csrf_token": csrfToken})
+		return
+	}
+	common.Success(c, gin.H{"token": token})
+}
+
+// ExportMe 导出个人数据
+// @Summary 导出个人数据
+// @Description 以 ZIP 格式导出当前用户的个人资料、任务及所有相关记录（JSON 格式）
+// @Tags Me
+// @Produce application/zip
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {file} file "ZIP 压缩包"
+// @Failure 500 {object} common.Response "服务器错误"
+// @Router /me/export [get]
+func ExportMe(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	data, err := accountService.Export(c.Request.Context(), userID.(uint))
+	if err != nil {
+		common.Error(c, 500, "导出失败")
+		return
+	}
+	filename := fmt.Sprintf("go-todo-export-%s.zip", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(200, "application/zip", data)
+}
+
+// DeleteMe 注销账号
+// @Summary 注销账号
+// @Description 校验密码后永久删除当前用户及其全部数据，所有已签发的 Token 立即失效
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param request body DeleteAccountRequest true "密码确认"
+// @Success 200 {object} common.Response "注销成功"
+// @Failure 400 {object} common.Response "参数错误或仍拥有共享工作区"
+// @Failure 403 {object} common.Response "密码错误"
+// @Failure 500 {object} common.Response "服务器错误"
+// @Router /me [delete]
+func DeleteMe(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "请输入密码确认注销")
+		return
+	}
+
+	err := accountService.Delete(c.Request.Context(), userID.(uint), req.Password)
+	if errors.Is(err, service.ErrWrongPassword) {
+		common.Error(c, 403, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrOwnsSharedWorkspace) {
+		common.Error(c, 400, err.Error())
+		return
+	}
+	if err != nil {
+		common.Error(c, 500, "注销失败")
+		return
+	}
+	common.Success(c, "账号已注销")
+}
+
+// currentProfile 当前用户的偏好，由 AuthMiddleware 随用户一起读取，没有时使用默认值
+func currentProfile(c *gin.Context) models.Profile {
+	if profile, ok := c.Get("profile"); ok {
+		return profile.(models.Profile)
+	}
+	return models.Profile{TimeZone: "UTC", WeekStart: 1}
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"errors"
+	"go-todo/common"
+	"go-todo/models"
+	"go-todo/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var shareService = service.ShareService{}
+
+// SharePasswordHeader 访问带密码的分享时，通过该请求头传递密码
+const SharePasswordHeader = "X-Share-Password"
+
+// CreateShareRequest 创建分享链接请求
+// @Description 指定 todo_id 分享单个任务，或指定 project（可选 workspace_id）分享一个项目
+type CreateShareRequest struct {
+	// 分享的任务 ID
+	TodoID *string `json:"todo_id" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	// 分享的项目名称
+	Project string `json:"project" example:"工作"`
+	// 项目所在工作区 ID，不填表示个人任务中的项目
+	WorkspaceID *uint `json:"workspace_id" example:"1"`
+	// 权限：read（默认）或 comment
+	Permission string `json:"permission" example:"read"`
+	// 访问密码，可选
+	Password string `json:"password" example:"s3cret"`
+	// 有效期（小时），0 表示不过期
+	ExpiresInHours int `json:"expires_in_hours" example:"168"`
+}
+
+// CreateShare 创建分享链接
+// @Summary 创建分享链接
+// @Description 为任务或项目创建只读/可评论的公开链接，可设置过期时间和访问密码；需要编辑权限
+// @Tags Shares
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param request body CreateShareRequest true "分享信息"
+// @Success 200 {object} models.ShareLink "分享链接，token 用于公开访问"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 403 {object} common.Response "权限不足"
+// @Failure 404 {object} common.Response "任务不存在"
+// @Router /shares [post]
+func CreateShare(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req CreateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数格式错误")
+		return
+	}
+
+	link, err := shareService.Create(c.Request.Context(), userID.(uint), service.ShareRequest{
+		TodoID:      req.TodoID,
+		WorkspaceID: req.WorkspaceID,
+		Project:     req.Project,
+		Permission:  req.Permission,
+		Password:    req.Password,
+		TTL:         time.Duration(req.ExpiresInHours) * time.Hour,
+	})
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		common.Error(c, 403, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		common.Error(c, 404, "找不到该任务")
+	case err != nil:
+		common.Error(c, 400, err.Error())
+	default:
+		common.Success(c, link)
+	}
+}
+
+// GetShares 我的分享链接
+// @Summary 列出我创建的分享链接
+// @Tags Shares
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.ShareLink "分享链接列表"
+// @Router /shares [get]
+func GetShares(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	links, err := shareService.List(c.Request.Context(), userID.(uint))
+	if err != nil {
+		common.Error(c, 500, "查询失败")
+		return
+	}
+	common.Success(c, links)
+}
+
+// RevokeShare 撤销分享链接
+// @Summary 撤销分享链接
+// @Tags Shares
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "分享 ID"
+// @Success 200 {object} common.Response "撤销成功"
+// @Failure 404 {object} common.Response "分享不存在"
+// @Router /shares/{id} [delete]
+func RevokeShare(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := shareService.Revoke(c.Request.Context(), userID.(uint), id); err != nil {
+		common.Error(c, 404, "分享不存在")
+		return
+	}
+	common.Success(c, gin.H{"id": id})
+}
+
+// GetSharedContent 访问分享
+// @Summary 访问分享内容（无需登录）
+// @Description 通过分享 token 查看分享的任务或项目；设置了密码时需要在 X-Share-Password 请求头中提供
+// @Tags Public
+// @Produce json
+// @Param token path string true "分享 token"
+// @Param X-Share-Password header string false "访问密码"
+// @Success 200 {object} service.SharedContent "分享内容"
+// @Failure 401 {object} common.Response "需要访问密码"
+// @Failure 404 {object} common.Response "分享不存在或已失效"
+// @Router /public/shares/{token} [get]
+func GetSharedContent(c *gin.Context) {
+	link, ok := resolveShare(c)
+	if !ok {
+		return
+	}
+	content, err := shareService.Content(c.Request.Context(), link)
+	if err != nil {
+		common.Error(c, 404, service.ErrShareInvalid.Error())
+		return
+	}
+	common.Success(c, content)
+}
+
+// resolveShare 校验分享 token 和密码，失败时直接返回错误响应
+func resolveShare(c *gin.Context) (link models.ShareLink, ok bool) {
+	link, err := shareService.Resolve(c.Request.Context(), c.Param("token"), c.GetHeader(SharePasswordHeader))
+	if errors.Is(err, service.ErrSharePassword) {
+		common.Error(c, 401, err.Error())
+		return link, false
+	}
+	if err != nil {
+		common.Error(c, 404, err.Error())
+		return link, false
+	}
+	return link, true
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"errors"
+	"go-todo/common"
+	"go-todo/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var syncService = service.SyncService{}
+
+// SyncRequest 提交离线修改请求
+// @Description 先按顺序应用 changes，再返回 sync_token 之后的所有变更（包括本次提交产生的）
+type SyncRequest struct {
+	// 上次同步得到的 token，为空表示首次同步
+	SyncToken string `json:"sync_token" example:"djE6MTI"`
+	// 冲突策略：lww（默认，最后写入获胜）或 field（字段级合并）
+	Conflict string `json:"conflict" example:"lww"`
+	// 客户端变更
+	Changes []service.SyncChange `json:"changes"`
+}
+
+// GetSync 拉取变更
+// @Summary 增量同步：拉取变更
+// @Description 返回 sync_token 之后新建/修改的任务和已删除任务的墓碑；不传 token 时返回全部任务的快照。
+// @Description has_more 为 true 时应立即用新的 token 继续拉取
+// @Tags Sync
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param sync_token query string false "上次同步得到的 token"
+// @Success 200 {object} service.SyncPage "变更"
+// @Failure 400 {object} common.Response "token 无效，需要全量同步"
+// @Router /sync [get]
+func GetSync(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	page, err := syncService.Pull(c.Request.Context(), userID.(uint), c.Query("sync_token"))
+	if err != nil {
+		syncError(c, err)
+		return
+	}
+	respondSync(c, page)
+}
+
+// PostSync 提交离线修改
+// @Summary 增量同步：提交离线修改并拉取变更
+// @Description 新建任务使用客户端生成的 client_id；每条变更单独返回 applied / merged / conflict / rejected 结果
+// @Tags Sync
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param request body SyncRequest true "离线修改"
+// @Success 200 {object} service.SyncPage "处理结果和变更"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /sync [post]
+func PostSync(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数格式错误")
+		return
+	}
+	page, err := syncService.Sync(c.Request.Context(), userID.(uint), req.SyncToken, req.Changes, req.Conflict)
+	if err != nil {
+		syncError(c, err)
+		return
+	}
+	respondSync(c, page)
+}
+
+// respondSync 把任务时间转换到用户时区后返回
+func respondSync(c *gin.Context, page service.SyncPage) {
+	loc := currentProfile(c).Location()
+	localizeTodos(page.Todos, loc)
+	for _, r := range page.Results {
+		if r.Todo != nil {
+			localizeTodo(r.Todo, loc)
+		}
+	}
+	common.Success(c, page)
+}
+
+func syncError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSyncToken), errors.Is(err, service.ErrSyncInvalid):
+		common.Error(c, 400, err.Error())
+	default:
+		common.Error(c, 500, "同步失败")
+	}
+}
@@ -1,33 +1,101 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"go-todo/common" // 导入你定义的通用响应包
 	"go-todo/models"
 	"go-todo/service"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// 实例化 service
-var todoService = service.TodoService{}
+// TodoController 任务相关的接口
+type TodoController struct {
+	todos *service.TodoService
+	users *service.UserService
+}
+
+// NewTodoController 创建 TodoController
+func NewTodoController(todos *service.TodoService, users *service.UserService) *TodoController {
+	return &TodoController{todos: todos, users: users}
+}
 
 // GetTodos 获取所有任务（支持分页）
 // @Summary 获取所有任务
-// @Description 获取当前用户的所有任务，支持分页查询
+// @Description 获取当前用户的所有任务，支持分页、项目过滤和排序；未指定排序时使用用户偏好
 // @Tags Todos
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer Token"
 // @Param page query int false "页码，默认为 1"
-// @Param pageSize query int false "每页数量，默认为 10"
+// @Param pageSize query int false "每页数量，默认为 10，最多 100"
+// @Param project query string false "按项目过滤"
+// @Param sort query string false "排序方式：created_asc, created_desc, due_asc, due_desc, title_asc"
+// @Param due query string false "按截止时间过滤（按用户时区计算）：today, week, overdue"
+// @Param workspace_id query int false "工作区 ID，不传则返回个人任务"
+// @Param assignee query string false "按负责人过滤：me 或用户 ID"
+// @Param unassigned query bool false "为 true 时只返回没有负责人的任务"
 // @Success 200 {object} map[string]interface{} "返回任务列表和分页信息"
 // @Failure 400 {object} common.Response "请求参数错误"
 // @Failure 500 {object} common.Response "服务器错误"
 // @Router /todos [get]
-func GetTodos(c *gin.Context) {
+func (h *TodoController) GetTodos(c *gin.Context) {
 	userID, _ := c.Get("userID")
-	
+	q, profile, ok := parseTodoQuery(c)
+	if !ok {
+		return
+	}
+
+	// assignee=me 或 assignee=<用户 ID>
+	if a := c.Query("assignee"); a != "" {
+		assigneeID := userID.(uint)
+		if a != "me" {
+			user, err := h.users.GetByPublicID(c.Request.Context(), a)
+			if err != nil {
+				common.Error(c, 400, "assignee 用户不存在")
+				return
+			}
+			assigneeID = user.ID
+		}
+		q.AssigneeID = &assigneeID
+	}
+	q.Unassigned = c.Query("unassigned") == "true"
+
+	// 调用 service 获取分页数据
+	todos, total, err := h.todos.List(c.Request.Context(), userID.(uint), q)
+	respondTodoPage(c, q, profile, todos, total, err)
+}
+
+// GetMyAssigned 我负责的任务
+// @Summary 获取分配给我的任务
+// @Description 跨个人任务和所有已加入的工作区（项目），返回分配给当前用户的任务，支持与任务列表相同的分页、过滤和排序参数
+// @Tags Todos
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param page query int false "页码，默认为 1"
+// @Param pageSize query int false "每页数量，默认为 10，最多 100"
+// @Param project query string false "按项目过滤"
+// @Param sort query string false "排序方式：created_asc, created_desc, due_asc, due_desc, title_asc"
+// @Param due query string false "按截止时间过滤（按用户时区计算）：today, week, overdue"
+// @Success 200 {object} map[string]interface{} "返回任务列表和分页信息"
+// @Failure 400 {object} common.Response "请求参数错误"
+// @Router /me/assigned [get]
+func (h *TodoController) GetMyAssigned(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	q, profile, ok := parseTodoQuery(c)
+	if !ok {
+		return
+	}
+	todos, total, err := h.todos.ListAssigned(c.Request.Context(), userID.(uint), q)
+	respondTodoPage(c, q, profile, todos, total, err)
+}
+
+// parseTodoQuery 解析任务列表的通用查询参数，失败时直接返回错误响应
+func parseTodoQuery(c *gin.Context) (service.TodoQuery, models.Profile, bool) {
 	// 从查询参数获取分页信息
 	page := 1
 	pageSize := 10
@@ -35,36 +103,75 @@ func GetTodos(c *gin.Context) {
 	if p := c.Query("page"); p != "" {
 		if _, err := fmt.Sscanf(p, "%d", &page); err != nil {
 			common.Error(c, 400, "page 参数格式错误")
-			return
+			return service.TodoQuery{}, models.Profile{}, false
 		}
 	}
 	
 	if ps := c.Query("pageSize"); ps != "" {
 		if _, err := fmt.Sscanf(ps, "%d", &pageSize); err != nil {
 			common.Error(c, 400, "pageSize 参数格式错误")
-			return
+			return service.TodoQuery{}, models.Profile{}, false
 		}
 	}
 	
-	// 调用 service 获取分页数据
-	todos, total, err := todoService.GetAll(userID.(uint), page, pageSize)
+	var workspaceID *uint
+	if ws := c.Query("workspace_id"); ws != "" {
+		id, err := strconv.ParseUint(ws, 10, 64)
+		if err != nil {
+			common.Error(c, 400, "workspace_id 参数格式错误")
+			return service.TodoQuery{}, models.Profile{}, false
+		}
+		wsID := uint(id)
+		workspaceID = &wsID
+	}
+
+	profile := currentProfile(c)
+	sort := c.DefaultQuery("sort", profile.DefaultSort)
+	if _, ok := service.TodoSorts[sort]; !ok {
+		common.Error(c, 400, "sort 参数不支持")
+		return service.TodoQuery{}, profile, false
+	}
+
+	return service.TodoQuery{
+		Page:        page,
+		PageSize:    pageSize,
+		Project:     c.Query("project"),
+		Sort:        sort,
+		Due:         c.Query("due"),
+		WorkspaceID: workspaceID,
+		Location:    profile.Location(),
+		WeekStart:   profile.WeekStart,
+	}, profile, true
+}
+
+// respondTodoPage 返回分页后的任务列表
+func respondTodoPage(c *gin.Context, q service.TodoQuery, profile models.Profile, todos []models.Todo, total int64, err error) {
+	if errors.Is(err, service.ErrInvalidDueFilter) {
+		common.Error(c, 400, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrForbidden) {
+		common.Error(c, 403, "不是该工作区的成员")
+		return
+	}
 	if err != nil {
 		common.Error(c, 500, "查询失败")
 		return
 	}
+	localizeTodos(todos, profile.Location())
 	
 	// 返回分页数据
 	common.Success(c, gin.H{
 		"data":      todos,
-		"page":      page,
-		"pageSize":  pageSize,
+		"page":      q.Page,
+		"pageSize":  q.PageSize,
 		"total":     total,
 	})
 }
 
 // CreateTask 创建任务
 // @Summary 创建一个新任务
-// @Description 创建一个新的任务，需要传递 title 字段，user_id 会自动从 Token 获取
+// @Description 创建一个新的任务，需要传递 title 字段，user_id 会自动从 Token 获取；未指定 project 时使用用户的默认项目；指定 workspace_id 时需要该工作区的 editor 以上角色
 // @Tags Todos
 // @Accept json
 // @Produce json
@@ -72,9 +179,10 @@ func GetTodos(c *gin.Context) {
 // @Param todo body models.Todo true "任务信息"
 // @Success 201 {object} models.Todo "创建成功"
 // @Failure 400 {object} common.Response "请求参数错误"
+// @Failure 403 {object} common.Response "没有该工作区的编辑权限"
 // @Failure 500 {object} common.Response "服务器错误"
 // @Router /todos [post]
-func CreateTask(c *gin.Context) {
+func (h *TodoController) CreateTask(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	var todo models.Todo
 	if err := c.ShouldBindJSON(&todo); err != nil {
@@ -82,10 +190,16 @@ func CreateTask(c *gin.Context) {
 		return
 	}
 
-	if err := todoService.Create(userID.(uint), &todo); err != nil {
-		common.Error(c, 500, "创建失败")
+	profile := currentProfile(c)
+	if todo.Project == "" {
+		todo.Project = profile.DefaultProject
+	}
+
+	if err := h.todos.Create(c.Request.Context(), userID.(uint), &todo); err != nil {
+		todoError(c, err, "创建失败")
 		return
 	}
+	localizeTodo(&todo, profile.Location())
 	common.Success(c, todo)
 }
 
@@ -101,20 +215,21 @@ func CreateTask(c *gin.Context) {
 // @Failure 404 {object} common.Response "任务不存在"
 // @Failure 500 {object} common.Response "服务器错误"
 // @Router /todos/{id} [get]
-func GetTodo(c *gin.Context) {
+func (h *TodoController) GetTodo(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	id := c.Param("id")
-	todo, err := todoService.GetByID(userID.(uint), id)
+	todo, err := h.todos.GetByID(c.Request.Context(), userID.(uint), id)
 	if err != nil {
 		common.Error(c, 404, "任务没找到")
 		return
 	}
+	localizeTodo(&todo, currentProfile(c).Location())
 	common.Success(c, todo)
 }
 
 // UpdateTodo 更新任务
 // @Summary 更新任务
-// @Description 更新指定 ID 的任务信息
+// @Description 更新指定 ID 的任务信息（不能修改创建者、所属工作区和创建时间）
 // @Tags Todos
 // @Accept json
 // @Produce json
@@ -123,14 +238,15 @@ func GetTodo(c *gin.Context) {
 // @Param todo body models.Todo true "更新的任务信息"
 // @Success 200 {object} models.Todo "更新成功"
 // @Failure 400 {object} common.Response "请求参数错误"
+// @Failure 403 {object} common.Response "没有编辑权限"
 // @Failure 404 {object} common.Response "任务不存在"
 // @Failure 500 {object} common.Response "服务器错误"
 // @Router /todos/{id} [put]
-func UpdateTodo(c *gin.Context) {
+func (h *TodoController) UpdateTodo(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	id := c.Param("id")
 	// 1. 先查是否存在
-	todo, err := todoService.GetByID(userID.(uint), id)
+	todo, err := h.todos.GetByID(c.Request.Context(), userID.(uint), id)
 	if err != nil {
 		common.Error(c, 404, "找不到该任务")
 		return
@@ -143,10 +259,11 @@ func UpdateTodo(c *gin.Context) {
 	}
 
 	// 3. 调用 Service 更新
-	if err := todoService.Update(userID.(uint), &todo); err != nil {
-		common.Error(c, 500, "更新失败")
+	if err := h.todos.Update(c.Request.Context(), userID.(uint), &todo); err != nil {
+		todoError(c, err, "更新失败")
 		return
 	}
+	localizeTodo(&todo, currentProfile(c).Location())
 	common.Success(c, todo)
 }
 
@@ -159,15 +276,125 @@ func UpdateTodo(c *gin.Context) {
 // @Param Authorization header string true "Bearer Token"
 // @Param id path string true "任务 ID"
 // @Success 200 {object} map[string]string "删除成功"
+// @Failure 403 {object} common.Response "没有删除权限"
 // @Failure 500 {object} common.Response "服务器错误"
 // @Router /todos/{id} [delete]
-func DeleteTodo(c *gin.Context) {
+func (h *TodoController) DeleteTodo(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	id := c.Param("id")
-	if err := todoService.Delete(userID.(uint), id); err != nil {
-		common.Error(c, 500, "删除失败")
+	if err := h.todos.Delete(c.Request.Context(), userID.(uint), id); err != nil {
+		todoError(c, err, "删除失败")
 		return
 	}
 	// 删除成功也可以返回一个简单的 map 或者 null
 	common.Success(c, gin.H{"id": id})
-}
\ No newline at end of file
+}
+
+// AssignRequest 分配负责人请求
+// @Description 通过用户 ID 或用户名指定负责人
+type AssignRequest struct {
+	// 负责人用户 ID
+	UserID string `json:"user_id" example:"01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"`
+	// 负责人用户名（未提供 user_id 时使用）
+	Username string `json:"username" example:"jane_doe"`
+}
+
+// AssignTodo 分配负责人
+// @Summary 分配负责人
+// @Description 个人任务只能分配给自己，工作区任务可以分配给任意成员；需要任务的编辑权限
+// @Tags Todos
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Param request body AssignRequest true "负责人"
+// @Success 200 {object} models.Todo "分配后的任务"
+// @Failure 400 {object} common.Response "负责人不合法"
+// @Failure 403 {object} common.Response "没有编辑权限"
+// @Failure 404 {object} common.Response "任务不存在"
+// @Router /todos/{id}/assignees [post]
+func (h *TodoController) AssignTodo(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数格式错误")
+		return
+	}
+	var user models.User
+	var err error
+	if req.UserID != "" {
+		user, err = h.users.GetByPublicID(c.Request.Context(), req.UserID)
+	} else {
+		user, err = h.users.GetByUsername(c.Request.Context(), req.Username)
+	}
+	if err != nil {
+		common.Error(c, 400, "负责人不存在")
+		return
+	}
+
+	todo, err := h.todos.Assign(c.Request.Context(), userID.(uint), c.Param("id"), user.ID)
+	if errors.Is(err, service.ErrInvalidAssignee) {
+		common.Error(c, 400, err.Error())
+		return
+	}
+	if err != nil {
+		todoError(c, err, "分配失败")
+		return
+	}
+	localizeTodo(&todo, currentProfile(c).Location())
+	common.Success(c, todo)
+}
+
+// UnassignTodo 取消负责人
+// @Summary 取消负责人
+// @Tags Todos
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path string true "任务 ID"
+// @Param userID path string true "负责人用户 ID"
+// @Success 200 {object} models.Todo "取消后的任务"
+// @Failure 403 {object} common.Response "没有编辑权限"
+// @Failure 404 {object} common.Response "任务不存在"
+// @Router /todos/{id}/assignees/{userID} [delete]
+func (h *TodoController) UnassignTodo(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	assigneeID, ok := userIDParam(c, h.users, "userID")
+	if !ok {
+		return
+	}
+	todo, err := h.todos.Unassign(c.Request.Context(), userID.(uint), c.Param("id"), assigneeID)
+	if err != nil {
+		todoError(c, err, "取消分配失败")
+		return
+	}
+	localizeTodo(&todo, currentProfile(c).Location())
+	common.Success(c, todo)
+}
+
+// todoError 把 service 返回的错误转换成对应的错误码
+func todoError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		common.Error(c, 403, "没有权限操作该任务")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		common.Error(c, 404, "找不到该任务")
+	default:
+		common.Error(c, 500, msg)
+	}
+}
+
+// localizeTodo 把任务中的时间转换到用户时区再返回
+func localizeTodo(todo *models.Todo, loc *time.Location) {
+	if todo.DueDate != nil {
+		due := todo.DueDate.In(loc)
+		todo.DueDate = &due
+	}
+	todo.CreatedAt = todo.CreatedAt.In(loc)
+	todo.UpdatedAt = todo.UpdatedAt.In(loc)
+}
+
+func localizeTodos(todos []models.Todo, loc *time.Location) {
+	for i := range todos {
+		localizeTodo(&todos[i], loc)
+	}
+}
@@ -0,0 +1,509 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-todo/common"
+	"go-todo/config"
+	"go-todo/middleware"
+	"go-todo/migrations"
+	"go-todo/models"
+	"go-todo/ratelimit"
+	"go-todo/repository"
+	"go-todo/service"
+	"go-todo/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestRouter 用内存存储组装任务接口，请求以 user 的身份发出（代替 AuthMiddleware），
+// extra 是额外的中间件
+func newTestRouter(user models.User, users *repository.MemoryUserRepository, extra ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	todos := service.NewTodoService(repository.NewMemoryTodoRepository(users), nil)
+	h := NewTodoController(todos, service.NewUserService(users))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", user.ID)
+		c.Set("profile", user.Profile)
+	})
+	r.Use(extra...)
+	r.POST("/todos", h.CreateTask)
+	r.GET("/todos", h.GetTodos)
+	r.GET("/todos/:id", h.GetTodo)
+	return r
+}
+
+func doRequest(t *testing.T, r *gin.Engine, method, path, body string, data interface{}) common.Response {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	resp := common.Response{Data: data}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应不是 JSON: %s", w.Body.String())
+	}
+	return resp
+}
+
+// TestTodoHandlers 控制器使用内存存储测试，不需要数据库
+func TestTodoHandlers(t *testing.T) {
+	t.Parallel()
+	users := repository.NewMemoryUserRepository()
+	alice := models.User{Username: "alice", Profile: models.Profile{DefaultProject: "收件箱", DefaultSort: "title_asc"}}
+	users.Create(t.Context(), &alice)
+	r := newTestRouter(alice, users)
+
+	var created models.Todo
+	resp := doRequest(t, r, "POST", "/todos", `{"title":"写周报"}`, &created)
+	if resp.Code != 200 {
+		t.Fatalf("创建失败: %+v", resp)
+	}
+	if created.Project != "收件箱" || created.CreatorID != alice.PublicID {
+		t.Errorf("应该使用默认项目并返回创建者公开 ID: %+v", created)
+	}
+	doRequest(t, r, "POST", "/todos", `{"title":"买咖啡"}`, nil)
+
+	var page struct {
+		Data  []models.Todo `json:"data"`
+		Total int64         `json:"total"`
+	}
+	doRequest(t, r, "GET", "/todos", "", &page)
+	if page.Total != 2 || page.Data[0].Title != "买咖啡" {
+		t.Errorf("期望按用户偏好的标题顺序返回 2 条任务，但得到了 %+v", page)
+	}
+
+	if resp := doRequest(t, r, "GET", "/todos?sort=random", "", nil); resp.Code != 400 {
+		t.Errorf("不支持的排序期望 400，但得到了 %d", resp.Code)
+	}
+	if resp := doRequest(t, r, "GET", "/todos/"+models.NewPublicID(), "", nil); resp.Code != 404 {
+		t.Errorf("不存在的任务期望 404，但得到了 %d", resp.Code)
+	}
+}
+
+// TestTodoHandlersContext 超过处理时限或客户端断开后，查询被放弃并统一返回 504/503
+func TestTodoHandlersContext(t *testing.T) {
+	t.Parallel()
+	users := repository.NewMemoryUserRepository()
+	alice := models.User{Username: "alice"}
+	users.Create(t.Context(), &alice)
+
+	// 等到超时之后再进入处理函数
+	r := newTestRouter(alice, users, middleware.Timeout(time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	if resp := doRequest(t, r, "GET", "/todos", "", nil); resp.Code != 504 {
+		t.Errorf("超时期望 504，但得到了 %+v", resp)
+	}
+	if resp := doRequest(t, r, "GET", "/todos/"+models.NewPublicID(), "", nil); resp.Code != 504 {
+		t.Errorf("超时不应该被当成任务不存在，期望 504，但得到了 %+v", resp)
+	}
+
+	// 内层的 Timeout 覆盖外层更短的时限
+	r = newTestRouter(alice, users, middleware.Timeout(time.Nanosecond), middleware.Timeout(time.Minute))
+	if resp := doRequest(t, r, "POST", "/todos", `{"title":"写周报"}`, nil); resp.Code != 200 {
+		t.Errorf("期望使用内层的时限，但得到了 %+v", resp)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	newTestRouter(alice, users).ServeHTTP(w, httptest.NewRequest("GET", "/todos", nil).WithContext(ctx))
+	var resp common.Response
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Code != 503 {
+		t.Errorf("客户端断开期望 503，但得到了 %+v", resp)
+	}
+}
+
+// TestTodoHandlersTracing 请求 span 接在 traceparent 指定的上游链路后面，服务层的 span 挂在请求下
+func TestTodoHandlersTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	if _, err := tracing.Setup(t.Context(), tracing.Config{Exporter: tracing.ExporterNone}); err != nil {
+		t.Fatal(err)
+	}
+
+	users := repository.NewMemoryUserRepository()
+	alice := models.User{Username: "alice"}
+	users.Create(t.Context(), &alice)
+	r := newTestRouter(alice, users, middleware.Tracing())
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("POST", "/todos", strings.NewReader(`{"title":"写周报"}`))
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get("traceparent"); !strings.Contains(got, traceID) {
+		t.Errorf("响应头应该返回同一条链路的 traceparent，但得到了 %q", got)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		if s.SpanContext().TraceID().String() == traceID {
+			spans[s.Name()] = s
+		}
+	}
+	server, ok := spans["POST /todos"]
+	if !ok {
+		t.Fatalf("期望有请求 span，但得到了 %v", spans)
+	}
+	if server.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("请求 span 应该以上游 span 为父 span")
+	}
+	create, ok := spans["TodoService.Create"]
+	if !ok || create.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("期望 TodoService.Create 挂在请求 span 下，但得到了 %v", spans)
+	}
+}
+
+// TestTodoHandlersRateLimit 登录后按用户限流，超过限制返回 HTTP 429 和 Retry-After
+func TestTodoHandlersRateLimit(t *testing.T) {
+	t.Parallel()
+	users := repository.NewMemoryUserRepository()
+	alice, bob := models.User{Username: "alice"}, models.User{Username: "bob"}
+	users.Create(t.Context(), &alice)
+	users.Create(t.Context(), &bob)
+
+	store := ratelimit.NewMemoryStore()
+	limit := middleware.RateLimit("api", ratelimit.Limit{Requests: 60, Period: time.Minute, Burst: 2}, store)
+	r := newTestRouter(alice, users, limit)
+
+	for i, remaining := range []string{"1", "0"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/todos", nil))
+		if w.Code != 200 || w.Header().Get("RateLimit-Remaining") != remaining || w.Header().Get("RateLimit-Limit") != "2" {
+			t.Fatalf("第 %d 个请求期望放行并剩余 %s 个，但得到了 %d %v", i+1, remaining, w.Code, w.Header())
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/todos", nil))
+	var resp common.Response
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != 429 || resp.Code != 429 || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("超过限制期望 429 和 Retry-After: 1，但得到了 %d %v %+v", w.Code, w.Header(), resp)
+	}
+
+	// 每个用户有自己的令牌桶
+	w = httptest.NewRecorder()
+	newTestRouter(bob, users, limit).ServeHTTP(w, httptest.NewRequest("GET", "/todos", nil))
+	if w.Code != 200 {
+		t.Errorf("其他用户不应该被限流，但得到了 %d", w.Code)
+	}
+}
+
+// TestTodoHandlersCors 只回显允许的来源，预检请求直接返回，分组可以单独配置
+func TestTodoHandlersCors(t *testing.T) {
+	t.Parallel()
+	users := repository.NewMemoryUserRepository()
+	alice := models.User{Username: "alice"}
+	users.Create(t.Context(), &alice)
+
+	cors := middleware.Cors(config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}, middleware.CorsGroup{Prefix: "/todos", Config: config.CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET"},
+		AllowCredentials: true,
+	}})
+	r := newTestRouter(alice, users, cors)
+	r.GET("/me", func(c *gin.Context) { c.Status(200) })
+
+	serve := func(method, path, origin string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Origin", origin)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, origin := range []string{"https://app.example.com", "https://a.example.org", "https://a.b.example.org"} {
+		w := serve("GET", "/me", origin)
+		h := w.Header()
+		if w.Code != 200 || h.Get("Access-Control-Allow-Origin") != origin || h.Get("Access-Control-Allow-Credentials") != "true" ||
+			h.Get("Vary") != "Origin" || h.Get("Access-Control-Expose-Headers") != "X-Request-ID" {
+			t.Errorf("%s 期望被允许，但得到了 %d %v", origin, w.Code, h)
+		}
+	}
+	for _, origin := range []string{"https://evil.com", "https://example.org", "https://evil.com/.example.org", "http://app.example.com"} {
+		w := serve("GET", "/me", origin)
+		if w.Code != 200 || w.Header().Get("Access-Control-Allow-Origin") != "" || w.Header().Get("Vary") != "Origin" {
+			t.Errorf("%s 不应该被允许，但得到了 %d %v", origin, w.Code, w.Header())
+		}
+	}
+
+	// 预检请求
+	w := serve("OPTIONS", "/me", "https://app.example.com", "Access-Control-Request-Method", "POST", "Access-Control-Request-Headers", "content-type")
+	h := w.Header()
+	if w.Code != 204 || h.Get("Access-Control-Allow-Methods") != "GET, POST" || h.Get("Access-Control-Allow-Headers") != "Authorization, Content-Type" ||
+		h.Get("Access-Control-Max-Age") != "600" || h.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("预检请求期望 204，但得到了 %d %v", w.Code, h)
+	}
+	if w := serve("OPTIONS", "/me", "https://evil.com", "Access-Control-Request-Method", "GET"); w.Code != 403 || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("不允许的来源的预检请求期望 403，但得到了 %d %v", w.Code, w.Header())
+	}
+	if w := serve("OPTIONS", "/me", "https://app.example.com", "Access-Control-Request-Method", "DELETE"); w.Code != 403 {
+		t.Errorf("不允许的方法的预检请求期望 403，但得到了 %d", w.Code)
+	}
+
+	// 分组允许任意来源，此时不能携带凭据
+	w = serve("GET", "/todos", "https://evil.com")
+	if w.Code != 200 || w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("分组期望允许任意来源且不带凭据，但得到了 %d %v", w.Code, w.Header())
+	}
+	if w := serve("OPTIONS", "/todos", "https://evil.com", "Access-Control-Request-Method", "POST"); w.Code != 403 {
+		t.Errorf("分组只允许 GET，但得到了 %d", w.Code)
+	}
+}
+
+// TestTodoHandlersCookieAuth Cookie 登录和 Authorization 头可以同时使用，Cookie 登录修改数据需要 CSRF Token
+func TestTodoHandlersCookieAuth(t *testing.T) {
+	t.Parallel()
+	users := repository.NewMemoryUserRepository()
+	userService := service.NewUserService(users)
+	if err := userService.Register(t.Context(), "alice", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.SessionCookieConfig{Enabled: true, Name: "session", CSRFName: "csrf_token", Secure: true, SameSite: http.SameSiteLaxMode}
+	h := &UserController{users: userService, cookie: cfg}
+	todos := NewTodoController(service.NewTodoService(repository.NewMemoryTodoRepository(users), nil), userService)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/login", h.Login)
+	api := r.Group("", middleware.CookieToken(cfg), middleware.AuthMiddleware(userService))
+	api.POST("/logout", h.Logout)
+	api.POST("/logout-all", h.LogoutAll)
+	api.PUT("/me/password", h.ChangePassword)
+	api.GET("/todos", todos.GetTodos)
+	api.POST("/todos", todos.CreateTask)
+
+	serve := func(method, path, body string, cookies []*http.Cookie, header ...string) (*httptest.ResponseRecorder, common.Response) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp common.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("响应不是 JSON: %s", w.Body.String())
+		}
+		return w, resp
+	}
+
+	w, resp := serve("POST", "/login", `{"username":"alice","password":"password123","cookie":true}`, nil)
+	data, _ := resp.Data.(map[string]interface{})
+	csrfToken, _ := data["csrf_token"].(string)
+	cookies := w.Result().Cookies()
+	if resp.Code != 200 || csrfToken == "" || data["token"] != nil || len(cookies) != 2 {
+		t.Fatalf("Cookie 登录期望返回 CSRF Token 并设置两个 Cookie，但得到了 %+v %v", resp, cookies)
+	}
+	if session := cookies[0]; session.Name != "session" || !session.HttpOnly || !session.Secure || session.SameSite != http.SameSiteLaxMode {
+		t.Errorf("会话 Cookie 期望 HttpOnly、Secure、SameSite=Lax，但得到了 %+v", session)
+	}
+	if csrf := cookies[1]; csrf.Name != "csrf_token" || csrf.Value != csrfToken || csrf.HttpOnly {
+		t.Errorf("CSRF Cookie 期望前端可以读取，但得到了 %+v", csrf)
+	}
+
+	if _, resp := serve("GET", "/todos", "", cookies); resp.Code != 200 {
+		t.Errorf("Cookie 登录后读取数据不需要 CSRF Token，但得到了 %+v", resp)
+	}
+	if _, resp := serve("POST", "/todos", `{"title":"写周报"}`, cookies); resp.Code != 403 {
+		t.Errorf("没有 CSRF Token 期望 403，但得到了 %+v", resp)
+	}
+	if _, resp := serve("POST", "/todos", `{"title":"写周报"}`, cookies[:1], middleware.CSRFHeader, csrfToken); resp.Code != 403 {
+		t.Errorf("请求头和 CSRF Cookie 不一致期望 403，但得到了 %+v", resp)
+	}
+	// 攻击者自己的会话对应的 CSRF Token 不能用在别人的会话上
+	forged := []*http.Cookie{cookies[0], {Name: "csrf_token", Value: common.CSRFToken("other")}}
+	if _, resp := serve("POST", "/todos", `{"title":"写周报"}`, forged, middleware.CSRFHeader, forged[1].Value); resp.Code != 403 {
+		t.Errorf("不属于这个会话的 CSRF Token 期望 403，但得到了 %+v", resp)
+	}
+	if _, resp := serve("POST", "/todos", `{"title":"写周报"}`, cookies, middleware.CSRFHeader, csrfToken); resp.Code != 200 {
+		t.Errorf("带上 CSRF Token 期望创建成功，但得到了 %+v", resp)
+	}
+
+	// 修改密码后旧的会话失效，Cookie 登录换成新的会话 Cookie 和 CSRF Token
+	w, resp = serve("PUT", "/me/password", `{"old_password":"password123","new_password":"newpassword"}`, cookies, middleware.CSRFHeader, csrfToken)
+	data, _ = resp.Data.(map[string]interface{})
+	renewedCSRF, _ := data["csrf_token"].(string)
+	renewed := w.Result().Cookies()
+	if resp.Code != 200 || renewedCSRF == "" || renewedCSRF == csrfToken || data["token"] != nil || len(renewed) != 2 {
+		t.Fatalf("修改密码期望更新会话 Cookie 并返回新的 CSRF Token，但得到了 %+v %v", resp, renewed)
+	}
+	if _, resp := serve("GET", "/todos", "", cookies); resp.Code != 401 {
+		t.Errorf("修改密码后旧的会话 Cookie 期望 401，但得到了 %+v", resp)
+	}
+	cookies, csrfToken = renewed, renewedCSRF
+	if _, resp := serve("POST", "/todos", `{"title":"写月报"}`, cookies, middleware.CSRFHeader, csrfToken); resp.Code != 200 {
+		t.Errorf("修改密码后新的会话 Cookie 期望可以继续使用，但得到了 %+v", resp)
+	}
+
+	// Authorization 头不需要 CSRF Token
+	_, resp = serve("POST", "/login", `{"username":"alice","password":"newpassword"}`, nil)
+	token, _ := resp.Data.(map[string]interface{})["token"].(string)
+	if _, resp := serve("POST", "/todos", `{"title":"买咖啡"}`, nil, "Authorization", "Bearer "+token); resp.Code != 200 {
+		t.Errorf("Authorization 头期望创建成功，但得到了 %+v", resp)
+	}
+
+	// 退出登录同样需要 CSRF Token，只作废这个会话，Authorization 头使用的 Token 不受影响
+	if _, resp := serve("POST", "/logout", "", cookies); resp.Code != 403 {
+		t.Errorf("没有 CSRF Token 的退出登录期望 403，但得到了 %+v", resp)
+	}
+	w, resp = serve("POST", "/logout", "", cookies, middleware.CSRFHeader, csrfToken)
+	if resp.Code != 200 || len(w.Result().Cookies()) != 2 {
+		t.Fatalf("退出登录期望成功并删除 Cookie，但得到了 %+v %v", resp, w.Result().Cookies())
+	}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge >= 0 || cookie.Value != "" {
+			t.Errorf("退出登录期望删除 Cookie，但得到了 %+v", cookie)
+		}
+	}
+	if _, resp := serve("GET", "/todos", "", cookies); resp.Code != 401 {
+		t.Errorf("退出登录后旧的会话 Cookie 期望 401，但得到了 %+v", resp)
+	}
+	if _, resp := serve("GET", "/todos", "", nil, "Authorization", "Bearer "+token); resp.Code != 200 {
+		t.Errorf("Cookie 会话退出登录后 Authorization 头的 Token 期望仍然可用，但得到了 %+v", resp)
+	}
+
+	// 退出所有设备后所有 Token 都失效
+	w, resp = serve("POST", "/login", `{"username":"alice","password":"newpassword","cookie":true}`, nil)
+	cookies = w.Result().Cookies()
+	w, resp = serve("POST", "/logout-all", "", nil, "Authorization", "Bearer "+token)
+	if resp.Code != 200 || len(w.Result().Cookies()) != 2 {
+		t.Fatalf("退出所有设备期望成功并删除 Cookie，但得到了 %+v %v", resp, w.Result().Cookies())
+	}
+	if _, resp := serve("GET", "/todos", "", nil, "Authorization", "Bearer "+token); resp.Code != 401 {
+		t.Errorf("退出所有设备后旧的 Token 期望 401，但得到了 %+v", resp)
+	}
+	if _, resp := serve("GET", "/todos", "", cookies); resp.Code != 401 {
+		t.Errorf("退出所有设备后其他会话 Cookie 期望 401，但得到了 %+v", resp)
+	}
+
+	h.cookie.Enabled = false
+	if _, resp := serve("POST", "/login", `{"username":"alice","password":"password123","cookie":true}`, nil); resp.Code != 400 {
+		t.Errorf("未开启 Cookie 登录期望 400，但得到了 %+v", resp)
+	}
+}
+
+// TestTodoHandlersIdempotency 相同 Idempotency-Key 的重试重放响应，不同的请求返回 422，
+// 处理中返回 409，处理函数 panic 后可以用同一个 Key 重试
+func TestTodoHandlersIdempotency(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrations.New(db)
+	if err == nil {
+		_, err = m.Up()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.DB = db
+
+	users := repository.NewMemoryUserRepository()
+	alice := models.User{Username: "alice"}
+	users.Create(t.Context(), &alice)
+	recovery := gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ any) { c.AbortWithStatus(500) })
+	r := newTestRouter(alice, users, recovery, middleware.Idempotency())
+	started, release := make(chan struct{}), make(chan struct{})
+	r.POST("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		common.Success(c, nil)
+	})
+	panicked := false
+	r.POST("/panic", func(c *gin.Context) {
+		if !panicked {
+			panicked = true
+			panic("boom")
+		}
+		common.Success(c, nil)
+	})
+
+	serve := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set(middleware.IdempotencyHeader, key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	code := func(w *httptest.ResponseRecorder) int {
+		var resp common.Response
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Code
+	}
+
+	first := serve("/todos", "key-1", `{"title":"写周报"}`)
+	if code(first) != 200 {
+		t.Fatalf("创建失败: %s", first.Body.String())
+	}
+	replayed := serve("/todos", "key-1", `{"title":"写周报"}`)
+	if replayed.Header().Get("Idempotent-Replayed") != "true" || replayed.Body.String() != first.Body.String() {
+		t.Errorf("重试期望重放第一次的响应，但得到了 %v %s", replayed.Header(), replayed.Body.String())
+	}
+	if w := serve("/todos", "key-1", `{"title":"买咖啡"}`); code(w) != 422 {
+		t.Errorf("相同 Key 用于不同的请求期望 422，但得到了 %s", w.Body.String())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve("/slow", "key-2", "")
+	}()
+	<-started
+	if w := serve("/slow", "key-2", ""); code(w) != 409 {
+		t.Errorf("第一次请求还在处理时期望 409，但得到了 %s", w.Body.String())
+	}
+	close(release)
+	<-done
+
+	if w := serve("/panic", "key-3", ""); w.Code != 500 {
+		t.Fatalf("处理函数 panic 期望 500，但得到了 %d", w.Code)
+	}
+	if w := serve("/panic", "key-3", ""); code(w) != 200 {
+		t.Errorf("panic 之后期望可以用同一个 Key 重试，但得到了 %s", w.Body.String())
+	}
+
+	// 保存响应失败时释放 Key，重试会重新执行而不是一直得到 409
+	failComplete := true
+	db.Callback().Update().Before("gorm:update").Register("test:fail_idempotency", func(tx *gorm.DB) {
+		if failComplete && tx.Statement.Table == "idempotency_keys" {
+			tx.AddError(errors.New("boom"))
+		}
+	})
+	if w := serve("/todos", "key-4", `{"title":"买咖啡"}`); code(w) != 200 {
+		t.Fatalf("创建失败: %s", w.Body.String())
+	}
+	failComplete = false
+	if w := serve("/todos", "key-4", `{"title":"买咖啡"}`); code(w) != 200 || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("保存响应失败后期望可以用同一个 Key 重试，但得到了 %v %s", w.Header(), w.Body.String())
+	}
+}
@@ -2,12 +2,23 @@ package controllers
 
 import (
 	"go-todo/common"
+	"go-todo/config"
 	"go-todo/service"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-var userService = service.UserService{}
+// UserController 注册、登录和当前用户的账号接口
+type UserController struct {
+	users  *service.UserService
+	cookie config.SessionCookieConfig
+}
+
+// NewUserController 创建 UserController，Cookie 登录按 session.cookie.* 配置
+func NewUserController(users *service.UserService) *UserController {
+	return &UserController{users: users, cookie: config.SessionCookie()}
+}
 
 // AuthRequest 认证请求
 // @Description 用户登录和注册请求结构体
@@ -18,6 +29,14 @@ type AuthRequest struct {
 	Password string `json:"password" binding:"required" example:"password123"`
 }
 
+// LoginRequest 登录请求
+// @Description 用户登录请求结构体
+type LoginRequest struct {
+	AuthRequest
+	// 为 true 时 Token 保存在 HttpOnly Cookie 中，响应只返回 CSRF Token（需要开启 session.cookie.enabled）
+	Cookie bool `json:"cookie" example:"false"`
+}
+
 // Register 用户注册
 // @Summary 用户注册
 // @Description 新用户注册，需要提供用户名和密码
@@ -29,14 +48,14 @@ type AuthRequest struct {
 // @Failure 400 {object} common.Response "参数验证失败或用户已存在"
 // @Failure 500 {object} common.Response "服务器错误"
 // @Router /auth/register [post]
-func Register(c *gin.Context) {
+func (h *UserController) Register(c *gin.Context) {
     var req AuthRequest
     if err := c.ShouldBindJSON(&req); err != nil {
         common.Error(c, 400, "参数验证失败")
         return
     }
 
-    if err := userService.Register(req.Username, req.Password); err != nil {
+    if err := h.users.Register(c.Request.Context(), req.Username, req.Password); err != nil {
         common.Error(c, 400, err.Error())
         return
     }
@@ -46,35 +65,115 @@ func Register(c *gin.Context) {
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户使用用户名和密码登录，返回 JWT 令牌
+// @Description 用户使用用户名和密码登录，返回 JWT 令牌；cookie 为 true 时令牌写入 HttpOnly Cookie，返回 CSRF Token
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Param request body AuthRequest true "登录请求信息"
-// @Success 200 {object} map[string]string "登录成功，返回 JWT 令牌"
-// @Failure 400 {object} common.Response "参数验证失败"
+// @Param request body LoginRequest true "登录请求信息"
+// @Success 200 {object} map[string]string "登录成功，返回 JWT 令牌或 CSRF Token"
+// @Failure 400 {object} common.Response "参数验证失败或未开启 Cookie 登录"
 // @Failure 401 {object} common.Response "用户不存在或密码错误"
 // @Failure 500 {object} common.Response "服务器错误"
 // @Router /auth/login [post]
-func Login(c *gin.Context) {
-    var req AuthRequest
+func (h *UserController) Login(c *gin.Context) {
+    var req LoginRequest
     // 1. 绑定并校验参数
     if err := c.ShouldBindJSON(&req); err != nil {
         common.Error(c, 400, "参数验证失败: "+err.Error())
         return
     }
+    if req.Cookie && !h.cookie.Enabled {
+        common.Error(c, 400, "未开启 Cookie 登录")
+        return
+    }
 
     // 2. 调用 Service 进行登录验证并获取 Token
     // 这里的 token 变量接收的就是 service 返回的字符串
-    token, err := userService.Login(req.Username, req.Password)
+    token, err := h.users.Login(c.Request.Context(), req.Username, req.Password)
     if err != nil {
         // 登录失败（用户不存在或密码错误）返回 401
         common.Error(c, 401, err.Error())
         return
     }
 
+    // Cookie 登录：Token 只放在 HttpOnly Cookie 中，前端脚本读不到；
+    // 之后修改数据的请求需要在 X-CSRF-Token 请求头中带上这里返回的 CSRF Token
+    if req.Cookie {
+        csrfToken := common.CSRFToken(token)
+        h.setSessionCookies(c, token, csrfToken, int(common.TokenTTL.Seconds()))
+        common.Success(c, gin.H{
+            "csrf_token": csrfToken,
+        })
+        return
+    }
+
     // 3. 登录成功，直接把 Token 返回给前端
     common.Success(c, gin.H{
         "token": token,
     })
+}
+
+// Logout 退出登录
+// @Summary 退出登录
+// @Description 只作废这次请求使用的 Token，其他设备和另一种登录方式的会话不受影响；Cookie 登录时同时清除会话 Cookie。
+// @Description Cookie 登录时需要在 X-CSRF-Token 请求头中带上 CSRF Token
+// @Tags Auth
+// @Produce json
+// @Param Authorization header string false "Bearer Token（Cookie 登录时不需要）"
+// @Param X-CSRF-Token header string false "CSRF Token（Cookie 登录时需要）"
+// @Success 200 {object} common.Response "已退出登录"
+// @Failure 401 {object} common.Response "未登录"
+// @Failure 403 {object} common.Response "CSRF Token 无效"
+// @Router /auth/logout [post]
+func (h *UserController) Logout(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	claims, _ := c.Get("claims")
+	if err := h.users.Logout(c.Request.Context(), userID.(uint), claims.(*common.MyCustomClaims)); err != nil {
+		common.Error(c, 500, "退出登录失败")
+		return
+	}
+	if c.GetBool("cookieAuth") {
+		h.setSessionCookies(c, "", "", -1)
+	}
+	common.Success(c, "已退出登录")
+}
+
+// LogoutAll 退出所有设备
+// @Summary 退出所有设备
+// @Description 作废当前用户之前签发的所有 Token（所有设备都需要重新登录，被窃取的 Cookie 也随之失效），并清除会话 Cookie。
+// @Description Cookie 登录时需要在 X-CSRF-Token 请求头中带上 CSRF Token
+// @Tags Auth
+// @Produce json
+// @Param Authorization header string false "Bearer Token（Cookie 登录时不需要）"
+// @Param X-CSRF-Token header string false "CSRF Token（Cookie 登录时需要）"
+// @Success 200 {object} common.Response "已退出所有设备"
+// @Failure 401 {object} common.Response "未登录"
+// @Failure 403 {object} common.Response "CSRF Token 无效"
+// @Router /auth/logout-all [post]
+func (h *UserController) LogoutAll(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	if err := h.users.LogoutAll(c.Request.Context(), userID.(uint)); err != nil {
+		common.Error(c, 500, "退出登录失败")
+		return
+	}
+	if h.cookie.Enabled {
+		h.setSessionCookies(c, "", "", -1)
+	}
+	common.Success(c, "已退出所有设备")
+}
+
+// setSessionCookies 写入会话 Cookie 和 CSRF Cookie，maxAge 为负数时删除它们。
+// CSRF Cookie 不设置 HttpOnly，和 API 同站的前端可以直接读取
+func (h *UserController) setSessionCookies(c *gin.Context, token, csrfToken string, maxAge int) {
+	for _, cookie := range []*http.Cookie{
+		{Name: h.cookie.Name, Value: token, HttpOnly: true},
+		{Name: h.cookie.CSRFName, Value: csrfToken},
+	} {
+		cookie.Path = "/"
+		cookie.Domain = h.cookie.Domain
+		cookie.MaxAge = maxAge
+		cookie.Secure = h.cookie.Secure
+		cookie.SameSite = h.cookie.SameSite
+		http.SetCookie(c.Writer, cookie)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"errors"
+	"go-todo/common"
+	"go-todo/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var webhookService = service.WebhookService{}
+
+// WebhookRequest 创建/修改 Webhook 请求
+// @Description 事件类型可选 todo.created、todo.updated、todo.deleted，为空表示全部
+type WebhookRequest struct {
+	// 接收事件的地址
+	URL string `json:"url" binding:"required" example:"https://ci.example.com/hooks/todo"`
+	// 订阅的事件类型
+	Events []string `json:"events" example:"todo.created,todo.updated"`
+	// 是否启用，默认启用
+	Active *bool `json:"active" example:"true"`
+}
+
+func (r WebhookRequest) toService() service.WebhookRequest {
+	return service.WebhookRequest{URL: r.URL, Events: r.Events, Active: r.Active}
+}
+
+// CreateWebhook 创建 Webhook
+// @Summary 创建 Webhook
+// @Description 任务变更时向 URL 发送 HMAC-SHA256 签名的 POST 请求；返回的 secret 只显示这一次
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param request body WebhookRequest true "Webhook 信息"
+// @Success 200 {object} models.Webhook "创建成功，包含签名密钥"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "URL 不能为空")
+		return
+	}
+	hook, err := webhookService.Create(c.Request.Context(), userID.(uint), req.toService())
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	common.Success(c, hook)
+}
+
+// GetWebhooks Webhook 列表
+// @Summary 列出我的 Webhook
+// @Tags Webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.Webhook "Webhook 列表（不含密钥）"
+// @Router /webhooks [get]
+func GetWebhooks(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	hooks, err := webhookService.List(c.Request.Context(), userID.(uint))
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	common.Success(c, hooks)
+}
+
+// GetWebhook Webhook 详情
+// @Summary 获取 Webhook
+// @Tags Webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.Webhook "Webhook（不含密钥）"
+// @Failure 404 {object} common.Response "Webhook 不存在"
+// @Router /webhooks/{id} [get]
+func GetWebhook(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	hook, err := webhookService.Get(c.Request.Context(), userID.(uint), id)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	common.Success(c, hook)
+}
+
+// UpdateWebhook 修改 Webhook
+// @Summary 修改 Webhook
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Webhook ID"
+// @Param request body WebhookRequest true "Webhook 信息"
+// @Success 200 {object} models.Webhook "修改成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Webhook 不存在"
+// @Router /webhooks/{id} [put]
+func UpdateWebhook(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "URL 不能为空")
+		return
+	}
+	hook, err := webhookService.Update(c.Request.Context(), userID.(uint), id, req.toService())
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	common.Success(c, hook)
+}
+
+// DeleteWebhook 删除 Webhook
+// @Summary 删除 Webhook
+// @Description 同时删除投递记录，未发送的投递不再发送
+// @Tags Webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 404 {object} common.Response "Webhook 不存在"
+// @Router /webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := webhookService.Delete(c.Request.Context(), userID.(uint), id); err != nil {
+		webhookError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": id})
+}
+
+// GetWebhookDeliveries 投递记录
+// @Summary Webhook 投递记录
+// @Description 最近 100 次投递，包括状态、尝试次数、响应状态码和错误
+// @Tags Webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Webhook ID"
+// @Success 200 {array} models.WebhookDelivery "投递记录"
+// @Failure 404 {object} common.Response "Webhook 不存在"
+// @Router /webhooks/{id}/deliveries [get]
+func GetWebhookDeliveries(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	deliveries, err := webhookService.Deliveries(c.Request.Context(), userID.(uint), id, 100)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	common.Success(c, deliveries)
+}
+
+// TestWebhook 发送测试事件
+// @Summary 发送测试事件
+// @Description 立即发送一条 webhook.test 事件并返回投递结果，失败时会按正常规则重试
+// @Tags Webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.WebhookDelivery "投递结果"
+// @Failure 404 {object} common.Response "Webhook 不存在"
+// @Router /webhooks/{id}/test [post]
+func TestWebhook(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	delivery, err := webhookService.Test(c.Request.Context(), userID.(uint), id)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	common.Success(c, delivery)
+}
+
+func webhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrWebhookInvalid):
+		common.Error(c, 400, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		common.Error(c, 404, "Webhook 不存在")
+	default:
+		common.Error(c, 500, "Webhook 操作失败")
+	}
+}
@@ -0,0 +1,433 @@
+package controllers
+
+import (
+	"errors"
+	"go-todo/common"
+	"go-todo/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var workspaceService = service.WorkspaceService{}
+
+// WorkspaceController 工作区、成员和邀请的接口
+type WorkspaceController struct {
+	users *service.UserService
+}
+
+// NewWorkspaceController 创建 WorkspaceController，users 用来解析路径中的用户 ID
+func NewWorkspaceController(users *service.UserService) *WorkspaceController {
+	return &WorkspaceController{users: users}
+}
+
+// WorkspaceRequest 创建/修改工作区请求
+// @Description 工作区名称
+type WorkspaceRequest struct {
+	// 工作区名称
+	Name string `json:"name" binding:"required" example:"产品研发组"`
+}
+
+// MemberRoleRequest 修改成员角色请求
+// @Description 成员角色，设为 owner 表示转让工作区
+type MemberRoleRequest struct {
+	// 角色：owner / editor / viewer
+	Role string `json:"role" binding:"required" example:"editor"`
+}
+
+// InviteRequest 邀请请求
+// @Description 指定 username 邀请某个用户，不指定则生成邀请链接
+type InviteRequest struct {
+	// 被邀请的用户名，可选
+	Username string `json:"username" example:"jane_doe"`
+	// 加入后的角色：editor / viewer
+	Role string `json:"role" binding:"required" example:"editor"`
+	// 有效期（小时），0 表示不过期
+	ExpiresInHours int `json:"expires_in_hours" example:"72"`
+}
+
+// CreateWorkspace 创建工作区
+// @Summary 创建工作区
+// @Description 创建一个工作区，创建者成为拥有者
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param request body WorkspaceRequest true "工作区信息"
+// @Success 200 {object} models.Workspace "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /workspaces [post]
+func (h *WorkspaceController) CreateWorkspace(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数验证失败")
+		return
+	}
+	ws, err := workspaceService.Create(c.Request.Context(), userID.(uint), req.Name)
+	if err != nil {
+		common.Error(c, 500, "创建失败")
+		return
+	}
+	common.Success(c, ws)
+}
+
+// GetWorkspaces 我的工作区
+// @Summary 列出我加入的工作区
+// @Description 返回当前用户加入的所有工作区及其角色
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.Workspace "工作区列表"
+// @Router /workspaces [get]
+func (h *WorkspaceController) GetWorkspaces(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	list, err := workspaceService.List(c.Request.Context(), userID.(uint))
+	if err != nil {
+		common.Error(c, 500, "查询失败")
+		return
+	}
+	common.Success(c, list)
+}
+
+// GetWorkspace 工作区详情
+// @Summary 获取工作区详情
+// @Description 返回工作区信息和成员列表，成员才能查看
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Success 200 {object} map[string]interface{} "工作区和成员"
+// @Failure 403 {object} common.Response "不是该工作区成员"
+// @Router /workspaces/{id} [get]
+func (h *WorkspaceController) GetWorkspace(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	ws, err := workspaceService.Get(c.Request.Context(), userID.(uint), id)
+	if err != nil {
+		workspaceError(c, err)
+		return
+	}
+	members, err := workspaceService.Members(c.Request.Context(), userID.(uint), id)
+	if err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"workspace": ws, "members": members})
+}
+
+// UpdateWorkspace 修改工作区
+// @Summary 修改工作区名称
+// @Description 只有拥有者可以修改
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Param request body WorkspaceRequest true "工作区信息"
+// @Success 200 {object} common.Response "修改成功"
+// @Failure 403 {object} common.Response "权限不足"
+// @Router /workspaces/{id} [put]
+func (h *WorkspaceController) UpdateWorkspace(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数验证失败")
+		return
+	}
+	if err := workspaceService.Rename(c.Request.Context(), userID.(uint), id, req.Name); err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": id, "name": req.Name})
+}
+
+// DeleteWorkspace 删除工作区
+// @Summary 删除工作区
+// @Description 删除工作区及其中所有任务，只有拥有者可以删除
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 403 {object} common.Response "权限不足"
+// @Router /workspaces/{id} [delete]
+func (h *WorkspaceController) DeleteWorkspace(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := workspaceService.Delete(c.Request.Context(), userID.(uint), id); err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": id})
+}
+
+// GetWorkspaceMembers 成员列表
+// @Summary 列出工作区成员
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Success 200 {array} models.WorkspaceMember "成员列表"
+// @Failure 403 {object} common.Response "不是该工作区成员"
+// @Router /workspaces/{id}/members [get]
+func (h *WorkspaceController) GetWorkspaceMembers(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	members, err := workspaceService.Members(c.Request.Context(), userID.(uint), id)
+	if err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, members)
+}
+
+// SetWorkspaceMemberRole 修改成员角色
+// @Summary 修改成员角色
+// @Description 拥有者修改成员角色；设为 owner 表示转让工作区，原拥有者变为 editor
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Param userID path string true "成员用户 ID"
+// @Param request body MemberRoleRequest true "角色"
+// @Success 200 {object} common.Response "修改成功"
+// @Failure 403 {object} common.Response "权限不足"
+// @Failure 404 {object} common.Response "成员不存在"
+// @Router /workspaces/{id}/members/{userID} [put]
+func (h *WorkspaceController) SetWorkspaceMemberRole(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := userIDParam(c, h.users, "userID")
+	if !ok {
+		return
+	}
+	var req MemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数验证失败")
+		return
+	}
+	if err := workspaceService.SetMemberRole(c.Request.Context(), userID.(uint), id, memberID, req.Role); err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"user_id": memberID, "role": req.Role})
+}
+
+// RemoveWorkspaceMember 移除成员
+// @Summary 移除成员或退出工作区
+// @Description 拥有者可以移除任何成员；成员可以移除自己（退出工作区）
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Param userID path string true "成员用户 ID"
+// @Success 200 {object} common.Response "移除成功"
+// @Failure 403 {object} common.Response "权限不足"
+// @Failure 404 {object} common.Response "成员不存在"
+// @Router /workspaces/{id}/members/{userID} [delete]
+func (h *WorkspaceController) RemoveWorkspaceMember(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := userIDParam(c, h.users, "userID")
+	if !ok {
+		return
+	}
+	if err := workspaceService.RemoveMember(c.Request.Context(), userID.(uint), id, memberID); err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"user_id": memberID})
+}
+
+// CreateWorkspaceInvite 邀请成员
+// @Summary 邀请成员
+// @Description 指定 username 时邀请该用户（对方在"我的邀请"中接受）；不指定时生成可多次使用的邀请链接
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Param request body InviteRequest true "邀请信息"
+// @Success 200 {object} models.WorkspaceInvite "邀请信息，token 用于接受邀请"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 403 {object} common.Response "权限不足"
+// @Router /workspaces/{id}/invites [post]
+func (h *WorkspaceController) CreateWorkspaceInvite(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, 400, "参数验证失败")
+		return
+	}
+	ttl := time.Duration(req.ExpiresInHours) * time.Hour
+	invite, err := workspaceService.Invite(c.Request.Context(), userID.(uint), id, req.Username, req.Role, ttl)
+	if err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, invite)
+}
+
+// GetWorkspaceInvites 邀请列表
+// @Summary 列出工作区的邀请
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Success 200 {array} models.WorkspaceInvite "邀请列表"
+// @Failure 403 {object} common.Response "权限不足"
+// @Router /workspaces/{id}/invites [get]
+func (h *WorkspaceController) GetWorkspaceInvites(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	invites, err := workspaceService.Invites(c.Request.Context(), userID.(uint), id)
+	if err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, invites)
+}
+
+// RevokeWorkspaceInvite 撤销邀请
+// @Summary 撤销邀请
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param id path int true "工作区 ID"
+// @Param inviteID path int true "邀请 ID"
+// @Success 200 {object} common.Response "撤销成功"
+// @Failure 403 {object} common.Response "权限不足"
+// @Router /workspaces/{id}/invites/{inviteID} [delete]
+func (h *WorkspaceController) RevokeWorkspaceInvite(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	inviteID, ok := idParam(c, "inviteID")
+	if !ok {
+		return
+	}
+	if err := workspaceService.RevokeInvite(c.Request.Context(), userID.(uint), id, inviteID); err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"id": inviteID})
+}
+
+// GetMyInvites 我的邀请
+// @Summary 列出发给我的邀请
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Success 200 {array} models.WorkspaceInvite "邀请列表"
+// @Router /invites [get]
+func (h *WorkspaceController) GetMyInvites(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	invites, err := workspaceService.PendingInvites(c.Request.Context(), userID.(uint))
+	if err != nil {
+		common.Error(c, 500, "查询失败")
+		return
+	}
+	common.Success(c, invites)
+}
+
+// AcceptInvite 接受邀请
+// @Summary 接受邀请
+// @Description 通过邀请 token（来自"我的邀请"或邀请链接）加入工作区
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param token path string true "邀请 token"
+// @Success 200 {object} models.Workspace "加入的工作区"
+// @Failure 404 {object} common.Response "邀请不存在或已失效"
+// @Router /invites/{token}/accept [post]
+func (h *WorkspaceController) AcceptInvite(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	ws, err := workspaceService.AcceptInvite(c.Request.Context(), userID.(uint), c.Param("token"))
+	if err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, ws)
+}
+
+// DeclineInvite 拒绝邀请
+// @Summary 拒绝邀请
+// @Tags Workspaces
+// @Produce json
+// @Param Authorization header string true "Bearer Token"
+// @Param token path string true "邀请 token"
+// @Success 200 {object} common.Response "已拒绝"
+// @Failure 404 {object} common.Response "邀请不存在或已失效"
+// @Router /invites/{token}/decline [post]
+func (h *WorkspaceController) DeclineInvite(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	if err := workspaceService.DeclineInvite(c.Request.Context(), userID.(uint), c.Param("token")); err != nil {
+		workspaceError(c, err)
+		return
+	}
+	common.Success(c, "已拒绝邀请")
+}
+
+// idParam 解析路径中的数字 ID，失败时直接返回错误响应
+func idParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		common.Error(c, 400, name+" 参数格式错误")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// userIDParam 把路径中用户的公开 ID 解析成内部 ID
+func userIDParam(c *gin.Context, users *service.UserService, name string) (uint, bool) {
+	user, err := users.GetByPublicID(c.Request.Context(), c.Param(name))
+	if err != nil {
+		common.Error(c, 404, "用户不存在")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func workspaceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		common.Error(c, 403, err.Error())
+	case errors.Is(err, service.ErrMemberNotFound), errors.Is(err, service.ErrInviteInvalid),
+		errors.Is(err, service.ErrUserNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		common.Error(c, 404, err.Error())
+	default:
+		common.Error(c, 400, err.Error())
+	}
+}
@@ -15,54 +15,88 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "用户使用用户名和密码登录，返回 JWT 令牌",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/stats": {
+            "get": {
+                "description": "用户数、任务数、近 7 天新增等统计信息",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "用户登录",
+                "summary": "查看使用统计",
                 "parameters": [
                     {
-                        "description": "登录请求信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.AuthRequest"
-                        }
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功，返回 JWT 令牌",
+                        "description": "统计信息",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/service.UsageStats"
                         }
                     },
-                    "400": {
-                        "description": "参数验证失败",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "description": "管理员分页查看用户，可按用户名或显示名称搜索",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "列出/搜索用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     },
-                    "401": {
-                        "description": "用户不存在或密码错误",
+                    {
+                        "type": "string",
+                        "description": "搜索关键字",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码，默认为 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量，默认为 10，最多 100",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用户列表和分页信息",
                         "schema": {
-                            "$ref": "#/definitions/common.Response"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
@@ -70,45 +104,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
+        "/admin/users/{id}/disable": {
             "post": {
-                "description": "新用户注册，需要提供用户名和密码",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "禁用后该用户无法登录，已签发的 Token 也立即失效",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "用户注册",
+                "summary": "禁用账号",
                 "parameters": [
                     {
-                        "description": "注册请求信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.AuthRequest"
-                        }
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "注册成功",
+                        "description": "操作成功",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     },
                     "400": {
-                        "description": "参数验证失败或用户已存在",
+                        "description": "操作失败",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "404": {
+                        "description": "用户不存在",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
@@ -116,19 +152,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/todos": {
-            "get": {
-                "description": "获取当前用户的所有任务，支持分页查询",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/users/{id}/enable": {
+            "post": {
+                "description": "重新启用被禁用的账号",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Todos"
+                    "Admin"
                 ],
-                "summary": "获取所有任务",
+                "summary": "启用账号",
                 "parameters": [
                     {
                         "type": "string",
@@ -138,52 +171,45 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "页码，默认为 1",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页数量，默认为 10",
-                        "name": "pageSize",
-                        "in": "query"
+                        "type": "string",
+                        "description": "用户 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "返回任务列表和分页信息",
+                        "description": "操作成功",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/common.Response"
                         }
                     },
                     "400": {
-                        "description": "请求参数错误",
+                        "description": "操作失败",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "404": {
+                        "description": "用户不存在",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{id}/force-password-reset": {
             "post": {
-                "description": "创建一个新的任务，需要传递 title 字段，user_id 会自动从 Token 获取",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "作废该用户所有 Token，重新登录后必须先修改密码才能访问其他接口",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Todos"
+                    "Admin"
                 ],
-                "summary": "创建一个新任务",
+                "summary": "强制用户重置密码",
                 "parameters": [
                     {
                         "type": "string",
@@ -193,30 +219,22 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "任务信息",
-                        "name": "todo",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Todo"
-                        }
+                        "type": "string",
+                        "description": "用户 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
-                        "schema": {
-                            "$ref": "#/definitions/models.Todo"
-                        }
-                    },
-                    "400": {
-                        "description": "请求参数错误",
+                    "200": {
+                        "description": "操作成功",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "404": {
+                        "description": "用户不存在",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
@@ -224,9 +242,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/todos/{id}": {
-            "get": {
-                "description": "根据任务 ID 获取单个任务详情",
+        "/admin/users/{id}/role": {
+            "put": {
+                "description": "设置用户为普通用户或管理员",
                 "consumes": [
                     "application/json"
                 ],
@@ -234,9 +252,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Todos"
+                    "Admin"
                 ],
-                "summary": "获取单个任务",
+                "summary": "修改用户角色",
                 "parameters": [
                     {
                         "type": "string",
@@ -247,35 +265,46 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "任务 ID",
+                        "description": "用户 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "角色",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SetRoleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "操作成功",
                         "schema": {
-                            "$ref": "#/definitions/models.Todo"
+                            "$ref": "#/definitions/common.Response"
                         }
                     },
-                    "404": {
-                        "description": "任务不存在",
+                    "400": {
+                        "description": "操作失败",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "404": {
+                        "description": "用户不存在",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "更新指定 ID 的任务信息",
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "用户使用用户名和密码登录，返回 JWT 令牌；cookie 为 true 时令牌写入 HttpOnly Cookie，返回 CSRF Token",
                 "consumes": [
                     "application/json"
                 ],
@@ -283,49 +312,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Todos"
+                    "Auth"
                 ],
-                "summary": "更新任务",
+                "summary": "用户登录",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bearer Token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "任务 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "更新的任务信息",
-                        "name": "todo",
+                        "description": "登录请求信息",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Todo"
+                            "$ref": "#/definitions/controllers.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "登录成功，返回 JWT 令牌或 CSRF Token",
                         "schema": {
-                            "$ref": "#/definitions/models.Todo"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
-                        "description": "请求参数错误",
+                        "description": "参数验证失败或未开启 Cookie 登录",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     },
-                    "404": {
-                        "description": "任务不存在",
+                    "401": {
+                        "description": "用户不存在或密码错误",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
@@ -337,123 +355,3821 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "删除指定 ID 的任务",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "只作废这次请求使用的 Token，其他设备和另一种登录方式的会话不受影响；Cookie 登录时同时清除会话 Cookie。\nCookie 登录时需要在 X-CSRF-Token 请求头中带上 CSRF Token",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Todos"
+                    "Auth"
                 ],
-                "summary": "删除任务",
+                "summary": "退出登录",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bearer Token",
+                        "description": "Bearer Token（Cookie 登录时不需要）",
                         "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "in": "header"
                     },
                     {
                         "type": "string",
-                        "description": "任务 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "CSRF Token（Cookie 登录时需要）",
+                        "name": "X-CSRF-Token",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "已退出登录",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/common.Response"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "CSRF Token 无效",
                         "schema": {
                             "$ref": "#/definitions/common.Response"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "common.Response": {
+        },
+        "/auth/logout-all": {
+            "post": {
+                "description": "作废当前用户之前签发的所有 Token（所有设备都需要重新登录，被窃取的 Cookie 也随之失效），并清除会话 Cookie。\nCookie 登录时需要在 X-CSRF-Token 请求头中带上 CSRF Token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "退出所有设备",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token（Cookie 登录时不需要）",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSRF Token（Cookie 登录时需要）",
+                        "name": "X-CSRF-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已退出所有设备",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "CSRF Token 无效",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "新用户注册，需要提供用户名和密码",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "用户注册",
+                "parameters": [
+                    {
+                        "description": "注册请求信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "注册成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数验证失败或用户已存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "推送 todo.created / todo.updated / todo.deleted 事件，定期发送心跳注释。\n重连时通过 Last-Event-ID 请求头（或 last_event_id 参数）补发错过的事件；\n错过的事件已不在缓冲区时推送 reset 事件，客户端需重新拉取任务列表。\nEventSource 不能设置请求头，可以用 access_token 参数传 Token",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "订阅任务变更事件（Server-Sent Events）",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token（用于浏览器 EventSource）",
+                        "name": "access_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "最后收到的事件 ID",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "事件流",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/ws": {
+            "get": {
+                "description": "与 SSE 相同的事件，每条消息是一个 JSON 事件；服务端定期发送 ping 帧。\n重连时用 last_event_id 参数补发错过的事件，浏览器可以用 access_token 参数传 Token",
+                "tags": [
+                    "Events"
+                ],
+                "summary": "订阅任务变更事件（WebSocket）",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token（用于浏览器 WebSocket）",
+                        "name": "access_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最后收到的事件 ID",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "切换到 WebSocket 协议",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Cookie 登录时来源不在 cors.allowed_origins 中",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/invites": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "列出发给我的邀请",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "邀请列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WorkspaceInvite"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/invites/{token}/accept": {
+            "post": {
+                "description": "通过邀请 token（来自\"我的邀请\"或邀请链接）加入工作区",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "接受邀请",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "邀请 token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "加入的工作区",
+                        "schema": {
+                            "$ref": "#/definitions/models.Workspace"
+                        }
+                    },
+                    "404": {
+                        "description": "邀请不存在或已失效",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/invites/{token}/decline": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "拒绝邀请",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "邀请 token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已拒绝",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "邀请不存在或已失效",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "description": "返回当前 Token 对应的用户信息及个人偏好",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "获取当前登录用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UserInfo"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "校验密码后永久删除当前用户及其全部数据，所有已签发的 Token 立即失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "注销账号",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "密码确认",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DeleteAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "注销成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或仍拥有共享工作区",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/assigned": {
+            "get": {
+                "description": "跨个人任务和所有已加入的工作区（项目），返回分配给当前用户的任务，支持与任务列表相同的分页、过滤和排序参数",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Todos"
+                ],
+                "summary": "获取分配给我的任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码，默认为 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量，默认为 10，最多 100",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "按项目过滤",
+                        "name": "project",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序方式：created_asc, created_desc, due_asc, due_desc, title_asc",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "按截止时间过滤（按用户时区计算）：today, week, overdue",
+                        "name": "due",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回任务列表和分页信息",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/export": {
+            "get": {
+                "description": "以 ZIP 格式导出当前用户的个人资料、任务及所有相关记录（JSON 格式）",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "导出个人数据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ZIP 压缩包",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "put": {
+                "description": "校验旧密码后设置新密码，其他设备上的 Token 全部失效，返回新的 Token；被管理员强制重置密码的用户只能访问此接口。\nCookie 登录时新 Token 写入会话 Cookie，响应返回新的 CSRF Token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "修改密码",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "旧密码和新密码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功，返回新 Token 或新的 CSRF Token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "旧密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/profile": {
+            "put": {
+                "description": "整体更新显示名称、时区、语言、默认项目、每周起始日和默认排序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "更新个人资料与偏好",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "个人资料",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Profile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/shares/{token}": {
+            "get": {
+                "description": "通过分享 token 查看分享的任务或项目；设置了密码时需要在 X-Share-Password 请求头中提供",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "访问分享内容（无需登录）",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "分享 token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "访问密码",
+                        "name": "X-Share-Password",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "分享内容",
+                        "schema": {
+                            "$ref": "#/definitions/service.SharedContent"
+                        }
+                    },
+                    "401": {
+                        "description": "需要访问密码",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "分享不存在或已失效",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/shares/{token}/todos/{todoID}/comments": {
+            "get": {
+                "description": "只返回作者名称，不返回作者的用户 ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "查看分享任务的评论（无需登录）",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "分享 token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "todoID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "访问密码",
+                        "name": "X-Share-Password",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "评论列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Comment"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "需要访问密码",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "分享或任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "只有 comment 权限的分享链接可以评论",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "通过分享链接发表评论（无需登录）",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "分享 token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "todoID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "访问密码",
+                        "name": "X-Share-Password",
+                        "in": "header"
+                    },
+                    {
+                        "description": "评论内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SharedCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "发表成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
+                    },
+                    "401": {
+                        "description": "需要访问密码",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "分享链接不允许评论",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "分享或任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/shares": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Shares"
+                ],
+                "summary": "列出我创建的分享链接",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "分享链接列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ShareLink"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "为任务或项目创建只读/可评论的公开链接，可设置过期时间和访问密码；需要编辑权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Shares"
+                ],
+                "summary": "创建分享链接",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "分享信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "分享链接，token 用于公开访问",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShareLink"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/shares/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Shares"
+                ],
+                "summary": "撤销分享链接",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分享 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "撤销成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "分享不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync": {
+            "get": {
+                "description": "返回 sync_token 之后新建/修改的任务和已删除任务的墓碑；不传 token 时返回全部任务的快照。\nhas_more 为 true 时应立即用新的 token 继续拉取",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "增量同步：拉取变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次同步得到的 token",
+                        "name": "sync_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "变更",
+                        "schema": {
+                            "$ref": "#/definitions/service.SyncPage"
+                        }
+                    },
+                    "400": {
+                        "description": "token 无效，需要全量同步",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "新建任务使用客户端生成的 client_id；每条变更单独返回 applied / merged / conflict / rejected 结果",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "增量同步：提交离线修改并拉取变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "离线修改",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SyncRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "处理结果和变更",
+                        "schema": {
+                            "$ref": "#/definitions/service.SyncPage"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos": {
+            "get": {
+                "description": "获取当前用户的所有任务，支持分页、项目过滤和排序；未指定排序时使用用户偏好",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Todos"
+                ],
+                "summary": "获取所有任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码，默认为 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量，默认为 10，最多 100",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "按项目过滤",
+                        "name": "project",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序方式：created_asc, created_desc, due_asc, due_desc, title_asc",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "按截止时间过滤（按用户时区计算）：today, week, overdue",
+                        "name": "due",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID，不传则返回个人任务",
+                        "name": "workspace_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "按负责人过滤：me 或用户 ID",
+                        "name": "assignee",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "为 true 时只返回没有负责人的任务",
+                        "name": "unassigned",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回任务列表和分页信息",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建一个新的任务，需要传递 title 字段，user_id 会自动从 Token 获取；未指定 project 时使用用户的默认项目；指定 workspace_id 时需要该工作区的 editor 以上角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Todos"
+                ],
+                "summary": "创建一个新任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "任务信息",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Todo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Todo"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有该工作区的编辑权限",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}": {
+            "get": {
+                "description": "根据任务 ID 获取单个任务详情",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Todos"
+                ],
+                "summary": "获取单个任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Todo"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "更新指定 ID 的任务信息（不能修改创建者、所属工作区和创建时间）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Todos"
+                ],
+                "summary": "更新任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新的任务信息",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Todo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Todo"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有编辑权限",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "删除指定 ID 的任务",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Todos"
+                ],
+                "summary": "删除任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有删除权限",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/assignees": {
+            "post": {
+                "description": "个人任务只能分配给自己，工作区任务可以分配给任意成员；需要任务的编辑权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Todos"
+                ],
+                "summary": "分配负责人",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "负责人",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AssignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "分配后的任务",
+                        "schema": {
+                            "$ref": "#/definitions/models.Todo"
+                        }
+                    },
+                    "400": {
+                        "description": "负责人不合法",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有编辑权限",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/assignees/{userID}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Todos"
+                ],
+                "summary": "取消负责人",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "负责人用户 ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "取消后的任务",
+                        "schema": {
+                            "$ref": "#/definitions/models.Todo"
+                        }
+                    },
+                    "403": {
+                        "description": "没有编辑权限",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/attachments": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "获取任务的附件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "附件列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Attachment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "需要任务的编辑权限；文件类型按内容识别，大小和类型受 attachments.* 配置限制",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "上传附件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "附件文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上传成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Attachment"
+                        }
+                    },
+                    "400": {
+                        "description": "缺少文件",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有编辑权限",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "文件过大",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "不支持的文件类型",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/attachments/{attachmentID}": {
+            "get": {
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "下载附件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "附件 ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "附件内容",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "附件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "需要任务的编辑权限，存储中的文件会一起删除",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "删除附件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "附件 ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有编辑权限",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "附件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/comments": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comments"
+                ],
+                "summary": "获取任务的评论",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "评论列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Comment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "能查看任务的用户都可以评论，正文中的 @username 会被解析为对应用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comments"
+                ],
+                "summary": "发表评论",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "评论内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "发表成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/comments/{commentID}": {
+            "put": {
+                "description": "只有作者本人可以修改",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comments"
+                ],
+                "summary": "修改评论",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "评论 ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "评论内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
+                    },
+                    "403": {
+                        "description": "不是作者",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "评论不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "作者本人、个人任务的所有者或工作区 owner 可以删除",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comments"
+                ],
+                "summary": "删除评论",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "评论 ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有权限",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "评论不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "列出我的 Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook 列表（不含密钥）",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "任务变更时向 URL 发送 HMAC-SHA256 签名的 POST 请求；返回的 secret 只显示这一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "创建 Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook 信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，包含签名密钥",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "获取 Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook（不含密钥）",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook 不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "修改 Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook 信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook 不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "同时删除投递记录，未发送的投递不再发送",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "删除 Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook 不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "最近 100 次投递，包括状态、尝试次数、响应状态码和错误",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Webhook 投递记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "投递记录",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook 不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/test": {
+            "post": {
+                "description": "立即发送一条 webhook.test 事件并返回投递结果，失败时会按正常规则重试",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "发送测试事件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "投递结果",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook 不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces": {
+            "get": {
+                "description": "返回当前用户加入的所有工作区及其角色",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "列出我加入的工作区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "工作区列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Workspace"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建一个工作区，创建者成为拥有者",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "创建工作区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "工作区信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/models.Workspace"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}": {
+            "get": {
+                "description": "返回工作区信息和成员列表，成员才能查看",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "获取工作区详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "工作区和成员",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "不是该工作区成员",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "只有拥有者可以修改",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "修改工作区名称",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "工作区信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "删除工作区及其中所有任务，只有拥有者可以删除",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "删除工作区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/invites": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "列出工作区的邀请",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "邀请列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WorkspaceInvite"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "指定 username 时邀请该用户（对方在\"我的邀请\"中接受）；不指定时生成可多次使用的邀请链接",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "邀请成员",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "邀请信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.InviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "邀请信息，token 用于接受邀请",
+                        "schema": {
+                            "$ref": "#/definitions/models.WorkspaceInvite"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/invites/{inviteID}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "撤销邀请",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "邀请 ID",
+                        "name": "inviteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "撤销成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/members": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "列出工作区成员",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成员列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WorkspaceMember"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "不是该工作区成员",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/members/{userID}": {
+            "put": {
+                "description": "拥有者修改成员角色；设为 owner 表示转让工作区，原拥有者变为 editor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "修改成员角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "成员用户 ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "角色",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MemberRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "成员不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "拥有者可以移除任何成员；成员可以移除自己（退出工作区）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "移除成员或退出工作区",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "工作区 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "成员用户 ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "移除成功",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "成员不存在",
+                        "schema": {
+                            "$ref": "#/definitions/common.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "common.Response": {
             "description": "API 统一响应格式",
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "业务状态码（200 表示成功，其他表示失败）",
+                "code": {
+                    "description": "业务状态码（200 表示成功，其他表示失败）",
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "description": "响应数据（可以是任意类型）"
+                },
+                "msg": {
+                    "description": "提示消息",
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "controllers.AssignRequest": {
+            "description": "通过用户 ID 或用户名指定负责人",
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "description": "负责人用户 ID",
+                    "type": "string",
+                    "example": "01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"
+                },
+                "username": {
+                    "description": "负责人用户名（未提供 user_id 时使用）",
+                    "type": "string",
+                    "example": "jane_doe"
+                }
+            }
+        },
+        "controllers.AuthRequest": {
+            "description": "用户登录和注册请求结构体",
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "description": "密码",
+                    "type": "string",
+                    "example": "password123"
+                },
+                "username": {
+                    "description": "用户名",
+                    "type": "string",
+                    "example": "john_doe"
+                }
+            }
+        },
+        "controllers.ChangePasswordRequest": {
+            "description": "修改密码需要提供旧密码",
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "description": "新密码",
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "newpassword456"
+                },
+                "old_password": {
+                    "description": "旧密码",
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "controllers.CommentRequest": {
+            "description": "评论正文，支持 Markdown 和 @username 提及",
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "description": "评论正文（Markdown）",
+                    "type": "string",
+                    "example": "已经提交了，@jane_doe 帮忙看一下"
+                }
+            }
+        },
+        "controllers.CreateShareRequest": {
+            "description": "指定 todo_id 分享单个任务，或指定 project（可选 workspace_id）分享一个项目",
+            "type": "object",
+            "properties": {
+                "expires_in_hours": {
+                    "description": "有效期（小时），0 表示不过期",
+                    "type": "integer",
+                    "example": 168
+                },
+                "password": {
+                    "description": "访问密码，可选",
+                    "type": "string",
+                    "example": "s3cret"
+                },
+                "permission": {
+                    "description": "权限：read（默认）或 comment",
+                    "type": "string",
+                    "example": "read"
+                },
+                "project": {
+                    "description": "分享的项目名称",
+                    "type": "string",
+                    "example": "工作"
+                },
+                "todo_id": {
+                    "description": "分享的任务 ID",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                },
+                "workspace_id": {
+                    "description": "项目所在工作区 ID，不填表示个人任务中的项目",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "controllers.DeleteAccountRequest": {
+            "description": "注销账号前需要再次输入密码确认",
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "description": "当前密码",
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "controllers.InviteRequest": {
+            "description": "指定 username 邀请某个用户，不指定则生成邀请链接",
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "expires_in_hours": {
+                    "description": "有效期（小时），0 表示不过期",
+                    "type": "integer",
+                    "example": 72
+                },
+                "role": {
+                    "description": "加入后的角色：editor / viewer",
+                    "type": "string",
+                    "example": "editor"
+                },
+                "username": {
+                    "description": "被邀请的用户名，可选",
+                    "type": "string",
+                    "example": "jane_doe"
+                }
+            }
+        },
+        "controllers.LoginRequest": {
+            "description": "用户登录请求结构体",
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "cookie": {
+                    "description": "为 true 时 Token 保存在 HttpOnly Cookie 中，响应只返回 CSRF Token（需要开启 session.cookie.enabled）",
+                    "type": "boolean",
+                    "example": false
+                },
+                "password": {
+                    "description": "密码",
+                    "type": "string",
+                    "example": "password123"
+                },
+                "username": {
+                    "description": "用户名",
+                    "type": "string",
+                    "example": "john_doe"
+                }
+            }
+        },
+        "controllers.MemberRoleRequest": {
+            "description": "成员角色，设为 owner 表示转让工作区",
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "description": "角色：owner / editor / viewer",
+                    "type": "string",
+                    "example": "editor"
+                }
+            }
+        },
+        "controllers.SetRoleRequest": {
+            "description": "修改用户角色",
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "description": "角色：user 或 admin",
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "controllers.SharedCommentRequest": {
+            "description": "通过可评论的分享链接发表评论",
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "description": "评论正文（Markdown）",
+                    "type": "string",
+                    "example": "这里的文案需要再改一下"
+                },
+                "name": {
+                    "description": "访客名称，不填显示为\"访客\"",
+                    "type": "string",
+                    "example": "客户张三"
+                }
+            }
+        },
+        "controllers.SyncRequest": {
+            "description": "先按顺序应用 changes，再返回 sync_token 之后的所有变更（包括本次提交产生的）",
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "客户端变更",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SyncChange"
+                    }
+                },
+                "conflict": {
+                    "description": "冲突策略：lww（默认，最后写入获胜）或 field（字段级合并）",
+                    "type": "string",
+                    "example": "lww"
+                },
+                "sync_token": {
+                    "description": "上次同步得到的 token，为空表示首次同步",
+                    "type": "string",
+                    "example": "djE6MTI"
+                }
+            }
+        },
+        "controllers.UserInfo": {
+            "description": "当前登录用户的基本信息与个人资料",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "注册时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "用户 ID",
+                    "type": "string",
+                    "example": "01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"
+                },
+                "must_reset_password": {
+                    "description": "是否需要先修改密码",
+                    "type": "boolean",
+                    "example": false
+                },
+                "profile": {
+                    "description": "个人资料与偏好",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Profile"
+                        }
+                    ]
+                },
+                "role": {
+                    "description": "角色：user 或 admin",
+                    "type": "string",
+                    "example": "user"
+                },
+                "username": {
+                    "description": "用户名",
+                    "type": "string",
+                    "example": "john_doe"
+                }
+            }
+        },
+        "controllers.WebhookRequest": {
+            "description": "事件类型可选 todo.created、todo.updated、todo.deleted，为空表示全部",
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "description": "是否启用，默认启用",
+                    "type": "boolean",
+                    "example": true
+                },
+                "events": {
+                    "description": "订阅的事件类型",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "todo.created",
+                        "todo.updated"
+                    ]
+                },
+                "url": {
+                    "description": "接收事件的地址",
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/todo"
+                }
+            }
+        },
+        "controllers.WorkspaceRequest": {
+            "description": "工作区名称",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "工作区名称",
+                    "type": "string",
+                    "example": "产品研发组"
+                }
+            }
+        },
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Attachment": {
+            "description": "任务附件",
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "description": "MIME 类型（根据文件内容识别）",
+                    "type": "string",
+                    "example": "image/png"
+                },
+                "created_at": {
+                    "description": "上传时间",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "原始文件名",
+                    "type": "string",
+                    "example": "screenshot.png"
+                },
+                "id": {
+                    "description": "附件 ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "size": {
+                    "description": "文件大小（字节）",
+                    "type": "integer",
+                    "example": 20480
+                },
+                "todo_id": {
+                    "description": "任务 ID（仅导出时返回）",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                }
+            }
+        },
+        "models.Comment": {
+            "description": "任务评论",
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "description": "作者用户 ID（仅查询时返回），访客评论为空",
+                    "type": "string",
+                    "example": "01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"
+                },
+                "author_name": {
+                    "description": "作者名称（用户名或访客填写的名字）",
+                    "type": "string",
+                    "example": "john_doe"
+                },
+                "body": {
+                    "description": "评论正文（Markdown）",
+                    "type": "string",
+                    "example": "已经提交了，@jane_doe 帮忙看一下"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "评论 ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "mentions": {
+                    "description": "正文中 @ 到的用户",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CommentMention"
+                    }
+                },
+                "todo_id": {
+                    "description": "任务 ID（仅导出时返回）",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CommentMention": {
+            "description": "评论中提到的用户",
+            "type": "object",
+            "properties": {
+                "username": {
+                    "description": "被提到的用户名",
+                    "type": "string",
+                    "example": "jane_doe"
+                }
+            }
+        },
+        "models.Profile": {
+            "description": "用户个人资料与偏好设置",
+            "type": "object",
+            "properties": {
+                "default_project": {
+                    "description": "默认项目，创建任务时未指定项目则使用它",
+                    "type": "string",
+                    "example": "工作"
+                },
+                "default_sort": {
+                    "description": "任务列表的默认排序方式",
+                    "type": "string",
+                    "example": "created_asc"
+                },
+                "display_name": {
+                    "description": "显示名称",
+                    "type": "string",
+                    "example": "John"
+                },
+                "locale": {
+                    "description": "语言区域",
+                    "type": "string",
+                    "example": "zh-CN"
+                },
+                "time_zone": {
+                    "description": "时区（IANA 名称），解析和展示日期时使用",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                },
+                "week_start": {
+                    "description": "每周起始日：0 表示周日，1 表示周一，以此类推",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.ShareLink": {
+            "description": "分享链接",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "过期时间，为空表示不过期",
+                    "type": "string"
+                },
+                "has_password": {
+                    "description": "是否设置了访问密码",
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "description": "分享 ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "permission": {
+                    "description": "权限：read 只读，comment 可评论",
+                    "type": "string",
+                    "example": "read"
+                },
+                "project": {
+                    "description": "分享的项目名称",
+                    "type": "string",
+                    "example": "工作"
+                },
+                "revoked_at": {
+                    "description": "撤销时间，撤销后链接立即失效",
+                    "type": "string"
+                },
+                "todo_id": {
+                    "description": "分享的任务 ID（仅查询时返回）",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                },
+                "token": {
+                    "description": "分享口令，用于拼接公开链接",
+                    "type": "string",
+                    "example": "9c1e..."
+                },
+                "workspace_id": {
+                    "description": "分享项目所在的工作区 ID",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.Todo": {
+            "description": "任务信息结构体",
+            "type": "object",
+            "properties": {
+                "assignees": {
+                    "description": "负责人列表（通过分配接口维护，创建和更新任务时忽略）",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TodoAssignee"
+                    }
+                },
+                "client_id": {
+                    "description": "客户端生成的 ID（离线创建时使用），创建后不可修改，同一个用户内唯一",
+                    "type": "string",
+                    "example": "3f1c2a9e-8d4b-4c1e-9a57-0b6f3f2d1e7c"
+                },
+                "comment_count": {
+                    "description": "评论数量（仅在任务列表中返回）",
+                    "type": "integer",
+                    "example": 3
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "任务描述",
+                    "type": "string",
+                    "example": "编写详细的 README 和 API 文档"
+                },
+                "due_date": {
+                    "description": "截止时间（RFC3339），返回时会转换到用户时区",
+                    "type": "string",
+                    "example": "2026-01-02T18:00:00+08:00"
+                },
+                "id": {
+                    "description": "任务 ID（UUIDv7），对外只暴露它，自增主键仅在内部使用",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                },
+                "project": {
+                    "description": "所属项目，未填写时使用用户的默认项目",
+                    "type": "string",
+                    "example": "工作"
+                },
+                "status": {
+                    "description": "完成状态：true 完成, false 未完成",
+                    "type": "boolean",
+                    "example": false
+                },
+                "title": {
+                    "description": "任务标题",
+                    "type": "string",
+                    "example": "完成项目文档"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "创建者用户 ID（仅查询时返回）",
+                    "type": "string",
+                    "example": "01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"
+                },
+                "workspace_id": {
+                    "description": "所属工作区 ID，为空表示个人任务",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.TodoAssignee": {
+            "description": "任务负责人",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "分配时间",
+                    "type": "string"
+                },
+                "todo_id": {
+                    "description": "任务 ID（仅导出时返回）",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                },
+                "user_id": {
+                    "description": "负责人用户 ID（仅查询时返回）",
+                    "type": "string",
+                    "example": "01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"
+                },
+                "username": {
+                    "description": "负责人用户名（仅查询时返回）",
+                    "type": "string",
+                    "example": "jane_doe"
+                }
+            }
+        },
+        "models.Tombstone": {
+            "description": "已删除的任务",
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "任务的客户端 ID",
+                    "type": "string",
+                    "example": "3f1c2a9e-8d4b-4c1e-9a57-0b6f3f2d1e7c"
+                },
+                "deleted_at": {
+                    "description": "删除时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "任务 ID",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                }
+            }
+        },
+        "models.Webhook": {
+            "description": "Webhook 订阅",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "是否启用",
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "订阅的事件类型，为空表示全部",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "todo.created",
+                        "todo.updated"
+                    ]
+                },
+                "id": {
+                    "description": "Webhook ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "secret": {
+                    "description": "签名密钥，只在创建时返回",
+                    "type": "string",
+                    "example": "whsec_..."
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "接收事件的地址",
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/todo"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "description": "Webhook 投递记录",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "已尝试次数",
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "description": "投递成功时间",
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "事件 ID",
+                    "type": "integer",
+                    "example": 1760000000000001
+                },
+                "event_type": {
+                    "description": "事件类型",
+                    "type": "string",
+                    "example": "todo.created"
+                },
+                "id": {
+                    "description": "投递 ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_error": {
+                    "description": "最近一次错误",
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "description": "下次尝试时间",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "请求体",
+                    "type": "string"
+                },
+                "response_status": {
+                    "description": "最近一次响应状态码",
                     "type": "integer",
                     "example": 200
                 },
-                "data": {
-                    "description": "响应数据（可以是任意类型）"
+                "status": {
+                    "description": "状态：pending、succeeded、failed",
+                    "type": "string",
+                    "example": "succeeded"
                 },
-                "msg": {
-                    "description": "提示消息",
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "description": "Webhook ID",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.Workspace": {
+            "description": "工作区信息",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "工作区 ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "工作区名称",
                     "type": "string",
-                    "example": "success"
+                    "example": "产品研发组"
+                },
+                "role": {
+                    "description": "当前用户在该工作区的角色（仅查询时返回）",
+                    "type": "string",
+                    "example": "owner"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
                 }
             }
         },
-        "controllers.AuthRequest": {
-            "description": "用户登录和注册请求结构体",
+        "models.WorkspaceInvite": {
+            "description": "工作区邀请",
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
-                    "description": "密码",
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "过期时间，为空表示不过期",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "邀请 ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "invitee": {
+                    "description": "被邀请的用户名（仅查询时返回），链接邀请为空",
                     "type": "string",
-                    "example": "password123"
+                    "example": "jane_doe"
+                },
+                "role": {
+                    "description": "加入后的角色：editor / viewer",
+                    "type": "string",
+                    "example": "editor"
+                },
+                "token": {
+                    "description": "邀请口令，用于拼接邀请链接",
+                    "type": "string",
+                    "example": "3f2a..."
+                },
+                "workspace_id": {
+                    "description": "工作区 ID",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.WorkspaceMember": {
+            "description": "工作区成员及其角色",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "加入时间",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "角色：owner / editor / viewer",
+                    "type": "string",
+                    "example": "editor"
+                },
+                "user_id": {
+                    "description": "用户 ID（仅查询时返回）",
+                    "type": "string",
+                    "example": "01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"
                 },
                 "username": {
-                    "description": "用户名",
+                    "description": "用户名（仅查询时返回）",
                     "type": "string",
                     "example": "john_doe"
+                },
+                "workspace_id": {
+                    "description": "工作区 ID",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "models.Todo": {
-            "description": "任务信息结构体",
+        "service.SharedContent": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "过期时间",
+                    "type": "string"
+                },
+                "permission": {
+                    "description": "权限：read / comment",
+                    "type": "string",
+                    "example": "read"
+                },
+                "project": {
+                    "description": "分享的项目名称（分享单个任务时为空）",
+                    "type": "string",
+                    "example": "工作"
+                },
+                "todos": {
+                    "description": "分享的任务列表，分享单个任务时只有一条",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SharedTodo"
+                    }
+                }
+            }
+        },
+        "service.SharedTodo": {
             "type": "object",
             "properties": {
                 "description": {
-                    "description": "任务描述",
                     "type": "string",
                     "example": "编写详细的 README 和 API 文档"
                 },
+                "due_date": {
+                    "type": "string"
+                },
                 "id": {
-                    "description": "任务 ID",
-                    "type": "integer",
-                    "example": 1
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                },
+                "project": {
+                    "type": "string",
+                    "example": "工作"
                 },
                 "status": {
-                    "description": "完成状态：true 完成, false 未完成",
                     "type": "boolean",
                     "example": false
                 },
                 "title": {
-                    "description": "任务标题",
                     "type": "string",
                     "example": "完成项目文档"
                 },
-                "user_id": {
-                    "description": "所属用户 ID",
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.SyncChange": {
+            "description": "客户端变更，fields 只需要包含修改过的字段",
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "字段级合并时，修改前的字段值",
+                    "type": "object"
+                },
+                "client_id": {
+                    "description": "客户端生成的任务 ID，新建任务时必填",
+                    "type": "string",
+                    "example": "3f1c2a9e-8d4b-4c1e-9a57-0b6f3f2d1e7c"
+                },
+                "fields": {
+                    "description": "修改后的字段值（title、description、status、project、due_date）",
+                    "type": "object"
+                },
+                "id": {
+                    "description": "服务端任务 ID，已同步过的任务可以直接使用",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                },
+                "op": {
+                    "description": "操作：upsert 或 delete",
+                    "type": "string",
+                    "example": "upsert"
+                },
+                "updated_at": {
+                    "description": "客户端修改时间，删除和最后写入获胜时必填",
+                    "type": "string"
+                },
+                "workspace_id": {
+                    "description": "新建任务所属的工作区",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "service.SyncPage": {
+            "description": "同步结果",
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "description": "已删除的任务",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tombstone"
+                    }
+                },
+                "has_more": {
+                    "description": "还有更多变更时为 true，客户端应该立即用新 token 再次同步",
+                    "type": "boolean",
+                    "example": false
+                },
+                "results": {
+                    "description": "客户端变更的处理结果（仅提交时返回）",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.SyncResult"
+                    }
+                },
+                "sync_token": {
+                    "description": "下次同步使用的 token",
+                    "type": "string",
+                    "example": "djE6MTI"
+                },
+                "todos": {
+                    "description": "新建或修改过的任务",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Todo"
+                    }
+                }
+            }
+        },
+        "service.SyncResult": {
+            "description": "变更处理结果",
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "客户端 ID",
+                    "type": "string",
+                    "example": "3f1c2a9e-8d4b-4c1e-9a57-0b6f3f2d1e7c"
+                },
+                "conflicts": {
+                    "description": "发生冲突、保留了服务端值的字段",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "description": "拒绝原因",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "服务端任务 ID",
+                    "type": "string",
+                    "example": "01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"
+                },
+                "status": {
+                    "description": "applied、merged（部分字段冲突）、conflict（服务端获胜）或 rejected",
+                    "type": "string",
+                    "example": "applied"
+                },
+                "todo": {
+                    "description": "处理后服务端的任务，删除后为空",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Todo"
+                        }
+                    ]
+                }
+            }
+        },
+        "service.UsageStats": {
+            "type": "object",
+            "properties": {
+                "admins": {
                     "type": "integer",
                     "example": 1
+                },
+                "completed_todos": {
+                    "type": "integer",
+                    "example": 800
+                },
+                "disabled_users": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "new_todos_7d": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "new_users_7d": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "todos": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 100
                 }
             }
         }
@@ -0,0 +1,202 @@
+// Package events 实现按用户分发的实时事件中心，供 SSE 和 WebSocket 推送使用
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// 任务事件类型
+const (
+	TodoCreated = "todo.created"
+	TodoUpdated = "todo.updated"
+	TodoDeleted = "todo.deleted"
+	// Reset 表示重放缓冲区已经无法补齐客户端错过的事件，客户端需要重新拉取全量数据
+	Reset = "reset"
+)
+
+// Event 推送给客户端的一条事件
+type Event struct {
+	ID        uint64          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	recipients []uint
+}
+
+// Subscription 一个客户端连接的订阅
+type Subscription struct {
+	C      <-chan Event
+	hub    *Hub
+	userID uint
+	ch     chan Event
+	once   sync.Once
+}
+
+// Close 取消订阅
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Hub 事件中心：把事件分发给相关用户的所有连接，并保留最近的事件用于断线重放
+type Hub struct {
+	mu      sync.Mutex
+	nextID  uint64
+	buffer  []Event // 环形缓冲区
+	start   int
+	size    int
+	subs    map[uint]map[*Subscription]struct{}
+	backlog int
+	closed  bool
+}
+
+// NewHub 创建事件中心，replaySize 为重放缓冲区能保留的事件数量
+func NewHub(replaySize int) *Hub {
+	if replaySize < 1 {
+		replaySize = 1
+	}
+	return &Hub{
+		// 事件 ID 以启动时间（微秒）为起点，重启后也不会与之前的 ID 重复，
+		// 同时小于 2^53，JavaScript 客户端可以精确表示
+		nextID:  uint64(time.Now().UnixMicro()),
+		buffer:  make([]Event, replaySize),
+		subs:    make(map[uint]map[*Subscription]struct{}),
+		backlog: 64,
+	}
+}
+
+// Publish 把事件发给指定的用户，data 会被序列化为 JSON
+func (h *Hub) Publish(eventType string, data interface{}, userIDs ...uint) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	e := Event{ID: h.nextID, Type: eventType, Data: raw, CreatedAt: time.Now(), recipients: userIDs}
+	h.append(e)
+
+	for _, uid := range userIDs {
+		for sub := range h.subs[uid] {
+			select {
+			case sub.ch <- e:
+			default:
+				// 消费太慢的连接直接断开，客户端重连后可以用 Last-Event-ID 补齐
+				h.drop(sub)
+			}
+		}
+	}
+	return e, nil
+}
+
+// Subscribe 订阅用户的事件。lastEventID 不为 0 时先返回错过的事件；
+// 如果错过的事件已经不在缓冲区中，返回一条 Reset 事件
+func (h *Hub) Subscribe(userID uint, lastEventID uint64) (*Subscription, []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan Event, h.backlog)
+	sub := &Subscription{C: ch, ch: ch, hub: h, userID: userID}
+	if h.closed {
+		sub.once.Do(func() { close(ch) })
+		return sub, nil
+	}
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	if lastEventID == 0 {
+		return sub, nil
+	}
+	return sub, h.replay(userID, lastEventID)
+}
+
+// replay 返回 lastEventID 之后发给该用户的事件，调用方需持有锁
+func (h *Hub) replay(userID uint, lastEventID uint64) []Event {
+	if lastEventID == h.nextID {
+		return nil
+	}
+	oldest := h.nextID + 1 - uint64(h.size)
+	if lastEventID > h.nextID || lastEventID+1 < oldest {
+		return []Event{{ID: h.nextID, Type: Reset, CreatedAt: time.Now()}}
+	}
+	var missed []Event
+	for i := 0; i < h.size; i++ {
+		e := h.buffer[(h.start+i)%len(h.buffer)]
+		if e.ID > lastEventID && e.sentTo(userID) {
+			missed = append(missed, e)
+		}
+	}
+	return missed
+}
+
+func (h *Hub) append(e Event) {
+	if h.size < len(h.buffer) {
+		h.buffer[(h.start+h.size)%len(h.buffer)] = e
+		h.size++
+		return
+	}
+	h.buffer[h.start] = e
+	h.start = (h.start + 1) % len(h.buffer)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(sub)
+}
+
+// drop 移除订阅并关闭通道，调用方需持有锁
+func (h *Hub) drop(sub *Subscription) {
+	sub.once.Do(func() {
+		delete(h.subs[sub.userID], sub)
+		if len(h.subs[sub.userID]) == 0 {
+			delete(h.subs, sub.userID)
+		}
+		close(sub.ch)
+	})
+}
+
+// Close 关闭所有订阅，之后的订阅会立即被关闭，用于服务退出时结束事件流长连接
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.drop(sub)
+		}
+	}
+}
+
+// Closed 是否已经调用过 Close
+func (h *Hub) Closed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+// Connections 当前的连接数
+func (h *Hub) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, subs := range h.subs {
+		n += len(subs)
+	}
+	return n
+}
+
+func (e Event) sentTo(userID uint) bool {
+	for _, uid := range e.recipients {
+		if uid == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package events
+
+import "testing"
+
+// TestFanOut 测试事件只发给相关用户的所有连接
+func TestFanOut(t *testing.T) {
+	h := NewHub(10)
+	a1, _ := h.Subscribe(1, 0)
+	a2, _ := h.Subscribe(1, 0)
+	b, _ := h.Subscribe(2, 0)
+	defer a1.Close()
+	defer a2.Close()
+	defer b.Close()
+
+	e, err := h.Publish(TodoCreated, map[string]int{"id": 7}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []*Subscription{a1, a2} {
+		if got := <-sub.C; got.ID != e.ID || string(got.Data) != `{"id":7}` {
+			t.Errorf("收到的事件不正确: %+v", got)
+		}
+	}
+	select {
+	case got := <-b.C:
+		t.Errorf("用户 2 不应该收到事件: %+v", got)
+	default:
+	}
+	if h.Connections() != 3 {
+		t.Errorf("期望 3 个连接，但得到了 %d", h.Connections())
+	}
+}
+
+// TestReplay 测试断线重连时补发错过的事件，超出缓冲区时返回 reset
+func TestReplay(t *testing.T) {
+	h := NewHub(3)
+	first, _ := h.Publish(TodoCreated, 1, 1)
+	h.Publish(TodoUpdated, 2, 2)
+	h.Publish(TodoUpdated, 3, 1)
+
+	sub, missed := h.Subscribe(1, first.ID)
+	sub.Close()
+	if len(missed) != 1 || string(missed[0].Data) != "3" {
+		t.Errorf("期望补发 1 条事件，但得到了 %+v", missed)
+	}
+
+	// 再发两条后 first 已经被挤出缓冲区
+	h.Publish(TodoDeleted, 4, 1)
+	h.Publish(TodoDeleted, 5, 1)
+	sub, missed = h.Subscribe(1, first.ID-1)
+	sub.Close()
+	if len(missed) != 1 || missed[0].Type != Reset {
+		t.Errorf("期望返回 reset 事件，但得到了 %+v", missed)
+	}
+
+	// 未知的（比如重启前的）事件 ID 同样需要 reset
+	sub, missed = h.Subscribe(1, first.ID+100)
+	sub.Close()
+	if len(missed) != 1 || missed[0].Type != Reset {
+		t.Errorf("期望未知 ID 返回 reset 事件，但得到了 %+v", missed)
+	}
+}
+
+// TestSlowSubscriber 测试消费太慢的连接会被断开而不阻塞发布
+func TestSlowSubscriber(t *testing.T) {
+	h := NewHub(10)
+	sub, _ := h.Subscribe(1, 0)
+	for i := 0; i < h.backlog+1; i++ {
+		h.Publish(TodoUpdated, i, 1)
+	}
+	n := 0
+	for range sub.C {
+		n++
+	}
+	if n != h.backlog || h.Connections() != 0 {
+		t.Errorf("期望缓冲 %d 条后断开，实际收到 %d 条，剩余连接 %d", h.backlog, n, h.Connections())
+	}
+	sub.Close() // 重复关闭是安全的
+}
+
+// TestClose 测试关闭后所有订阅结束，新的订阅立即结束
+func TestClose(t *testing.T) {
+	h := NewHub(10)
+	sub, _ := h.Subscribe(1, 0)
+	h.Close()
+	if _, ok := <-sub.C; ok || h.Connections() != 0 {
+		t.Errorf("关闭后订阅应该结束，剩余连接 %d", h.Connections())
+	}
+	late, _ := h.Subscribe(2, 0)
+	if _, ok := <-late.C; ok || h.Connections() != 0 {
+		t.Error("关闭后的订阅应该立即结束")
+	}
+	late.Close()
+	sub.Close()
+}
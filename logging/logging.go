@@ -0,0 +1,152 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Redacted 替换敏感字段的值
+const Redacted = "[REDACTED]"
+
+// 日志格式
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New 创建日志，format 为 json 或 text。
+// 每一行都会带上 ctx 中的 request_id 和 user_id，敏感字段（见 Sensitive）的值被替换为 [REDACTED]
+func New(w io.Writer, level slog.Leveler, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+	var h slog.Handler
+	if format == FormatText {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{h})
+}
+
+// ParseLevel 解析 debug、info、warn、error，无法识别时返回 info
+func ParseLevel(s string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// sensitiveKeys 值需要隐藏的字段，比较时不区分大小写
+var sensitiveKeys = []string{"authorization", "cookie", "password", "secret", "token", "api_key", "apikey"}
+
+// Sensitive 字段名（日志属性、请求头、查询参数、路由参数）是否包含认证信息或密码
+func Sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && Sensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// RedactQuery 隐藏查询参数中的敏感值，如 ?access_token=...
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Redacted
+	}
+	for key := range query {
+		if Sensitive(key) {
+			query[key] = []string{Redacted}
+		}
+	}
+	return query.Encode()
+}
+
+// fields 一个请求的日志字段。保存在 ctx 中的是指针：认证中间件在请求中途设置用户 ID，
+// 之后从同一个请求派生的 ctx（包括 Timeout 重新派生的）都能看到
+type fields struct {
+	mu        sync.Mutex
+	requestID string
+	userID    string
+}
+
+type ctxKey struct{}
+
+// WithRequestID 返回带有请求 ID 的 ctx，之后用这个 ctx 写的日志都会带上 request_id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &fields{requestID: requestID})
+}
+
+// SetUserID 记录发起请求的用户（公开 ID），ctx 必须来自 WithRequestID
+func SetUserID(ctx context.Context, userID string) {
+	if f, ok := ctx.Value(ctxKey{}).(*fields); ok {
+		f.mu.Lock()
+		f.userID = userID
+		f.mu.Unlock()
+	}
+}
+
+// RequestID ctx 中的请求 ID，没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	requestID, _ := get(ctx)
+	return requestID
+}
+
+func get(ctx context.Context) (requestID, userID string) {
+	if ctx == nil {
+		return "", ""
+	}
+	f, ok := ctx.Value(ctxKey{}).(*fields)
+	if !ok {
+		return "", ""
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requestID, f.userID
+}
+
+// contextHandler 把 ctx 中的请求 ID、用户 ID 和链路 ID 加到每条日志上
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	requestID, userID := get(ctx)
+	if requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+	if userID != "" {
+		r.AddAttrs(slog.String("user_id", userID))
+	}
+	// 开启链路追踪时，可以用 trace_id 在追踪后端找到对应的请求
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
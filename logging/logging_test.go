@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestContextFields 请求 ID 和中途设置的用户 ID 出现在从同一个请求派生的每条日志上
+func TestContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, slog.LevelInfo, FormatJSON)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	// 派生的 ctx（如 Timeout 中间件创建的）在设置用户 ID 之前就已经存在
+	derived, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	SetUserID(ctx, "user-1")
+	log.InfoContext(derived, "查询任务", slog.Group("trace", "sql", "SELECT 1"))
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("日志不是 JSON: %s", buf.String())
+	}
+	if line["request_id"] != "req-1" || line["user_id"] != "user-1" || line["msg"] != "查询任务" {
+		t.Errorf("期望带有 request_id 和 user_id，但得到了 %s", buf.String())
+	}
+	if RequestID(derived) != "req-1" {
+		t.Errorf("期望 req-1，但得到了 %q", RequestID(derived))
+	}
+
+	// 没有请求 ID 的 ctx 不加字段
+	buf.Reset()
+	log.Info("启动")
+	if strings.Contains(buf.String(), "request_id") {
+		t.Errorf("没有请求 ID 时不应该输出 request_id: %s", buf.String())
+	}
+
+	// 低于配置级别的日志不输出
+	buf.Reset()
+	log.DebugContext(ctx, "调试")
+	if buf.Len() != 0 {
+		t.Errorf("info 级别不应该输出 debug 日志: %s", buf.String())
+	}
+}
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, slog.LevelDebug, FormatText)
+	log.Info("登录", "username", "alice", "password", "hunter2",
+		slog.Group("headers", "Authorization", "Bearer abc", "Cookie", "session=xyz", "Accept", "application/json"))
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "Bearer abc", "session=xyz"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("日志中不应该出现 %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "username=alice") || !strings.Contains(out, "headers.Accept=application/json") {
+		t.Errorf("普通字段不应该被隐藏: %s", out)
+	}
+
+	got := RedactQuery("page=2&access_token=abc")
+	if strings.Contains(got, "abc") || !strings.Contains(got, "page=2") {
+		t.Errorf("期望隐藏 access_token，但得到了 %s", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"debug": slog.LevelDebug, "WARN": slog.LevelWarn, "error": slog.LevelError, "verbose": slog.LevelInfo, "": slog.LevelInfo} {
+		if got := ParseLevel(in); got != want {
+			t.Errorf("ParseLevel(%q) 期望 %v，但得到了 %v", in, want, got)
+		}
+	}
+}
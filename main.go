@@ -2,7 +2,11 @@ package main
 
 import (
 	"go-todo/config"
+	"go-todo/repository"
 	"go-todo/routes"
+	"go-todo/service"
+	"log/slog"
+	"os"
 
 	"github.com/spf13/viper"
 
@@ -19,12 +23,56 @@ import (
 // @BasePath        /api/v1
 
 func main() {
-	config.InitConfig()      // 先加载配置
+	config.InitConfig() // 先加载配置
+	// 链路追踪在连接数据库之前设置，SQL 的 span 才能导出
+	if err := config.InitTracing(version); err != nil {
+		slog.Error("链路追踪初始化失败", "error", err)
+		os.Exit(1)
+	}
 	config.ConnectDatabase() // 再连接数据库
 
-	r := routes.SetupRouter()
+	// migrate up/down/status 子命令：只管理表结构，不启动服务
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			slog.Error("迁移失败", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+	// 表结构不是最新的时候拒绝启动（database.migrate=auto 时先自动执行迁移）
+	if err := config.EnsureMigrated(); err != nil {
+		slog.Error("启动失败", "error", err)
+		os.Exit(1)
+	}
+
+	config.ConnectStorage()  // 初始化附件存储
+	config.InitEvents()      // 初始化实时事件中心
+	config.InitRateLimiter() // 初始化限流令牌桶
+
+	// 把配置中的用户名提升为管理员，用于初始化第一个管理员账号
+	if admin := viper.GetString("admin.username"); admin != "" {
+		adminService := service.AdminService{}
+		if err := adminService.Promote(admin); err != nil {
+			slog.Error("设置管理员失败", "username", admin, "error", err)
+		}
+	}
+
+	// 后台任务：发送 Webhook 投递队列
+	workers := service.NewWorkers()
+	workers.Add("webhooks", service.NewWebhookWorker().Run)
+	// 后台任务：清理已经装满的限流令牌桶
+	workers.Add("ratelimit", config.RateLimiter.Run)
+
+	// 组装依赖：存储 -> 服务 -> 路由
+	users := service.NewUserService(repository.NewGormUserRepository(config.DB))
+	todos := service.NewTodoService(repository.NewGormTodoRepository(config.DB), service.PublishEvents)
+	health := service.NewHealthService(config.DB, workers, buildInfo())
+	r := routes.SetupRouter(todos, users, health)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	port := viper.GetString("server.port")
-	r.Run(":" + port) // 动态端口
-}
\ No newline at end of file
+
+	if err := serve(r, workers); err != nil {
+		slog.Error("服务异常退出", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace 所有业务指标的前缀
+const namespace = "gotodo"
+
+// Registry 本服务的指标，/metrics 只输出这里注册的指标（包括 Go 运行时和进程指标）
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests 按路由模板、方法和状态码统计的请求数
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 请求数",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration 按路由模板、方法和状态码统计的请求耗时
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求处理耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HTTPInFlight 正在处理的请求数
+	HTTPInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "正在处理的 HTTP 请求数",
+	})
+
+	// DBQueryDuration 按操作和表统计的 SQL 耗时，由 GORM 回调记录
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "数据库查询耗时（秒）",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// DBQueryErrors 执行失败的 SQL 数，记录不存在不算失败
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "执行失败的数据库查询数",
+	}, []string{"operation", "table"})
+
+	// TodosCreated 创建的任务数
+	TodosCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "todos_created_total",
+		Help:      "创建的任务数",
+	})
+
+	// TodosCompleted 从未完成变为已完成的任务数
+	TodosCompleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "todos_completed_total",
+		Help:      "完成的任务数",
+	})
+
+	// UsersRegistered 注册的用户数
+	UsersRegistered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_registered_total",
+		Help:      "注册的用户数",
+	})
+
+	// LoginFailures 按原因统计的登录失败次数，原因见 Login* 常量
+	LoginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "登录失败次数",
+	}, []string{"reason"})
+
+	// RateLimited 按分组统计的被限流拒绝的请求数
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "被限流拒绝的请求数",
+	}, []string{"group"})
+
+	// WebhookDeliveries 按结果统计的 Webhook 投递次数（每次尝试计一次），结果见 Delivery* 常量
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Webhook 投递尝试次数",
+	}, []string{"result"})
+)
+
+// 登录失败的原因
+const (
+	LoginUserNotFound  = "user_not_found"
+	LoginWrongPassword = "wrong_password"
+	LoginUserDisabled  = "user_disabled"
+)
+
+// Webhook 投递的结果
+const (
+	DeliverySucceeded = "succeeded"
+	// DeliveryRetrying 失败后还会重试
+	DeliveryRetrying = "retrying"
+	// DeliveryFailed 不再重试
+	DeliveryFailed = "failed"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration, HTTPInFlight,
+		DBQueryDuration, DBQueryErrors,
+		TodosCreated, TodosCompleted, UsersRegistered, LoginFailures, RateLimited, WebhookDeliveries,
+	)
+}
+
+// Handler 以 Prometheus 文本格式输出 Registry 中的指标
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
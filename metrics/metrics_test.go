@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+// TestInstrumentGORM 每条 SQL 按操作和表记录耗时，执行失败的单独计数，连接池统计输出到 /metrics
+func TestInstrumentGORM(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InstrumentGORM(db); err != nil {
+		t.Fatal(err)
+	}
+	db.AutoMigrate(&widget{})
+
+	count := func(operation string) uint64 {
+		var m dto.Metric
+		DBQueryDuration.WithLabelValues(operation, "widgets").(prometheus.Histogram).Write(&m)
+		return m.GetHistogram().GetSampleCount()
+	}
+	db.Create(&widget{Name: "a"})
+	var w widget
+	db.First(&w)
+	if count("create") != 1 || count("query") != 1 {
+		t.Errorf("期望记录 create 和 query 的耗时")
+	}
+	if got := testutil.ToFloat64(DBQueryErrors.WithLabelValues("query", "widgets")); got != 0 {
+		t.Errorf("查询成功不应该计为失败，但得到了 %v", got)
+	}
+	// 记录不存在不算失败
+	db.First(&w, 999)
+	if got := testutil.ToFloat64(DBQueryErrors.WithLabelValues("query", "widgets")); got != 0 {
+		t.Errorf("记录不存在不应该计为失败，但得到了 %v", got)
+	}
+	db.Table("widgets").Where("missing = 1").Find(&[]widget{})
+	if got := testutil.ToFloat64(DBQueryErrors.WithLabelValues("query", "widgets")); got != 1 {
+		t.Errorf("SQL 出错期望计为失败，但得到了 %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	for _, name := range []string{`gotodo_db_query_duration_seconds_count{operation="create",table="widgets"} 1`, `go_sql_open_connections{db_name="sqlite"}`, "go_goroutines"} {
+		if !strings.Contains(rec.Body.String(), name) {
+			t.Errorf("/metrics 缺少 %s", name)
+		}
+	}
+}
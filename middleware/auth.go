@@ -1,13 +1,22 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"go-todo/common"
+	"go-todo/config"
+	"go-todo/logging"
+	"go-todo/service"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// PasswordChangePath 修改密码接口的路由，需要强制改密的用户只能访问它
+const PasswordChangePath = "/api/v1/me/password"
+
+// AuthMiddleware 校验 Bearer Token，users 用来确认 Token 对应的用户仍然有效
+func AuthMiddleware(users *service.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 1. 获取 Authorization Header
 		// 格式通常是: "Bearer <token>"
@@ -31,10 +40,103 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 4. 🔥 关键点：把解析出来的 UserID 塞进上下文 (Context)
+		// 用户已注销、被禁用或 Token 已被作废
+		user, err := users.CheckToken(c.Request.Context(), claims)
+		if err != nil {
+			common.Error(c, 401, err.Error())
+			c.Abort()
+			return
+		}
+
+		// 管理员强制重置密码后，只允许访问修改密码接口
+		if user.MustResetPassword && c.FullPath() != PasswordChangePath {
+			common.Error(c, 403, "密码已被重置，请先修改密码")
+			c.Abort()
+			return
+		}
+
+		// 4. 🔥 关键点：把用户的内部 ID 塞进上下文 (Context)
 		// 这样后续的 Controller 就能通过 c.Get("userID") 知道是谁在发请求了！
-		c.Set("userID", claims.UserID)
+		// Token 里只有公开 ID，内部 ID 来自 CheckToken 查到的用户
+		c.Set("userID", user.ID)
+		c.Set("role", user.Role)
+		// 退出登录需要知道当前是哪个 Token
+		c.Set("claims", claims)
+		// 用户偏好随用户一起读出，控制器不需要再查一次
+		c.Set("profile", user.Profile)
+		// 之后的日志都带上用户的公开 ID
+		logging.SetUserID(c.Request.Context(), user.PublicID)
 
 		c.Next() // 放行
 	}
-}
\ No newline at end of file
+}
+
+// RequireRole 角色校验中间件，必须放在 AuthMiddleware 之后
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		common.Error(c, 403, "权限不足")
+		c.Abort()
+	}
+}
+
+// QueryToken 浏览器的 EventSource 和 WebSocket 不能设置请求头，
+// 允许通过 access_token 查询参数传 Token；读取后从 URL 中去掉，避免写进日志
+func QueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if token := query.Get("access_token"); token != "" {
+			if c.GetHeader("Authorization") == "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+			query.Del("access_token")
+			c.Request.URL.RawQuery = query.Encode()
+			c.Request.RequestURI = c.Request.URL.RequestURI()
+		}
+		c.Next()
+	}
+}
+
+// CSRFHeader Cookie 登录时，修改数据的请求需要在这个请求头中带上 CSRF Token
+const CSRFHeader = "X-CSRF-Token"
+
+// CookieToken Cookie 登录：请求没有 Authorization 头时，从 HttpOnly Cookie 中读取 Token 交给 AuthMiddleware，
+// 需要放在 AuthMiddleware 之前。浏览器会自动携带 Cookie，所以 GET、HEAD、OPTIONS 以外的请求
+// 必须在 X-CSRF-Token 请求头中带上登录时返回的 CSRF Token（与 CSRF Cookie 相同并且属于这个会话）。
+// 使用 Authorization 头的客户端不受影响
+func CookieToken(cfg config.SessionCookieConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled || c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+		token, err := c.Cookie(cfg.Name)
+		if err != nil || token == "" {
+			c.Next()
+			return
+		}
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			csrfToken := c.GetHeader(CSRFHeader)
+			csrfCookie, _ := c.Cookie(cfg.CSRFName)
+			if csrfToken == "" || subtle.ConstantTimeCompare([]byte(csrfToken), []byte(csrfCookie)) != 1 ||
+				!common.CheckCSRFToken(token, csrfToken) {
+				common.Error(c, 403, "CSRF Token 无效")
+				c.Abort()
+				return
+			}
+		}
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+		// WebSocket 握手是 GET 请求，不检查 CSRF Token，需要按这个标记再检查来源
+		c.Set("cookieAuth", true)
+		c.Next()
+	}
+}
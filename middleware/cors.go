@@ -1,26 +1,155 @@
 package middleware
 
 import (
+	"go-todo/config"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Cors() gin.HandlerFunc {
+// CorsGroup 路径在 Prefix 下的接口使用单独的跨域设置
+type CorsGroup struct {
+	Prefix string
+	Config config.CORSConfig
+}
+
+// Cors 跨域资源共享。请求的 Origin 在允许列表中时回显该来源，不在时不输出跨域响应头，由浏览器拦截；
+// 路径在 groups 的某个前缀下时使用该分组的设置（最长的前缀优先），否则使用 cfg。
+// 预检请求直接返回 204（来源或方法不允许时返回 403），不经过之后的中间件和处理函数。
+// 预检请求没有对应的路由，只会经过全局中间件，所以分组的设置也要在这里按路径选择，不能挂在路由分组上
+func Cors(cfg config.CORSConfig, groups ...CorsGroup) gin.HandlerFunc {
+	def := newCorsPolicy(cfg)
+	type group struct {
+		prefix string
+		policy *corsPolicy
+	}
+	byPrefix := make([]group, 0, len(groups))
+	for _, g := range groups {
+		byPrefix = append(byPrefix, group{strings.TrimSuffix(g.Prefix, "/"), newCorsPolicy(g.Config)})
+	}
+	sort.Slice(byPrefix, func(i, j int) bool { return len(byPrefix[i].prefix) > len(byPrefix[j].prefix) })
+
 	return func(c *gin.Context) {
-		method := c.Request.Method
-		// 允许的来源（本地开发通常是前端地址，云端可以设为 * 或具体域名）
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token, Authorization, Token")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type")
-		c.Header("Access-Control-Allow-Credentials", "true")
-
-		// 放行所有 OPTIONS 方法（预检请求）
-		if method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-		}
-		// 处理请求
-		c.Next()
-	}
-}
\ No newline at end of file
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			// 同源请求或非浏览器客户端
+			c.Next()
+			return
+		}
+		policy := def
+		path := c.Request.URL.Path
+		for _, g := range byPrefix {
+			if path == g.prefix || strings.HasPrefix(path, g.prefix+"/") {
+				policy = g.policy
+				break
+			}
+		}
+
+		header := c.Writer.Header()
+		// 响应随 Origin 变化，缓存不能把一个来源的响应给另一个来源
+		header.Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		allowOrigin, ok := policy.allowOrigin(origin)
+		if !ok {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+		header.Set("Access-Control-Allow-Origin", allowOrigin)
+		if policy.credentials && allowOrigin != "*" {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if policy.exposed != "" {
+				header.Set("Access-Control-Expose-Headers", policy.exposed)
+			}
+			c.Next()
+			return
+		}
+
+		if !policy.methods[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))] {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		header.Set("Access-Control-Allow-Methods", policy.allowMethods)
+		if policy.anyHeader {
+			// 携带凭据时浏览器不认 "*"，回显请求的头
+			if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+				header.Set("Access-Control-Allow-Headers", requested)
+			}
+		} else if policy.allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", policy.allowHeaders)
+		}
+		if policy.maxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(policy.maxAge))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// corsPolicy 预先处理好的跨域设置
+type corsPolicy struct {
+	cfg          config.CORSConfig
+	anyOrigin    bool
+	methods      map[string]bool
+	allowMethods string
+	anyHeader    bool
+	allowHeaders string
+	exposed      string
+	credentials  bool
+	// 秒
+	maxAge int
+}
+
+func newCorsPolicy(cfg config.CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		cfg:         cfg,
+		methods:     make(map[string]bool),
+		exposed:     strings.Join(cfg.ExposedHeaders, ", "),
+		credentials: cfg.AllowCredentials,
+		maxAge:      int(cfg.MaxAge.Seconds()),
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			p.anyOrigin = true
+		}
+	}
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		method = strings.ToUpper(method)
+		p.methods[method] = true
+		methods = append(methods, method)
+	}
+	p.allowMethods = strings.Join(methods, ", ")
+	for _, h := range cfg.AllowedHeaders {
+		if h == "*" {
+			p.anyHeader = true
+		}
+	}
+	p.allowHeaders = strings.Join(cfg.AllowedHeaders, ", ")
+	return p
+}
+
+// allowOrigin 来源是否允许跨域，返回 Access-Control-Allow-Origin 的值。
+// 允许任意来源时返回 "*"，浏览器不会在这种响应上携带凭据
+func (p *corsPolicy) allowOrigin(origin string) (string, bool) {
+	if p.anyOrigin {
+		return "*", true
+	}
+	if p.cfg.MatchOrigin(origin) {
+		return origin, true
+	}
+	return "", false
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"go-todo/common"
+	"go-todo/config"
+	"go-todo/service"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var idempotencyService = service.IdempotencyService{}
+
+// IdempotencyHeader 客户端用来标识一次操作的请求头，重试时使用相同的值
+const IdempotencyHeader = "Idempotency-Key"
+
+// idempotentMethods 支持 Idempotency-Key 的方法
+var idempotentMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// Idempotency 幂等中间件，必须放在 AuthMiddleware 之后。
+// 带 Idempotency-Key 的修改请求第一次执行后保存响应，相同 Key 的重试直接返回保存的响应；
+// 相同 Key 用于不同的请求返回 422，第一次请求还没处理完时返回 409。
+// 服务器错误（业务码 >= 500）不保存，客户端可以用同一个 Key 重试
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || !idempotentMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			common.Error(c, 400, "Idempotency-Key 不能超过 255 个字符")
+			c.Abort()
+			return
+		}
+
+		// 请求体需要完整读入计算指纹，最大不超过附件上传的限制
+		limit := config.AttachmentMaxSize() + 1<<20
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		if err != nil {
+			common.Error(c, 400, "读取请求体失败")
+			c.Abort()
+			return
+		}
+		if int64(len(body)) > limit {
+			common.Error(c, 413, "请求体过大")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.New()
+		io.WriteString(hash, c.Request.Method+" "+c.Request.URL.Path+"\n")
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		userID := c.GetUint("userID")
+		record, replay, err := idempotencyService.Begin(userID, key, fingerprint, config.IdempotencyTTL())
+		switch {
+		case errors.Is(err, service.ErrIdempotencyMismatch):
+			common.Error(c, 422, err.Error())
+			c.Abort()
+			return
+		case errors.Is(err, service.ErrIdempotencyInProgress):
+			common.Error(c, 409, err.Error())
+			c.Abort()
+			return
+		case err != nil:
+			common.Error(c, 500, "服务器错误")
+			c.Abort()
+			return
+		case replay:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		// 处理函数 panic 时释放 Key，否则之后的重试在整个保存期内都会得到 409
+		finished := false
+		defer func() {
+			if !finished {
+				abandonIdempotencyKey(c, record.ID)
+			}
+		}()
+		c.Next()
+		finished = true
+
+		if recorder.Status() >= 500 || businessCode(recorder.body.Bytes()) >= 500 {
+			abandonIdempotencyKey(c, record.ID)
+			return
+		}
+		if err := idempotencyService.Complete(record.ID, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			// 响应没有保存下来，释放 Key 让客户端可以重试，否则重试在整个保存期内都会得到 409
+			slog.ErrorContext(c.Request.Context(), "保存幂等响应失败", "idempotency_key_id", record.ID, "error", err)
+			abandonIdempotencyKey(c, record.ID)
+		}
+	}
+}
+
+// abandonIdempotencyKey 释放 Key，失败时只能等它过期
+func abandonIdempotencyKey(c *gin.Context, id uint) {
+	if err := idempotencyService.Abandon(id); err != nil {
+		slog.ErrorContext(c.Request.Context(), "释放幂等键失败", "idempotency_key_id", id, "error", err)
+	}
+}
+
+// businessCode 读取统一响应中的业务码
+func businessCode(body []byte) int {
+	var resp common.Response
+	if json.Unmarshal(body, &resp) != nil {
+		return 0
+	}
+	return resp.Code
+}
+
+// responseRecorder 在写出响应的同时保留一份副本
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
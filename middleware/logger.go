@@ -1,41 +1,76 @@
 package middleware
 
 import (
-	"fmt"
+	"go-todo/logging"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Logger 是一个中间件函数
+// Logger 请求结束后记录一条访问日志，放在 RequestID 之后。
+// 5xx 记为 error，4xx 记为 warn；debug 级别时附带请求头。
+// Authorization、Cookie 等请求头，以及查询参数和路由参数中的 Token 都会被隐藏
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 1. 请求开始时间
-		startTime := time.Now()
+		start := time.Now()
+		// 分享链接、邀请等接口的路径中带有 Token，处理之前先记下原始路径
+		path := c.Request.URL.Path
+		query := c.Request.URL.RawQuery
 
-		// 2. 处理请求 (这里会跳转到下一个中间件或 Controller)
 		c.Next()
 
-		// 3. 请求结束时间
-		endTime := time.Now()
-		
-		// 4. 计算耗时
-		latency := endTime.Sub(startTime)
+		for _, p := range c.Params {
+			if logging.Sensitive(p.Key) && p.Value != "" {
+				path = strings.Replace(path, p.Value, logging.Redacted, 1)
+			}
+		}
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
 
-		// 5. 获取请求信息
-		method := c.Request.Method
-		uri := c.Request.RequestURI
-		statusCode := c.Writer.Status()
-		clientIP := c.ClientIP()
-
-		// 打印日志
-		// 格式：[状态码] | 耗时 | IP | 方法 | 路径
-		fmt.Printf("| %3d | %13v | %15s | %s | %s |\n",
-			statusCode,
-			latency,
-			clientIP,
-			method,
-			uri,
-		)
+		ctx := c.Request.Context()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if query != "" {
+			attrs = append(attrs, slog.String("query", logging.RedactQuery(query)))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		if slog.Default().Enabled(ctx, slog.LevelDebug) {
+			headers := make([]any, 0, len(c.Request.Header))
+			for name, values := range c.Request.Header {
+				// 属性名敏感时 ReplaceAttr 会隐藏值
+				headers = append(headers, slog.String(name, strings.Join(values, ", ")))
+			}
+			attrs = append(attrs, slog.Group("headers", headers...))
+		}
+		slog.LogAttrs(ctx, level, "HTTP 请求", attrs...)
 	}
-}
\ No newline at end of file
+}
+
+// Recovery 捕获处理函数中的 panic，记录错误日志和调用栈后返回 500
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "请求处理异常", "panic", err, "stack", string(debug.Stack()))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fmt"
+	"go-todo/common"
+	"go-todo/metrics"
+	"go-todo/ratelimit"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit 令牌桶限流，group 是限流分组（如 auth、api）。
+// 登录后（放在 AuthMiddleware 之后）按用户限制，否则按客户端 IP 限制。
+// 响应头带有 RateLimit-Limit/Remaining/Reset，超过限制时返回 HTTP 429 和 Retry-After。
+// 存储出错时放行请求，限流故障不影响正常使用
+func RateLimit(group string, limit ratelimit.Limit, store ratelimit.Store) gin.HandlerFunc {
+	if !limit.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy := fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, int(limit.Period.Seconds()), limit.Burst)
+	return func(c *gin.Context) {
+		key := group + ":ip:" + c.ClientIP()
+		if userID, ok := c.Get("userID"); ok {
+			key = fmt.Sprintf("%s:user:%v", group, userID)
+		}
+		result, err := store.Allow(c.Request.Context(), key, limit)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "限流检查失败，放行请求", "group", group, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		if !result.Allowed {
+			metrics.RateLimited.WithLabelValues(group).Inc()
+			c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, common.Response{Code: 429, Msg: "请求过于频繁，请稍后再试"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds 向上取整的秒数，响应头中不能出现 0 秒后重试
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"go-todo/logging"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader 请求 ID 的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID 只接受上游（网关、客户端）传入的短 ID，避免把任意内容写进日志和响应头
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID 沿用请求中合法的 X-Request-ID，没有时生成一个，写入响应头和请求 ctx，
+// 之后用 c.Request.Context() 写的日志（包括 SQL 日志）都会带上 request_id。必须放在最前面
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
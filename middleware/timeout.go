@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"go-todo/common"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// baseContextKey 第一个 Timeout 之前的请求 context
+const baseContextKey = "baseContext"
+
+// Timeout 给请求的 context 设置处理时限，d <= 0 表示不限制。
+// 路由组和单个路由可以各自设置，离处理函数最近的 Timeout 生效（可以比外层的更长）。
+// 超时或客户端断开后，服务层和数据库查询放弃执行；处理函数没有写响应时返回 504/503
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		base, ok := c.Get(baseContextKey)
+		if !ok {
+			base = c.Request.Context()
+			c.Set(baseContextKey, base)
+		}
+		ctx := base.(context.Context)
+		if d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if code, msg, ok := common.ContextError(ctx.Err()); ok && !c.Writer.Written() {
+			common.Error(c, code, msg)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go-todo/config"
+	"go-todo/migrations"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = `用法:
+  migrate up          执行所有未执行的迁移
+  migrate down [n]    回滚最近执行的 n 个迁移（默认 1）
+  migrate status      查看每个迁移的执行状态`
+
+// runMigrate 执行 migrate 子命令，只管理表结构，不启动服务
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	m, err := migrations.New(config.DB)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := m.Up()
+		for _, mig := range done {
+			fmt.Printf("✅ 已执行 %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("没有未执行的迁移")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errors.New("回滚数量必须是正整数")
+			}
+		}
+		done, err := m.Down(steps)
+		for _, mig := range done {
+			fmt.Printf("↩️  已回滚 %04d_%s\n", mig.Version, mig.Name)
+		}
+		return err
+	case "status":
+		list, err := m.Status()
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range list {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+		return err
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,241 @@
+package migrations
+
+import (
+	"testing"
+
+	"go-todo/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) (*gorm.DB, *Migrator) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, m
+}
+
+// TestDialectsInSync 每个方言都要有相同的版本，并且都有 down 脚本
+func TestDialectsInSync(t *testing.T) {
+	sqliteList, err := Load("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dialect := range []string{"mysql", "postgres"} {
+		list, err := Load(dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sqliteList) != len(list) {
+			t.Fatalf("sqlite 有 %d 个迁移，%s 有 %d 个", len(sqliteList), dialect, len(list))
+		}
+		for i := range sqliteList {
+			if sqliteList[i].Version != list[i].Version || sqliteList[i].Name != list[i].Name {
+				t.Errorf("%s 第 %d 个迁移不一致: %d_%s / %d_%s", dialect, i, sqliteList[i].Version, sqliteList[i].Name,
+					list[i].Version, list[i].Name)
+			}
+			if len(splitStatements(list[i].Down)) == 0 {
+				t.Errorf("%s 迁移 %d_%s 缺少 down 脚本", dialect, list[i].Version, list[i].Name)
+			}
+		}
+	}
+	for _, mig := range sqliteList {
+		if len(splitStatements(mig.Down)) == 0 {
+			t.Errorf("sqlite 迁移 %d_%s 缺少 down 脚本", mig.Version, mig.Name)
+		}
+	}
+	if _, err := Load("oracle"); err == nil {
+		t.Error("不支持的方言应该返回错误")
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	db, m := openTestDB(t)
+
+	all, _ := Load("sqlite")
+	done, err := m.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != len(all) {
+		t.Fatalf("期望执行 %d 个迁移，执行了 %d 个", len(all), len(done))
+	}
+	if done, _ = m.Up(); len(done) != 0 {
+		t.Errorf("重复执行不应该再有迁移，得到 %d 个", len(done))
+	}
+	if pending, _ := m.Pending(); len(pending) != 0 {
+		t.Errorf("不应该有未执行的迁移: %v", pending)
+	}
+
+	// 回滚最新的一个
+	done, err = m.Down(1)
+	if err != nil || len(done) != 1 || done[0].Version != all[len(all)-1].Version {
+		t.Fatalf("应该回滚最新的迁移: %v %v", done, err)
+	}
+	status, _ := m.Status()
+	if status[len(status)-1].AppliedAt != nil || status[0].AppliedAt == nil {
+		t.Error("回滚后只有最新的迁移是未执行状态")
+	}
+
+	// 全部回滚后表都被删除
+	if _, err := m.Down(len(all)); err != nil {
+		t.Fatal(err)
+	}
+	if db.Migrator().HasTable("users") {
+		t.Error("全部回滚后 users 表应该被删除")
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("回滚后应该可以重新执行: %v", err)
+	}
+
+	// 数据库比二进制新时拒绝继续
+	db.Create(&Record{Version: 9999, Name: "future"})
+	if _, err := m.Pending(); err == nil {
+		t.Error("数据库中有未知版本时应该返回错误")
+	}
+}
+
+// TestSchemaMatchesModels 迁移建出来的表要包含模型需要的所有列和索引
+func TestSchemaMatchesModels(t *testing.T) {
+	db, m := openTestDB(t)
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	for _, model := range []interface{}{&models.User{}, &models.Todo{}, &models.TodoAssignee{},
+		&models.Workspace{}, &models.WorkspaceMember{}, &models.WorkspaceInvite{},
+		&models.ShareLink{}, &models.Comment{}, &models.CommentMention{}, &models.Attachment{},
+		&models.Webhook{}, &models.WebhookDelivery{}, &models.TodoChange{},
+		&models.IdempotencyKey{}, &models.RateLimitBucket{}, &models.RevokedToken{}} {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" || f.IgnoreMigration {
+				continue
+			}
+			if !db.Migrator().HasColumn(model, f.DBName) {
+				t.Errorf("%s 缺少列 %s", stmt.Schema.Table, f.DBName)
+			}
+		}
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			if !db.Migrator().HasIndex(model, idx.Name) {
+				t.Errorf("%s 缺少索引 %s", stmt.Schema.Table, idx.Name)
+			}
+		}
+	}
+}
+
+// TestPublicIDBackfill 补齐公开 ID 的迁移为已有数据生成各不相同的 ID
+func TestPublicIDBackfill(t *testing.T) {
+	db, m := openTestDB(t)
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	// 回到加公开 ID 之前的结构，写入旧数据
+	for {
+		status, _ := m.Status()
+		if statusOf(status, "public_ids").AppliedAt == nil {
+			break
+		}
+		m.Down(1)
+	}
+	db.Exec("INSERT INTO users (username) VALUES ('alice'), ('bob')")
+	db.Exec("INSERT INTO todos (title, user_id) VALUES ('任务1', 1)")
+	db.Exec("INSERT INTO todo_changes (todo_id) VALUES (1), (99)")
+
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	var users []string
+	db.Table("users").Pluck("public_id", &users)
+	if len(users) != 2 || len(users[0]) != 36 || users[0] == users[1] {
+		t.Errorf("用户公开 ID 不正确: %v", users)
+	}
+	var todo, change string
+	db.Table("todos").Select("public_id").Scan(&todo)
+	db.Table("todo_changes").Where("todo_id = 1").Select("public_id").Scan(&change)
+	if len(todo) != 36 || change != todo {
+		t.Errorf("变更日志应该使用任务的公开 ID: %q %q", todo, change)
+	}
+}
+
+func statusOf(list []Status, name string) Status {
+	for _, s := range list {
+		if s.Name == name {
+			return s
+		}
+	}
+	return Status{}
+}
+
+// baselineUser、baselineTodo 是改用版本化迁移之前的模型，旧库的表由 AutoMigrate 按它们建出来
+type baselineUser struct {
+	gorm.Model
+	Username string `gorm:"unique"`
+	Password string
+	Todos    []baselineTodo `gorm:"foreignKey:UserID"`
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineTodo struct {
+	ID          uint `gorm:"primaryKey"`
+	Title       string
+	Description string
+	Status      bool
+	UserID      uint
+}
+
+func (baselineTodo) TableName() string { return "todos" }
+
+// TestUpgradeFromBaseline 由 AutoMigrate 建好表、已经有数据的旧库可以直接执行全部迁移
+func TestUpgradeFromBaseline(t *testing.T) {
+	db, m := openTestDB(t)
+	if err := db.AutoMigrate(&baselineUser{}, &baselineTodo{}); err != nil {
+		t.Fatal(err)
+	}
+	old := baselineUser{Username: "alice", Password: "hash",
+		Todos: []baselineTodo{{Title: "旧任务", Status: true}, {Title: "另一个"}}}
+	if err := db.Create(&old).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("旧库执行迁移失败: %v", err)
+	}
+
+	var user models.User
+	if err := db.First(&user, old.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "alice" || len(user.PublicID) != 36 || user.Role != models.RoleUser ||
+		user.TokenVersion != 0 || user.Disabled || user.Profile.TimeZone != "UTC" || user.Profile.WeekStart != 1 {
+		t.Errorf("已有用户的新字段不正确: %+v", user)
+	}
+	var todos []models.Todo
+	if err := db.Where("user_id = ?", user.ID).Order("id").Find(&todos).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != 2 || todos[0].Title != "旧任务" || !todos[0].Status {
+		t.Fatalf("已有任务不正确: %+v", todos)
+	}
+	if len(todos[0].PublicID) != 36 || todos[0].PublicID == todos[1].PublicID || todos[0].CreatedAt.IsZero() {
+		t.Errorf("已有任务应该补上公开 ID 和创建时间: %+v", todos[0])
+	}
+
+	// 迁移后的表可以写入新模型
+	clientID := "c-1"
+	if err := db.Create(&models.Todo{PublicID: "new", ClientID: &clientID, Title: "新任务", UserID: user.ID}).Error; err != nil {
+		t.Errorf("迁移后写入任务失败: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+-- 初始表结构，与改用版本化迁移之前 AutoMigrate 生成的 users、todos 表一致。
+-- 使用 IF NOT EXISTS，已经由 AutoMigrate 建好表的数据库可以直接纳入迁移管理，之后的列和表由后续迁移逐个加上
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `username` varchar(191),
+  `password` longtext,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_users_username` UNIQUE (`username`),
+  INDEX `idx_users_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `todos` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `title` longtext,
+  `description` longtext,
+  `status` boolean,
+  `user_id` bigint unsigned,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_users_todos` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
@@ -0,0 +1,10 @@
+ALTER TABLE `users` DROP COLUMN `default_sort`;
+ALTER TABLE `users` DROP COLUMN `week_start`;
+ALTER TABLE `users` DROP COLUMN `default_project`;
+ALTER TABLE `users` DROP COLUMN `locale`;
+ALTER TABLE `users` DROP COLUMN `time_zone`;
+ALTER TABLE `users` DROP COLUMN `display_name`;
+ALTER TABLE `users` DROP COLUMN `must_reset_password`;
+ALTER TABLE `users` DROP COLUMN `disabled`;
+ALTER TABLE `users` DROP COLUMN `role`;
+ALTER TABLE `users` DROP COLUMN `token_version`;
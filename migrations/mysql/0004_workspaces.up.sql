@@ -0,0 +1,37 @@
+-- 团队工作区：工作区、成员和邀请
+
+CREATE TABLE IF NOT EXISTS `workspaces` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` longtext,
+  `owner_id` bigint unsigned,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_workspaces_owner_id` (`owner_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `workspace_members` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `workspace_id` bigint unsigned,
+  `user_id` bigint unsigned,
+  `role` varchar(20),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_workspace_member` (`workspace_id`, `user_id`),
+  INDEX `idx_workspace_members_user_id` (`user_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `workspace_invites` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `workspace_id` bigint unsigned,
+  `token` varchar(64),
+  `invitee_id` bigint unsigned,
+  `role` varchar(20),
+  `invited_by` bigint unsigned,
+  `expires_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_workspace_invites_token` (`token`),
+  INDEX `idx_workspace_invites_workspace_id` (`workspace_id`),
+  INDEX `idx_workspace_invites_invitee_id` (`invitee_id`)
+);
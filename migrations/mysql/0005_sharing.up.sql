@@ -0,0 +1,60 @@
+-- 分享链接、评论和附件
+
+CREATE TABLE IF NOT EXISTS `share_links` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `token` varchar(64),
+  `owner_id` bigint unsigned,
+  `todo_id` bigint unsigned,
+  `workspace_id` bigint unsigned,
+  `project` longtext,
+  `permission` varchar(20),
+  `password_hash` longtext,
+  `expires_at` datetime(3) NULL,
+  `revoked_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_share_links_token` (`token`),
+  INDEX `idx_share_links_owner_id` (`owner_id`),
+  INDEX `idx_share_links_todo_id` (`todo_id`),
+  INDEX `idx_share_links_workspace_id` (`workspace_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `comments` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `todo_id` bigint unsigned,
+  `author_id` bigint unsigned,
+  `author_name` longtext,
+  `share_link_id` bigint unsigned,
+  `body` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_comments_todo_id` (`todo_id`),
+  INDEX `idx_comments_author_id` (`author_id`),
+  INDEX `idx_comments_share_link_id` (`share_link_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `comment_mentions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `comment_id` bigint unsigned,
+  `user_id` bigint unsigned,
+  `username` longtext,
+  PRIMARY KEY (`id`),
+  INDEX `idx_comment_mentions_comment_id` (`comment_id`),
+  INDEX `idx_comment_mentions_user_id` (`user_id`),
+  CONSTRAINT `fk_comments_mentions` FOREIGN KEY (`comment_id`) REFERENCES `comments`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `attachments` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `todo_id` bigint unsigned,
+  `uploader_id` bigint unsigned,
+  `filename` longtext,
+  `content_type` longtext,
+  `size` bigint,
+  `storage_key` varchar(255),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_attachments_todo_id` (`todo_id`),
+  INDEX `idx_attachments_uploader_id` (`uploader_id`)
+);
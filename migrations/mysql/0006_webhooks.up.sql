@@ -0,0 +1,33 @@
+-- Webhook 订阅和投递队列
+
+CREATE TABLE IF NOT EXISTS `webhooks` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `url` varchar(2048),
+  `events` longtext,
+  `secret` varchar(128),
+  `active` boolean,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_webhooks_user_id` (`user_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `webhook_id` bigint unsigned,
+  `event_id` bigint unsigned,
+  `event_type` varchar(50),
+  `payload` text,
+  `status` varchar(20),
+  `attempts` bigint,
+  `next_attempt_at` datetime(3) NULL,
+  `response_status` bigint,
+  `last_error` varchar(1024),
+  `delivered_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_webhook_deliveries_webhook_id` (`webhook_id`),
+  INDEX `idx_delivery_queue` (`status`, `next_attempt_at`)
+);
@@ -0,0 +1,31 @@
+-- 离线同步的变更日志和幂等键
+
+CREATE TABLE IF NOT EXISTS `todo_changes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `todo_id` bigint unsigned,
+  `client_id` varchar(64),
+  `user_id` bigint unsigned,
+  `workspace_id` bigint unsigned,
+  `deleted` boolean,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_todo_changes_todo_id` (`todo_id`),
+  INDEX `idx_todo_changes_user_id` (`user_id`),
+  INDEX `idx_todo_changes_workspace_id` (`workspace_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `idempotency_keys` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `idempotency_key` varchar(255),
+  `fingerprint` varchar(64),
+  `completed` boolean,
+  `status_code` bigint,
+  `content_type` varchar(255),
+  `response_body` longblob,
+  `expires_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_user_idempotency_key` (`user_id`, `idempotency_key`),
+  INDEX `idx_idempotency_keys_expires_at` (`expires_at`)
+);
@@ -0,0 +1,5 @@
+DROP INDEX `idx_todos_public_id` ON `todos`;
+DROP INDEX `idx_users_public_id` ON `users`;
+ALTER TABLE `todo_changes` DROP COLUMN `public_id`;
+ALTER TABLE `todos` DROP COLUMN `public_id`;
+ALTER TABLE `users` DROP COLUMN `public_id`;
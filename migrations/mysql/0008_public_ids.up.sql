@@ -0,0 +1,16 @@
+-- 用户和任务的公开 ID。新记录由应用生成 UUIDv7，
+-- 已有的记录在这里补上随机的 UUIDv4，两者格式相同、都不可枚举
+
+ALTER TABLE `users` ADD COLUMN `public_id` varchar(36);
+ALTER TABLE `todos` ADD COLUMN `public_id` varchar(36);
+ALTER TABLE `todo_changes` ADD COLUMN `public_id` varchar(36);
+
+UPDATE `users` SET `public_id` = LOWER(CONCAT(HEX(RANDOM_BYTES(4)), '-', HEX(RANDOM_BYTES(2)), '-4', SUBSTR(HEX(RANDOM_BYTES(2)), 2), '-', SUBSTR('89ab', 1 + FLOOR(RAND() * 4), 1), SUBSTR(HEX(RANDOM_BYTES(2)), 2), '-', HEX(RANDOM_BYTES(6))))
+WHERE `public_id` IS NULL OR `public_id` = '';
+UPDATE `todos` SET `public_id` = LOWER(CONCAT(HEX(RANDOM_BYTES(4)), '-', HEX(RANDOM_BYTES(2)), '-4', SUBSTR(HEX(RANDOM_BYTES(2)), 2), '-', SUBSTR('89ab', 1 + FLOOR(RAND() * 4), 1), SUBSTR(HEX(RANDOM_BYTES(2)), 2), '-', HEX(RANDOM_BYTES(6))))
+WHERE `public_id` IS NULL OR `public_id` = '';
+-- 已经删除的任务查不到公开 ID，它们的墓碑 id 为空
+UPDATE `todo_changes` SET `public_id` = (SELECT `public_id` FROM `todos` WHERE `todos`.`id` = `todo_changes`.`todo_id`);
+
+CREATE UNIQUE INDEX `idx_users_public_id` ON `users` (`public_id`);
+CREATE UNIQUE INDEX `idx_todos_public_id` ON `todos` (`public_id`);
@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX `idx_todos_client_id` ON `todos` (`client_id`);
+DROP INDEX `idx_todo_client` ON `todos`;
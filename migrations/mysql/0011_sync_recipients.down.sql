@@ -0,0 +1,2 @@
+DROP INDEX `idx_todo_changes_recipient_id` ON `todo_changes`;
+ALTER TABLE `todo_changes` DROP COLUMN `recipient_id`;
@@ -0,0 +1,4 @@
+-- 只发给某个成员的同步变更：加入工作区时补发任务，离开工作区时发送墓碑
+
+ALTER TABLE `todo_changes` ADD COLUMN `recipient_id` bigint unsigned;
+CREATE INDEX `idx_todo_changes_recipient_id` ON `todo_changes` (`recipient_id`);
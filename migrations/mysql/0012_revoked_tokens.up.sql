@@ -0,0 +1,13 @@
+-- 退出登录时单独作废的 Token
+
+CREATE TABLE IF NOT EXISTS `revoked_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `token_id` varchar(36),
+  `user_id` bigint unsigned,
+  `expires_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_revoked_tokens_token_id` (`token_id`),
+  INDEX `idx_revoked_tokens_user_id` (`user_id`),
+  INDEX `idx_revoked_tokens_expires_at` (`expires_at`)
+);
@@ -0,0 +1,24 @@
+-- 初始表结构，与 mysql、sqlite 的 0001 一致。
+-- PostgreSQL 支持是在改用版本化迁移之后加入的，不存在由 AutoMigrate 建好的旧库，IF NOT EXISTS 只是为了保持一致
+
+CREATE TABLE IF NOT EXISTS "users" (
+  "id" bigserial,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "username" text,
+  "password" text,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_users_username" UNIQUE ("username")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "todos" (
+  "id" bigserial,
+  "title" text,
+  "description" text,
+  "status" boolean,
+  "user_id" bigint,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_users_todos" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
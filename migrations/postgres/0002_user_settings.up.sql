@@ -0,0 +1,15 @@
+-- 用户的令牌版本、角色、账号状态和个人设置
+
+ALTER TABLE "users" ADD COLUMN "token_version" bigint;
+ALTER TABLE "users" ADD COLUMN "role" varchar(20) DEFAULT 'user';
+ALTER TABLE "users" ADD COLUMN "disabled" boolean;
+ALTER TABLE "users" ADD COLUMN "must_reset_password" boolean;
+ALTER TABLE "users" ADD COLUMN "display_name" text;
+ALTER TABLE "users" ADD COLUMN "time_zone" text DEFAULT 'UTC';
+ALTER TABLE "users" ADD COLUMN "locale" text DEFAULT 'zh-CN';
+ALTER TABLE "users" ADD COLUMN "default_project" text;
+ALTER TABLE "users" ADD COLUMN "week_start" bigint DEFAULT 1;
+ALTER TABLE "users" ADD COLUMN "default_sort" text DEFAULT 'created_asc';
+
+-- 已有用户补上默认值
+UPDATE "users" SET "token_version" = 0, "disabled" = false, "must_reset_password" = false WHERE "token_version" IS NULL;
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "todo_assignees";
+DROP INDEX IF EXISTS "idx_todos_workspace_id";
+DROP INDEX IF EXISTS "idx_todos_project";
+DROP INDEX IF EXISTS "idx_todos_client_id";
+ALTER TABLE "todos" DROP COLUMN "updated_at";
+ALTER TABLE "todos" DROP COLUMN "created_at";
+ALTER TABLE "todos" DROP COLUMN "workspace_id";
+ALTER TABLE "todos" DROP COLUMN "due_date";
+ALTER TABLE "todos" DROP COLUMN "project";
+ALTER TABLE "todos" DROP COLUMN "client_id";
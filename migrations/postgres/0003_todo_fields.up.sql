@@ -0,0 +1,27 @@
+-- 任务的客户端 ID、项目、截止时间、所属工作区和时间戳，以及任务负责人
+
+ALTER TABLE "todos" ADD COLUMN "client_id" varchar(64);
+ALTER TABLE "todos" ADD COLUMN "project" text;
+ALTER TABLE "todos" ADD COLUMN "due_date" timestamptz;
+ALTER TABLE "todos" ADD COLUMN "workspace_id" bigint;
+ALTER TABLE "todos" ADD COLUMN "created_at" timestamptz;
+ALTER TABLE "todos" ADD COLUMN "updated_at" timestamptz;
+
+-- 已有任务的创建和修改时间记为迁移时间
+UPDATE "todos" SET "created_at" = CURRENT_TIMESTAMP, "updated_at" = CURRENT_TIMESTAMP WHERE "created_at" IS NULL;
+
+CREATE UNIQUE INDEX "idx_todos_client_id" ON "todos" ("client_id");
+CREATE INDEX "idx_todos_project" ON "todos" ("project");
+CREATE INDEX "idx_todos_workspace_id" ON "todos" ("workspace_id");
+
+CREATE TABLE IF NOT EXISTS "todo_assignees" (
+  "id" bigserial,
+  "todo_id" bigint,
+  "user_id" bigint,
+  "assigned_by" bigint,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_todos_assignees" FOREIGN KEY ("todo_id") REFERENCES "todos"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_todo_assignee" ON "todo_assignees" ("todo_id", "user_id");
+CREATE INDEX IF NOT EXISTS "idx_todo_assignees_user_id" ON "todo_assignees" ("user_id");
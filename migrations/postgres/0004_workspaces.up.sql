@@ -0,0 +1,37 @@
+-- 团队工作区：工作区、成员和邀请
+
+CREATE TABLE IF NOT EXISTS "workspaces" (
+  "id" bigserial,
+  "name" text,
+  "owner_id" bigint,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_workspaces_owner_id" ON "workspaces" ("owner_id");
+
+CREATE TABLE IF NOT EXISTS "workspace_members" (
+  "id" bigserial,
+  "workspace_id" bigint,
+  "user_id" bigint,
+  "role" varchar(20),
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_workspace_member" ON "workspace_members" ("workspace_id", "user_id");
+CREATE INDEX IF NOT EXISTS "idx_workspace_members_user_id" ON "workspace_members" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "workspace_invites" (
+  "id" bigserial,
+  "workspace_id" bigint,
+  "token" varchar(64),
+  "invitee_id" bigint,
+  "role" varchar(20),
+  "invited_by" bigint,
+  "expires_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_workspace_invites_token" ON "workspace_invites" ("token");
+CREATE INDEX IF NOT EXISTS "idx_workspace_invites_workspace_id" ON "workspace_invites" ("workspace_id");
+CREATE INDEX IF NOT EXISTS "idx_workspace_invites_invitee_id" ON "workspace_invites" ("invitee_id");
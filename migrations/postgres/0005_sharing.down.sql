@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "attachments";
+DROP TABLE IF EXISTS "comment_mentions";
+DROP TABLE IF EXISTS "comments";
+DROP TABLE IF EXISTS "share_links";
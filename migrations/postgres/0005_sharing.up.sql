@@ -0,0 +1,60 @@
+-- 分享链接、评论和附件
+
+CREATE TABLE IF NOT EXISTS "share_links" (
+  "id" bigserial,
+  "token" varchar(64),
+  "owner_id" bigint,
+  "todo_id" bigint,
+  "workspace_id" bigint,
+  "project" text,
+  "permission" varchar(20),
+  "password_hash" text,
+  "expires_at" timestamptz,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_share_links_token" ON "share_links" ("token");
+CREATE INDEX IF NOT EXISTS "idx_share_links_owner_id" ON "share_links" ("owner_id");
+CREATE INDEX IF NOT EXISTS "idx_share_links_todo_id" ON "share_links" ("todo_id");
+CREATE INDEX IF NOT EXISTS "idx_share_links_workspace_id" ON "share_links" ("workspace_id");
+
+CREATE TABLE IF NOT EXISTS "comments" (
+  "id" bigserial,
+  "todo_id" bigint,
+  "author_id" bigint,
+  "author_name" text,
+  "share_link_id" bigint,
+  "body" text,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_comments_todo_id" ON "comments" ("todo_id");
+CREATE INDEX IF NOT EXISTS "idx_comments_author_id" ON "comments" ("author_id");
+CREATE INDEX IF NOT EXISTS "idx_comments_share_link_id" ON "comments" ("share_link_id");
+
+CREATE TABLE IF NOT EXISTS "comment_mentions" (
+  "id" bigserial,
+  "comment_id" bigint,
+  "user_id" bigint,
+  "username" text,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_comments_mentions" FOREIGN KEY ("comment_id") REFERENCES "comments"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_comment_mentions_comment_id" ON "comment_mentions" ("comment_id");
+CREATE INDEX IF NOT EXISTS "idx_comment_mentions_user_id" ON "comment_mentions" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "attachments" (
+  "id" bigserial,
+  "todo_id" bigint,
+  "uploader_id" bigint,
+  "filename" text,
+  "content_type" text,
+  "size" bigint,
+  "storage_key" varchar(255),
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_attachments_todo_id" ON "attachments" ("todo_id");
+CREATE INDEX IF NOT EXISTS "idx_attachments_uploader_id" ON "attachments" ("uploader_id");
@@ -0,0 +1,33 @@
+-- Webhook 订阅和投递队列
+
+CREATE TABLE IF NOT EXISTS "webhooks" (
+  "id" bigserial,
+  "user_id" bigint,
+  "url" varchar(2048),
+  "events" text,
+  "secret" varchar(128),
+  "active" boolean,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhooks_user_id" ON "webhooks" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+  "id" bigserial,
+  "webhook_id" bigint,
+  "event_id" bigint,
+  "event_type" varchar(50),
+  "payload" text,
+  "status" varchar(20),
+  "attempts" bigint,
+  "next_attempt_at" timestamptz,
+  "response_status" bigint,
+  "last_error" varchar(1024),
+  "delivered_at" timestamptz,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_webhook_id" ON "webhook_deliveries" ("webhook_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_queue" ON "webhook_deliveries" ("status", "next_attempt_at");
@@ -0,0 +1,31 @@
+-- 离线同步的变更日志和幂等键
+
+CREATE TABLE IF NOT EXISTS "todo_changes" (
+  "id" bigserial,
+  "todo_id" bigint,
+  "client_id" varchar(64),
+  "user_id" bigint,
+  "workspace_id" bigint,
+  "deleted" boolean,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_todo_changes_todo_id" ON "todo_changes" ("todo_id");
+CREATE INDEX IF NOT EXISTS "idx_todo_changes_user_id" ON "todo_changes" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_todo_changes_workspace_id" ON "todo_changes" ("workspace_id");
+
+CREATE TABLE IF NOT EXISTS "idempotency_keys" (
+  "id" bigserial,
+  "user_id" bigint,
+  "idempotency_key" varchar(255),
+  "fingerprint" varchar(64),
+  "completed" boolean,
+  "status_code" bigint,
+  "content_type" varchar(255),
+  "response_body" bytea,
+  "expires_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_idempotency_key" ON "idempotency_keys" ("user_id", "idempotency_key");
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
@@ -0,0 +1,8 @@
+-- 多实例共享的限流令牌桶（ratelimit.store=database）
+
+CREATE TABLE IF NOT EXISTS "rate_limit_buckets" (
+  "bucket_key" varchar(191) NOT NULL,
+  "tat" bigint,
+  PRIMARY KEY ("bucket_key")
+);
+CREATE INDEX IF NOT EXISTS "idx_rate_limit_buckets_tat" ON "rate_limit_buckets" ("tat");
@@ -0,0 +1,13 @@
+-- 退出登录时单独作废的 Token
+
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+  "id" bigserial,
+  "token_id" varchar(36),
+  "user_id" bigint,
+  "expires_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_revoked_tokens_token_id" ON "revoked_tokens" ("token_id");
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_user_id" ON "revoked_tokens" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
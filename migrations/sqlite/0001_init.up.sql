@@ -0,0 +1,7 @@
+-- 初始表结构，与改用版本化迁移之前 AutoMigrate 生成的 users、todos 表一致。
+-- 使用 IF NOT EXISTS，已经由 AutoMigrate 建好表的数据库可以直接纳入迁移管理，之后的列和表由后续迁移逐个加上
+
+CREATE TABLE IF NOT EXISTS `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`username` text,`password` text,CONSTRAINT `uni_users_username` UNIQUE (`username`));
+CREATE INDEX IF NOT EXISTS `idx_users_deleted_at` ON `users`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `todos` (`id` integer PRIMARY KEY AUTOINCREMENT,`title` text,`description` text,`status` numeric,`user_id` integer,CONSTRAINT `fk_users_todos` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
@@ -0,0 +1,19 @@
+-- 任务的客户端 ID、项目、截止时间、所属工作区和时间戳，以及任务负责人
+
+ALTER TABLE `todos` ADD COLUMN `client_id` text;
+ALTER TABLE `todos` ADD COLUMN `project` text;
+ALTER TABLE `todos` ADD COLUMN `due_date` datetime;
+ALTER TABLE `todos` ADD COLUMN `workspace_id` integer;
+ALTER TABLE `todos` ADD COLUMN `created_at` datetime;
+ALTER TABLE `todos` ADD COLUMN `updated_at` datetime;
+
+-- 已有任务的创建和修改时间记为迁移时间
+UPDATE `todos` SET `created_at` = CURRENT_TIMESTAMP, `updated_at` = CURRENT_TIMESTAMP WHERE `created_at` IS NULL;
+
+CREATE UNIQUE INDEX `idx_todos_client_id` ON `todos`(`client_id`);
+CREATE INDEX `idx_todos_project` ON `todos`(`project`);
+CREATE INDEX `idx_todos_workspace_id` ON `todos`(`workspace_id`);
+
+CREATE TABLE IF NOT EXISTS `todo_assignees` (`id` integer PRIMARY KEY AUTOINCREMENT,`todo_id` integer,`user_id` integer,`assigned_by` integer,`created_at` datetime,CONSTRAINT `fk_todos_assignees` FOREIGN KEY (`todo_id`) REFERENCES `todos`(`id`));
+CREATE INDEX IF NOT EXISTS `idx_todo_assignees_user_id` ON `todo_assignees`(`user_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_todo_assignee` ON `todo_assignees`(`todo_id`,`user_id`);
@@ -0,0 +1,13 @@
+-- 团队工作区：工作区、成员和邀请
+
+CREATE TABLE IF NOT EXISTS `workspaces` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text,`owner_id` integer,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_workspaces_owner_id` ON `workspaces`(`owner_id`);
+
+CREATE TABLE IF NOT EXISTS `workspace_members` (`id` integer PRIMARY KEY AUTOINCREMENT,`workspace_id` integer,`user_id` integer,`role` text,`created_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_workspace_members_user_id` ON `workspace_members`(`user_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_workspace_member` ON `workspace_members`(`workspace_id`,`user_id`);
+
+CREATE TABLE IF NOT EXISTS `workspace_invites` (`id` integer PRIMARY KEY AUTOINCREMENT,`workspace_id` integer,`token` text,`invitee_id` integer,`role` text,`invited_by` integer,`expires_at` datetime,`created_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_workspace_invites_invitee_id` ON `workspace_invites`(`invitee_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_workspace_invites_token` ON `workspace_invites`(`token`);
+CREATE INDEX IF NOT EXISTS `idx_workspace_invites_workspace_id` ON `workspace_invites`(`workspace_id`);
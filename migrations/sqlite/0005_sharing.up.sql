@@ -0,0 +1,20 @@
+-- 分享链接、评论和附件
+
+CREATE TABLE IF NOT EXISTS `share_links` (`id` integer PRIMARY KEY AUTOINCREMENT,`token` text,`owner_id` integer,`todo_id` integer,`workspace_id` integer,`project` text,`permission` text,`password_hash` text,`expires_at` datetime,`revoked_at` datetime,`created_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_share_links_workspace_id` ON `share_links`(`workspace_id`);
+CREATE INDEX IF NOT EXISTS `idx_share_links_todo_id` ON `share_links`(`todo_id`);
+CREATE INDEX IF NOT EXISTS `idx_share_links_owner_id` ON `share_links`(`owner_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_share_links_token` ON `share_links`(`token`);
+
+CREATE TABLE IF NOT EXISTS `comments` (`id` integer PRIMARY KEY AUTOINCREMENT,`todo_id` integer,`author_id` integer,`author_name` text,`share_link_id` integer,`body` text,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_comments_share_link_id` ON `comments`(`share_link_id`);
+CREATE INDEX IF NOT EXISTS `idx_comments_author_id` ON `comments`(`author_id`);
+CREATE INDEX IF NOT EXISTS `idx_comments_todo_id` ON `comments`(`todo_id`);
+
+CREATE TABLE IF NOT EXISTS `comment_mentions` (`id` integer PRIMARY KEY AUTOINCREMENT,`comment_id` integer,`user_id` integer,`username` text,CONSTRAINT `fk_comments_mentions` FOREIGN KEY (`comment_id`) REFERENCES `comments`(`id`));
+CREATE INDEX IF NOT EXISTS `idx_comment_mentions_user_id` ON `comment_mentions`(`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_comment_mentions_comment_id` ON `comment_mentions`(`comment_id`);
+
+CREATE TABLE IF NOT EXISTS `attachments` (`id` integer PRIMARY KEY AUTOINCREMENT,`todo_id` integer,`uploader_id` integer,`filename` text,`content_type` text,`size` integer,`storage_key` text,`created_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_attachments_uploader_id` ON `attachments`(`uploader_id`);
+CREATE INDEX IF NOT EXISTS `idx_attachments_todo_id` ON `attachments`(`todo_id`);
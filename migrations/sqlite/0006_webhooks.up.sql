@@ -0,0 +1,8 @@
+-- Webhook 订阅和投递队列
+
+CREATE TABLE IF NOT EXISTS `webhooks` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer,`url` text,`events` text,`secret` text,`active` numeric,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_webhooks_user_id` ON `webhooks`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (`id` integer PRIMARY KEY AUTOINCREMENT,`webhook_id` integer,`event_id` integer,`event_type` text,`payload` text,`status` text,`attempts` integer,`next_attempt_at` datetime,`response_status` integer,`last_error` text,`delivered_at` datetime,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_delivery_queue` ON `webhook_deliveries`(`status`,`next_attempt_at`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_webhook_id` ON `webhook_deliveries`(`webhook_id`);
@@ -0,0 +1,10 @@
+-- 离线同步的变更日志和幂等键
+
+CREATE TABLE IF NOT EXISTS `todo_changes` (`id` integer PRIMARY KEY AUTOINCREMENT,`todo_id` integer,`client_id` text,`user_id` integer,`workspace_id` integer,`deleted` numeric,`created_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_todo_changes_workspace_id` ON `todo_changes`(`workspace_id`);
+CREATE INDEX IF NOT EXISTS `idx_todo_changes_user_id` ON `todo_changes`(`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_todo_changes_todo_id` ON `todo_changes`(`todo_id`);
+
+CREATE TABLE IF NOT EXISTS `idempotency_keys` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer,`idempotency_key` text,`fingerprint` text,`completed` numeric,`status_code` integer,`content_type` text,`response_body` blob,`expires_at` datetime,`created_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_idempotency_keys_expires_at` ON `idempotency_keys`(`expires_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_user_idempotency_key` ON `idempotency_keys`(`user_id`,`idempotency_key`);
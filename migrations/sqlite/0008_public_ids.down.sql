@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS `idx_todos_public_id`;
+DROP INDEX IF EXISTS `idx_users_public_id`;
+ALTER TABLE `todo_changes` DROP COLUMN `public_id`;
+ALTER TABLE `todos` DROP COLUMN `public_id`;
+ALTER TABLE `users` DROP COLUMN `public_id`;
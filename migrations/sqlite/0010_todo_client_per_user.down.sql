@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_todos_client_id` ON `todos`(`client_id`);
+DROP INDEX IF EXISTS `idx_todo_client`;
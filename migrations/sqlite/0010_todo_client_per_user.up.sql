@@ -0,0 +1,4 @@
+-- 客户端 ID 只在同一个用户内唯一
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_todo_client` ON `todos`(`user_id`,`client_id`);
+DROP INDEX IF EXISTS `idx_todos_client_id`;
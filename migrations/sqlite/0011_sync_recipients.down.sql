@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_todo_changes_recipient_id`;
+ALTER TABLE `todo_changes` DROP COLUMN `recipient_id`;
@@ -0,0 +1,6 @@
+-- 退出登录时单独作废的 Token
+
+CREATE TABLE IF NOT EXISTS `revoked_tokens` (`id` integer PRIMARY KEY AUTOINCREMENT,`token_id` text,`user_id` integer,`expires_at` datetime,`created_at` datetime);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_revoked_tokens_token_id` ON `revoked_tokens`(`token_id`);
+CREATE INDEX IF NOT EXISTS `idx_revoked_tokens_user_id` ON `revoked_tokens`(`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_revoked_tokens_expires_at` ON `revoked_tokens`(`expires_at`);
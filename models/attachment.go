@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Attachment 任务附件，文件内容保存在存储后端中
+// @Description 任务附件
+type Attachment struct {
+	// 附件 ID
+	ID     uint `json:"id" gorm:"primaryKey" example:"1"`
+	TodoID uint `json:"-" gorm:"index"`
+	// 任务 ID（仅导出时返回）
+	TodoPublicID string `json:"todo_id,omitempty" gorm:"->;-:migration" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	UploaderID   uint   `json:"-" gorm:"index"`
+	// 原始文件名
+	Filename string `json:"filename" example:"screenshot.png"`
+	// MIME 类型（根据文件内容识别）
+	ContentType string `json:"content_type" example:"image/png"`
+	// 文件大小（字节）
+	Size int64 `json:"size" example:"20480"`
+	// 存储后端中的路径
+	StorageKey string `json:"-" gorm:"size:255"`
+	// 上传时间
+	CreatedAt time.Time `json:"created_at"`
+}
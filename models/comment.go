@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Comment 任务评论，正文为 Markdown
+// 通过可评论的分享链接发表的访客评论没有 AuthorID
+// @Description 任务评论
+type Comment struct {
+	// 评论 ID
+	ID     uint `json:"id" gorm:"primaryKey" example:"1"`
+	TodoID uint `json:"-" gorm:"index"`
+	// 任务 ID（仅导出时返回）
+	TodoPublicID string `json:"todo_id,omitempty" gorm:"->;-:migration" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	AuthorID     *uint  `json:"-" gorm:"index"`
+	// 作者用户 ID（仅查询时返回），访客评论为空
+	AuthorPublicID *string `json:"author_id" gorm:"->;-:migration" example:"01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"`
+	// 作者名称（用户名或访客填写的名字）
+	AuthorName string `json:"author_name" example:"john_doe"`
+	// 访客评论所使用的分享链接 ID
+	ShareLinkID *uint `json:"-" gorm:"index"`
+	// 评论正文（Markdown）
+	Body string `json:"body" gorm:"type:text" example:"已经提交了，@jane_doe 帮忙看一下"`
+	// 正文中 @ 到的用户
+	Mentions []CommentMention `json:"mentions" gorm:"foreignKey:CommentID"`
+	// 创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// 更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CommentMention 评论中 @ 到的用户
+// @Description 评论中提到的用户
+type CommentMention struct {
+	ID uint `json:"-" gorm:"primaryKey"`
+	// 评论 ID
+	CommentID uint `json:"-" gorm:"index"`
+	UserID    uint `json:"-" gorm:"index"`
+	// 被提到的用户名
+	Username string `json:"username" example:"jane_doe"`
+}
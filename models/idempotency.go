@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// IdempotencyKey 保存带 Idempotency-Key 请求的响应，重试时直接重放
+type IdempotencyKey struct {
+	ID uint `gorm:"primaryKey"`
+	// 用户 ID，Key 按用户隔离
+	UserID uint `gorm:"uniqueIndex:idx_user_idempotency_key"`
+	// 客户端提供的 Key
+	Key string `gorm:"column:idempotency_key;size:255;uniqueIndex:idx_user_idempotency_key"`
+	// 请求指纹：方法、路径和请求体的 SHA-256
+	Fingerprint string `gorm:"size:64"`
+	// 是否已经处理完成，未完成时相同 Key 的请求会被拒绝
+	Completed bool
+	// 响应状态码
+	StatusCode int
+	// 响应的 Content-Type
+	ContentType string `gorm:"size:255"`
+	// 响应体
+	ResponseBody []byte
+	// 过期时间，过期后 Key 可以重新使用
+	ExpiresAt time.Time `gorm:"index"`
+	// 创建时间
+	CreatedAt time.Time
+}
@@ -0,0 +1,9 @@
+package models
+
+import "github.com/google/uuid"
+
+// NewPublicID 生成对外暴露的 ID。
+// UUIDv7 以时间戳开头，按创建顺序递增，索引局部性好，又不会泄露行数
+func NewPublicID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
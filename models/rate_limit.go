@@ -0,0 +1,10 @@
+package models
+
+// RateLimitBucket 限流令牌桶的状态，多个实例共享限流时使用（ratelimit.store=database）
+type RateLimitBucket struct {
+	// 限流分组和用户 ID / 客户端 IP，如 "auth:ip:203.0.113.7"
+	Key string `gorm:"column:bucket_key;primaryKey;size:191"`
+	// 令牌桶的理论到达时间（Unix 纳秒）：早于当前时间说明桶已经满了，
+	// 比当前时间晚多少，桶里就少了对应数量的令牌
+	TAT int64 `gorm:"column:tat;index"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RevokedToken 退出登录时单独作废的 Token，只影响这一个会话；
+// Token 过期后这条记录就没有用了，可以删除
+type RevokedToken struct {
+	ID uint `gorm:"primaryKey"`
+	// Token 的 jti
+	TokenID string `gorm:"size:36;uniqueIndex"`
+	// Token 所属用户
+	UserID uint `gorm:"index"`
+	// Token 的过期时间
+	ExpiresAt time.Time `gorm:"index"`
+	// 作废时间
+	CreatedAt time.Time
+}
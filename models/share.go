@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// 分享链接的权限
+const (
+	SharePermissionRead    = "read"
+	SharePermissionComment = "comment"
+)
+
+// ShareLink 分享链接，无需登录即可查看一个任务或一个项目下的任务
+// TodoID 不为空时分享单个任务；否则分享 Project 项目，
+// 范围是 WorkspaceID 对应的工作区（为空时是创建者的个人任务）
+// @Description 分享链接
+type ShareLink struct {
+	// 分享 ID
+	ID uint `json:"id" gorm:"primaryKey" example:"1"`
+	// 分享口令，用于拼接公开链接
+	Token   string `json:"token" gorm:"size:64;uniqueIndex" example:"9c1e..."`
+	OwnerID uint   `json:"-" gorm:"index"`
+	TodoID  *uint  `json:"-" gorm:"index"`
+	// 分享的任务 ID（仅查询时返回）
+	TodoPublicID *string `json:"todo_id" gorm:"->;-:migration" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	// 分享项目所在的工作区 ID
+	WorkspaceID *uint `json:"workspace_id" gorm:"index" example:"1"`
+	// 分享的项目名称
+	Project string `json:"project" example:"工作"`
+	// 权限：read 只读，comment 可评论
+	Permission string `json:"permission" gorm:"size:20" example:"read"`
+	// 访问密码（bcrypt 加密，不返回）
+	PasswordHash string `json:"-"`
+	// 是否设置了访问密码
+	HasPassword bool `json:"has_password" gorm:"-" example:"false"`
+	// 过期时间，为空表示不过期
+	ExpiresAt *time.Time `json:"expires_at"`
+	// 撤销时间，撤销后链接立即失效
+	RevokedAt *time.Time `json:"revoked_at"`
+	// 创建时间
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// TodoChange 任务变更日志，用于增量同步。
+// 每个任务只保留最新的一条，删除的任务以墓碑（Deleted=true）的形式保留
+type TodoChange struct {
+	// 自增 ID，同步 token 记录的就是它
+	ID uint `gorm:"primaryKey"`
+	// 任务 ID
+	TodoID uint `gorm:"index"`
+	// 任务的公开 ID，任务删除后墓碑仍需要返回它
+	PublicID string `gorm:"size:36"`
+	// 任务的客户端 ID
+	ClientID *string `gorm:"size:64"`
+	// 任务创建者，用于判断个人任务的可见范围
+	UserID uint `gorm:"index"`
+	// 任务所属工作区
+	WorkspaceID *uint `gorm:"index"`
+	// 不为空时只发给该用户：加入工作区时补发的任务，或离开工作区后的墓碑
+	RecipientID *uint `gorm:"index"`
+	// 是否为删除
+	Deleted bool
+	// 变更时间
+	CreatedAt time.Time
+}
+
+// Tombstone 同步接口返回的已删除任务
+// @Description 已删除的任务
+type Tombstone struct {
+	// 任务 ID
+	ID string `json:"id" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	// 任务的客户端 ID
+	ClientID *string `json:"client_id" example:"3f1c2a9e-8d4b-4c1e-9a57-0b6f3f2d1e7c"`
+	// 删除时间
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -1,20 +1,69 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 // Todo 任务模型
 // @Description 任务信息结构体
 type Todo struct {
-	// 任务 ID
-	ID uint `json:"id" gorm:"primaryKey" example:"1"`
+	ID uint `json:"-" gorm:"primaryKey"`
+	// 任务 ID（UUIDv7），对外只暴露它，自增主键仅在内部使用
+	PublicID string `json:"id" gorm:"size:36;uniqueIndex" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	// 客户端生成的 ID（离线创建时使用），创建后不可修改，同一个用户内唯一
+	ClientID *string `json:"client_id" gorm:"size:64;uniqueIndex:idx_todo_client,priority:2" example:"3f1c2a9e-8d4b-4c1e-9a57-0b6f3f2d1e7c"`
 	// 任务标题
 	Title string `json:"title" example:"完成项目文档"`
 	// 任务描述
 	Description string `json:"description" example:"编写详细的 README 和 API 文档"`
 	// 完成状态：true 完成, false 未完成
 	Status bool `json:"status" example:"false"`
-	// 所属用户 ID
-	UserID uint `json:"user_id" example:"1"`
+	// 所属项目，未填写时使用用户的默认项目
+	Project string `json:"project" gorm:"index" example:"工作"`
+	// 截止时间（RFC3339），返回时会转换到用户时区
+	DueDate *time.Time `json:"due_date" example:"2026-01-02T18:00:00+08:00"`
+	UserID uint `json:"-" gorm:"uniqueIndex:idx_todo_client,priority:1"`
+	// 创建者用户 ID（仅查询时返回）
+	CreatorID string `json:"user_id" gorm:"-" example:"01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"`
+	// 所属工作区 ID，为空表示个人任务
+	WorkspaceID *uint `json:"workspace_id" gorm:"index" example:"1"`
+	// 评论数量（仅在任务列表中返回）
+	CommentCount int64 `json:"comment_count" gorm:"-" example:"3"`
+	// 负责人列表（通过分配接口维护，创建和更新任务时忽略）
+	Assignees []TodoAssignee `json:"assignees" gorm:"foreignKey:TodoID"`
+	// 创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// 更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TodoAssignee 任务负责人，与创建者 UserID 相互独立
+// @Description 任务负责人
+type TodoAssignee struct {
+	ID uint `json:"-" gorm:"primaryKey"`
+	TodoID uint `json:"-" gorm:"uniqueIndex:idx_todo_assignee"`
+	// 任务 ID（仅导出时返回）
+	TodoPublicID string `json:"todo_id,omitempty" gorm:"->;-:migration" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	UserID uint `json:"-" gorm:"uniqueIndex:idx_todo_assignee;index"`
+	// 负责人用户 ID（仅查询时返回）
+	UserPublicID string `json:"user_id,omitempty" gorm:"->;-:migration" example:"01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"`
+	// 负责人用户名（仅查询时返回）
+	Username string `json:"username,omitempty" gorm:"->;-:migration" example:"jane_doe"`
+	AssignedBy uint `json:"-"`
+	// 分配时间
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate 为新任务生成公开 ID
+func (t *Todo) BeforeCreate(tx *gorm.DB) error {
+	if t.PublicID == "" {
+		t.PublicID = NewPublicID()
+	}
+	return nil
 }
 
 // 注意那个 `json:"title"`
 // 这叫做 "Tag" (标签)。
-// 它的作用是告诉 Go：把结构体转成 JSON 返回给前端时，这个字段叫 "title" (小写)，而不是 "Title"。
\ No newline at end of file
+// 它的作用是告诉 Go：把结构体转成 JSON 返回给前端时，这个字段叫 "title" (小写)，而不是 "Title"。
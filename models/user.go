@@ -1,16 +1,75 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // User 用户模型
 // @Description 用户信息结构体
 type User struct {
 	// 数据库标准字段：ID, CreatedAt, UpdatedAt, DeletedAt
 	gorm.Model
+	// 用户 ID（UUIDv7），对外只暴露它，自增主键仅在内部使用
+	PublicID string `gorm:"size:36;uniqueIndex" json:"id" example:"01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"`
 	// 用户名，全局唯一
 	Username string `gorm:"unique" json:"username" example:"john_doe"`
 	// 密码（JSON 返回时忽略，防止泄露）
 	Password string `json:"-"`
+	// Token 版本号，递增后之前签发的所有 Token 失效
+	TokenVersion uint `json:"-"`
+	// 角色：user 或 admin
+	Role string `gorm:"size:20;default:user" json:"role" example:"user"`
+	// 是否被管理员禁用
+	Disabled bool `json:"disabled" example:"false"`
+	// 是否需要在下次登录后修改密码（管理员强制重置）
+	MustResetPassword bool `json:"must_reset_password" example:"false"`
+	// 个人资料与偏好设置（与用户同表存储）
+	Profile Profile `gorm:"embedded" json:"profile"`
 	// 该用户的所有任务
 	Todos []Todo `json:"todos"`
-}
\ No newline at end of file
+}
+
+// BeforeCreate 为新用户生成公开 ID
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.PublicID == "" {
+		u.PublicID = NewPublicID()
+	}
+	return nil
+}
+
+// 用户角色
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Profile 用户个人资料与偏好
+// @Description 用户个人资料与偏好设置
+type Profile struct {
+	// 显示名称
+	DisplayName string `json:"display_name" example:"John"`
+	// 时区（IANA 名称），解析和展示日期时使用
+	TimeZone string `gorm:"default:UTC" json:"time_zone" example:"Asia/Shanghai"`
+	// 语言区域
+	Locale string `gorm:"default:zh-CN" json:"locale" example:"zh-CN"`
+	// 默认项目，创建任务时未指定项目则使用它
+	DefaultProject string `json:"default_project" example:"工作"`
+	// 每周起始日：0 表示周日，1 表示周一，以此类推
+	WeekStart int `gorm:"default:1" json:"week_start" example:"1"`
+	// 任务列表的默认排序方式
+	DefaultSort string `gorm:"default:created_asc" json:"default_sort" example:"created_asc"`
+}
+
+// Location 返回用户时区，未设置或无法识别时退回 UTC
+func (p Profile) Location() *time.Location {
+	if p.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
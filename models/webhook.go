@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// 投递状态
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook 用户配置的事件订阅，任务变更时向 URL 发送签名的 POST 请求
+// @Description Webhook 订阅
+type Webhook struct {
+	// Webhook ID
+	ID     uint `json:"id" gorm:"primaryKey" example:"1"`
+	UserID uint `json:"-" gorm:"index"`
+	// 接收事件的地址
+	URL string `json:"url" gorm:"size:2048" example:"https://ci.example.com/hooks/todo"`
+	// 订阅的事件类型，为空表示全部
+	Events []string `json:"events" gorm:"serializer:json" example:"todo.created,todo.updated"`
+	// 签名密钥，只在创建时返回
+	Secret string `json:"secret,omitempty" gorm:"size:128" example:"whsec_..."`
+	// 是否启用
+	Active bool `json:"active" example:"true"`
+	// 创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// 更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery 一次事件投递，同时也是持久化的投递队列
+// @Description Webhook 投递记录
+type WebhookDelivery struct {
+	// 投递 ID
+	ID uint `json:"id" gorm:"primaryKey" example:"1"`
+	// Webhook ID
+	WebhookID uint `json:"webhook_id" gorm:"index" example:"1"`
+	// 事件 ID
+	EventID uint64 `json:"event_id" example:"1760000000000001"`
+	// 事件类型
+	EventType string `json:"event_type" gorm:"size:50" example:"todo.created"`
+	// 请求体
+	Payload string `json:"payload" gorm:"type:text"`
+	// 状态：pending、succeeded、failed
+	Status string `json:"status" gorm:"size:20;index:idx_delivery_queue" example:"succeeded"`
+	// 已尝试次数
+	Attempts int `json:"attempts" example:"1"`
+	// 下次尝试时间
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"index:idx_delivery_queue"`
+	// 最近一次响应状态码
+	ResponseStatus int `json:"response_status" example:"200"`
+	// 最近一次错误
+	LastError string `json:"last_error" gorm:"size:1024"`
+	// 投递成功时间
+	DeliveredAt *time.Time `json:"delivered_at"`
+	// 创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// 更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribed Webhook 是否订阅了该事件类型
+func (w Webhook) Subscribed(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
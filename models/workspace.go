@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// 工作区成员角色
+const (
+	WorkspaceOwner  = "owner"
+	WorkspaceEditor = "editor"
+	WorkspaceViewer = "viewer"
+)
+
+// Workspace 工作区，多个用户共享同一组任务
+// @Description 工作区信息
+type Workspace struct {
+	// 工作区 ID
+	ID uint `json:"id" gorm:"primaryKey" example:"1"`
+	// 工作区名称
+	Name    string `json:"name" example:"产品研发组"`
+	OwnerID uint   `json:"-" gorm:"index"`
+	// 当前用户在该工作区的角色（仅查询时返回）
+	Role string `json:"role,omitempty" gorm:"->;-:migration" example:"owner"`
+	// 创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// 更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkspaceMember 工作区成员
+// @Description 工作区成员及其角色
+type WorkspaceMember struct {
+	ID uint `json:"-" gorm:"primaryKey"`
+	// 工作区 ID
+	WorkspaceID uint `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_member" example:"1"`
+	UserID      uint `json:"-" gorm:"uniqueIndex:idx_workspace_member;index"`
+	// 用户 ID（仅查询时返回）
+	UserPublicID string `json:"user_id,omitempty" gorm:"->;-:migration" example:"01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"`
+	// 用户名（仅查询时返回）
+	Username string `json:"username,omitempty" gorm:"->;-:migration" example:"john_doe"`
+	// 角色：owner / editor / viewer
+	Role string `json:"role" gorm:"size:20" example:"editor"`
+	// 加入时间
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorkspaceInvite 工作区邀请
+// 指定了 InviteeID 的邀请只能由该用户接受，且只能使用一次；
+// 没有指定的是链接邀请，任何拿到 Token 的用户在过期前都可以加入
+// @Description 工作区邀请
+type WorkspaceInvite struct {
+	// 邀请 ID
+	ID uint `json:"id" gorm:"primaryKey" example:"1"`
+	// 工作区 ID
+	WorkspaceID uint `json:"workspace_id" gorm:"index" example:"1"`
+	// 邀请口令，用于拼接邀请链接
+	Token string `json:"token" gorm:"size:64;uniqueIndex" example:"3f2a..."`
+	// 被邀请的用户，链接邀请为空
+	InviteeID *uint `json:"-" gorm:"index"`
+	// 被邀请的用户名（仅查询时返回），链接邀请为空
+	Invitee string `json:"invitee,omitempty" gorm:"->;-:migration" example:"jane_doe"`
+	// 加入后的角色：editor / viewer
+	Role      string `json:"role" gorm:"size:20" example:"editor"`
+	InvitedBy uint   `json:"-"`
+	// 过期时间，为空表示不过期
+	ExpiresAt *time.Time `json:"expires_at"`
+	// 创建时间
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"go-todo/models"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DatabaseStore 把令牌桶保存在 rate_limit_buckets 表中，连接同一个数据库的所有实例共享限流。
+// 每次取令牌是一条带条件的 UPDATE，不需要锁，多个实例同时请求也不会多发令牌
+type DatabaseStore struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// NewDatabaseStore 创建使用 db 的令牌桶
+func NewDatabaseStore(db *gorm.DB) *DatabaseStore {
+	return &DatabaseStore{db: db, now: time.Now}
+}
+
+func (s *DatabaseStore) Allow(ctx context.Context, key string, l Limit) (Result, error) {
+	db := s.db.WithContext(ctx)
+	now := s.now()
+	nowNanos := now.UnixNano()
+	interval := int64(l.interval())
+	capacity := interval * int64(l.Burst)
+
+	// 桶不存在时先创建一个满的桶
+	bucket := models.RateLimitBucket{Key: key, TAT: nowNanos}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&bucket).Error; err != nil {
+		return Result{}, err
+	}
+
+	// tat = max(tat, now) + interval，只有新的 tat 不超过 now + capacity 时才更新
+	start := gorm.Expr("CASE WHEN tat > ? THEN tat ELSE ? END", nowNanos, nowNanos)
+	res := db.Model(&models.RateLimitBucket{}).
+		Where("bucket_key = ? AND (CASE WHEN tat > ? THEN tat ELSE ? END) + ? <= ?", key, nowNanos, nowNanos, interval, nowNanos+capacity).
+		Update("tat", gorm.Expr("? + ?", start, interval))
+	if res.Error != nil {
+		return Result{}, res.Error
+	}
+
+	// 读取更新后的状态计算剩余令牌数；其他实例可能同时更新，结果只用于响应头
+	if err := db.Where("bucket_key = ?", key).First(&bucket).Error; err != nil {
+		return Result{}, err
+	}
+	tat := time.Unix(0, bucket.TAT)
+	if res.RowsAffected == 0 {
+		// 被拒绝：按当前状态计算需要等待的时间
+		_, result := take(tat, now, l)
+		result.Allowed = false
+		return result, nil
+	}
+	if tat.Before(now) {
+		tat = now
+	}
+	return Result{
+		Allowed:   true,
+		Limit:     l.Burst,
+		Remaining: int((time.Duration(capacity) - tat.Sub(now)) / l.interval()),
+		Reset:     tat.Sub(now),
+	}, nil
+}
+
+func (s *DatabaseStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.cleanup(ctx); err != nil {
+				slog.ErrorContext(ctx, "清理限流令牌桶失败", "error", err)
+			}
+		}
+	}
+}
+
+// cleanup 删除已经装满的桶，它们和不存在的桶等价
+func (s *DatabaseStore) cleanup(ctx context.Context) error {
+	return s.db.WithContext(ctx).Where("tat <= ?", s.now().UnixNano()).Delete(&models.RateLimitBucket{}).Error
+}
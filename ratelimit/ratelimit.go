@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit 令牌桶限制：每 Period 补充 Requests 个令牌，桶最多存 Burst 个令牌
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Enabled Requests、Period 和 Burst 都大于 0 时才限流
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0 && l.Burst > 0
+}
+
+// interval 补充一个令牌需要的时间
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// Result 一次取令牌的结果
+type Result struct {
+	// Allowed 是否取到了令牌
+	Allowed bool
+	// Limit 桶的容量
+	Limit int
+	// Remaining 取完之后桶里还剩的令牌数
+	Remaining int
+	// RetryAfter 被拒绝时，需要等待多久才能取到下一个令牌
+	RetryAfter time.Duration
+	// Reset 多久之后桶会重新装满
+	Reset time.Duration
+}
+
+// Store 保存令牌桶的状态。内存实现只在单个实例内限流，
+// 多实例部署时使用 DatabaseStore 等共享的实现
+type Store interface {
+	// Allow 从 key 对应的桶中取一个令牌
+	Allow(ctx context.Context, key string, l Limit) (Result, error)
+	// Run 定期清理已经装满的桶，直到 ctx 被取消
+	Run(ctx context.Context)
+}
+
+// cleanupInterval 清理已经装满的桶的间隔
+const cleanupInterval = time.Minute
+
+// take 用 GCRA 算法计算一次取令牌：tat 是桶的理论到达时间，不早于 now 时
+// tat - now 就是桶里缺少的令牌对应的时间。返回新的 tat 和结果，被拒绝时 tat 不变
+func take(tat, now time.Time, l Limit) (time.Time, Result) {
+	interval := l.interval()
+	capacity := interval * time.Duration(l.Burst)
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(interval)
+	if next.Sub(now) > capacity {
+		return tat, Result{
+			Limit:      l.Burst,
+			RetryAfter: next.Sub(now) - capacity,
+			Reset:      tat.Sub(now),
+		}
+	}
+	return next, Result{
+		Allowed:   true,
+		Limit:     l.Burst,
+		Remaining: int((capacity - next.Sub(now)) / interval),
+		Reset:     next.Sub(now),
+	}
+}
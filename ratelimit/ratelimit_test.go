@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go-todo/migrations"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// clock 测试用的时钟
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// stores 内存和数据库（SQLite 内存库）两种实现，同一组测试在两者上运行
+func stores(t *testing.T, c *clock) map[string]Store {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrations.New(db)
+	if err == nil {
+		_, err = m.Up()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := NewMemoryStore()
+	memory.now = c.Now
+	database := NewDatabaseStore(db)
+	database.now = c.Now
+	return map[string]Store{"memory": memory, "database": database}
+}
+
+func TestStore(t *testing.T) {
+	// 每分钟 60 个，最多连续 3 个：每秒补充一个令牌
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 3}
+	c := &clock{now: time.Unix(1_700_000_000, 0)}
+	for name, s := range stores(t, c) {
+		t.Run(name, func(t *testing.T) {
+			for i := 2; i >= 0; i-- {
+				r, err := s.Allow(t.Context(), "auth:ip:1", limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !r.Allowed || r.Remaining != i || r.Limit != 3 {
+					t.Fatalf("期望允许并剩余 %d 个，但得到了 %+v", i, r)
+				}
+			}
+			r, _ := s.Allow(t.Context(), "auth:ip:1", limit)
+			if r.Allowed || r.RetryAfter != time.Second || r.Reset != 3*time.Second {
+				t.Errorf("桶空了期望拒绝并在 1 秒后重试，但得到了 %+v", r)
+			}
+			// 不同的 key 互不影响
+			if r, _ := s.Allow(t.Context(), "auth:ip:2", limit); !r.Allowed {
+				t.Errorf("其他 key 不应该被限流: %+v", r)
+			}
+
+			// 一秒后补充一个令牌
+			c.Advance(time.Second)
+			if r, _ := s.Allow(t.Context(), "auth:ip:1", limit); !r.Allowed || r.Remaining != 0 {
+				t.Errorf("补充令牌后期望允许，但得到了 %+v", r)
+			}
+			if r, _ := s.Allow(t.Context(), "auth:ip:1", limit); r.Allowed {
+				t.Errorf("补充的令牌已经用完，期望拒绝，但得到了 %+v", r)
+			}
+
+			// 很久之后桶是满的，但不会超过 Burst
+			c.Advance(time.Hour)
+			if r, _ := s.Allow(t.Context(), "auth:ip:1", limit); !r.Allowed || r.Remaining != 2 {
+				t.Errorf("桶装满后期望剩余 2 个，但得到了 %+v", r)
+			}
+		})
+	}
+}
+
+// TestDatabaseStoreCleanup 装满的桶被删除，删除后行为不变
+func TestDatabaseStoreCleanup(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 1}
+	c := &clock{now: time.Unix(1_700_000_000, 0)}
+	s := stores(t, c)["database"].(*DatabaseStore)
+	s.Allow(t.Context(), "a", limit)
+	c.Advance(time.Minute)
+	s.Allow(t.Context(), "b", limit)
+	if err := s.cleanup(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	s.db.Table("rate_limit_buckets").Pluck("bucket_key", &keys)
+	if len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("期望只保留未装满的桶 b，但得到了 %v", keys)
+	}
+	if r, _ := s.Allow(t.Context(), "a", limit); !r.Allowed {
+		t.Errorf("清理后的桶期望是满的: %+v", r)
+	}
+}
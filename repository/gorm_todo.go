@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"go-todo/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormTodoRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTodoRepository 基于 GORM 的任务存储
+func NewGormTodoRepository(db *gorm.DB) TodoRepository {
+	return &gormTodoRepository{db: db}
+}
+
+func (r *gormTodoRepository) List(ctx context.Context, f TodoFilter) ([]models.Todo, int64, error) {
+	db := r.db.WithContext(ctx)
+	var todos []models.Todo
+	var total int64
+
+	query := scope(db, f)
+	if f.Project != "" {
+		query = query.Where("project = ?", f.Project)
+	}
+	if f.DueFrom != nil {
+		query = query.Where("due_date >= ?", *f.DueFrom)
+	}
+	if f.DueBefore != nil {
+		query = query.Where("due_date < ?", *f.DueBefore)
+	}
+	if f.Undone {
+		query = query.Where("status = ?", false)
+	}
+	if f.AssigneeID != nil {
+		query = query.Where("id IN (?)", db.Model(&models.TodoAssignee{}).Select("todo_id").Where("user_id = ?", *f.AssigneeID))
+	}
+	if f.Unassigned {
+		query = query.Where("id NOT IN (?)", db.Model(&models.TodoAssignee{}).Select("todo_id"))
+	}
+
+	// 先查询总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := TodoSorts[f.Sort]
+	if !ok {
+		order = TodoSorts[DefaultTodoSort]
+	}
+	query = query.Order(order).Preload("Assignees", PreloadAssignees)
+	if f.Limit > 0 {
+		query = query.Offset(f.Offset).Limit(f.Limit)
+	}
+	if err := query.Find(&todos).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 补充每条任务的评论数量
+	ids := make([]uint, 0, len(todos))
+	for _, t := range todos {
+		ids = append(ids, t.ID)
+	}
+	counts, err := commentCounts(db, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range todos {
+		todos[i].CommentCount = counts[todos[i].ID]
+	}
+	if err := FillCreators(db, todos); err != nil {
+		return nil, 0, err
+	}
+	return todos, total, nil
+}
+
+// scope 用户可以看到的任务范围：个人任务，或者某个工作区的任务，跨工作区查询时是两者的并集
+func scope(db *gorm.DB, f TodoFilter) *gorm.DB {
+	query := db.Model(&models.Todo{})
+	if f.AllWorkspaces {
+		joined := db.Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", f.UserID)
+		return query.Where("(user_id = ? AND workspace_id IS NULL) OR workspace_id IN (?)", f.UserID, joined)
+	}
+	if f.WorkspaceID == nil {
+		return query.Where("user_id = ? AND workspace_id IS NULL", f.UserID)
+	}
+	return query.Where("workspace_id = ?", *f.WorkspaceID)
+}
+
+// commentCounts 统计一批任务各自的评论数量
+func commentCounts(db *gorm.DB, ids []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		TodoID uint
+		Count  int64
+	}
+	err := db.Model(&models.Comment{}).Select("todo_id, COUNT(*) AS count").
+		Where("todo_id IN ?", ids).Group("todo_id").Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.TodoID] = row.Count
+	}
+	return counts, err
+}
+
+func (r *gormTodoRepository) FindByID(ctx context.Context, id uint) (models.Todo, error) {
+	var todo models.Todo
+	err := r.db.WithContext(ctx).First(&todo, id).Error
+	return todo, err
+}
+
+func (r *gormTodoRepository) FindByPublicID(ctx context.Context, publicID string) (models.Todo, error) {
+	db := r.db.WithContext(ctx)
+	var todo models.Todo
+	if err := db.Preload("Assignees", PreloadAssignees).Where("public_id = ?", publicID).First(&todo).Error; err != nil {
+		return todo, err
+	}
+	return todo, fillCreator(db, &todo)
+}
+
+func (r *gormTodoRepository) Create(ctx context.Context, todo *models.Todo) error {
+	db := r.db.WithContext(ctx)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(todo).Error; err != nil {
+			return err
+		}
+		return recordTodoChange(tx, *todo, false)
+	})
+	if err != nil {
+		return err
+	}
+	return fillCreator(db, todo)
+}
+
+func (r *gormTodoRepository) Update(ctx context.Context, todo *models.Todo) error {
+	db := r.db.WithContext(ctx)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(todo).Error; err != nil {
+			return err
+		}
+		return recordTodoChange(tx, *todo, false)
+	})
+	if err != nil {
+		return err
+	}
+	return fillCreator(db, todo)
+}
+
+func (r *gormTodoRepository) Delete(ctx context.Context, todo models.Todo) ([]string, error) {
+	var blobs []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		blobs, err = DeleteTodos(tx, []uint{todo.ID})
+		return err
+	})
+	return blobs, err
+}
+
+func (r *gormTodoRepository) AddAssignee(ctx context.Context, assignee models.TodoAssignee) error {
+	db := r.db.WithContext(ctx)
+	var count int64
+	err := db.Model(&models.TodoAssignee{}).Where("todo_id = ? AND user_id = ?", assignee.TodoID, assignee.UserID).Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+	return db.Create(&assignee).Error
+}
+
+func (r *gormTodoRepository) RemoveAssignee(ctx context.Context, todoID, userID uint) error {
+	return r.db.WithContext(ctx).Where("todo_id = ? AND user_id = ?", todoID, userID).Delete(&models.TodoAssignee{}).Error
+}
+
+func (r *gormTodoRepository) RecordChange(ctx context.Context, todo models.Todo) error {
+	return recordTodoChange(r.db.WithContext(ctx), todo, false)
+}
+
+func (r *gormTodoRepository) MemberRole(ctx context.Context, workspaceID, userID uint) (string, error) {
+	var member models.WorkspaceMember
+	err := r.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	return member.Role, err
+}
+
+func (r *gormTodoRepository) Members(ctx context.Context, workspaceID uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.WithContext(ctx).Model(&models.WorkspaceMember{}).Where("workspace_id = ?", workspaceID).Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// PreloadAssignees 预加载负责人时一并查出用户名和用户公开 ID
+func PreloadAssignees(db *gorm.DB) *gorm.DB {
+	return db.Select("todo_assignees.*, users.username, users.public_id AS user_public_id").
+		Joins("JOIN users ON users.id = todo_assignees.user_id").
+		Order("todo_assignees.id ASC")
+}
+
+// FillCreators 把任务创建者的内部 ID 换成公开 ID
+func FillCreators(db *gorm.DB, todos []models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(todos))
+	for _, t := range todos {
+		ids = append(ids, t.UserID)
+	}
+	var users []models.User
+	if err := db.Unscoped().Select("id", "public_id").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return err
+	}
+	publicIDs := make(map[uint]string, len(users))
+	for _, u := range users {
+		publicIDs[u.ID] = u.PublicID
+	}
+	for i := range todos {
+		todos[i].CreatorID = publicIDs[todos[i].UserID]
+	}
+	return nil
+}
+
+// fillCreator 单个任务版本的 FillCreators
+func fillCreator(db *gorm.DB, todo *models.Todo) error {
+	todos := []models.Todo{*todo}
+	if err := FillCreators(db, todos); err != nil {
+		return err
+	}
+	todo.CreatorID = todos[0].CreatorID
+	return nil
+}
+
+// DeleteTodos 在事务中删除任务及其关联记录，返回附件文件的存储键，
+// 由调用方在事务提交后从存储中删除
+func DeleteTodos(tx *gorm.DB, ids []uint) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := tx.Where("todo_id IN ?", ids).Delete(&models.TodoAssignee{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("todo_id IN ?", ids).Delete(&models.ShareLink{}).Error; err != nil {
+		return nil, err
+	}
+	if err := DeleteComments(tx, "todo_id IN ?", ids); err != nil {
+		return nil, err
+	}
+	var blobs []string
+	if err := tx.Model(&models.Attachment{}).Where("todo_id IN ?", ids).Pluck("storage_key", &blobs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("todo_id IN ?", ids).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+	// 为增量同步留下墓碑
+	var todos []models.Todo
+	if err := tx.Select("id", "public_id", "client_id", "user_id", "workspace_id").Find(&todos, ids).Error; err != nil {
+		return nil, err
+	}
+	for _, todo := range todos {
+		if err := recordTodoChange(tx, todo, true); err != nil {
+			return nil, err
+		}
+	}
+	return blobs, tx.Delete(&models.Todo{}, ids).Error
+}
+
+// DeleteComments 在事务中删除满足条件的评论及其提及记录
+func DeleteComments(tx *gorm.DB, query string, args ...interface{}) error {
+	ids := tx.Model(&models.Comment{}).Select("id").Where(query, args...)
+	if err := tx.Where("comment_id IN (?)", ids).Delete(&models.CommentMention{}).Error; err != nil {
+		return err
+	}
+	return tx.Where(query, args...).Delete(&models.Comment{}).Error
+}
+
+// recordTodoChange 写入变更日志，并删除该任务更早的变更，每个任务只保留最新一条。
+// 发给已离开成员的墓碑需要保留，他们看不到之后的普通变更
+func recordTodoChange(tx *gorm.DB, todo models.Todo, deleted bool) error {
+	change := models.TodoChange{
+		TodoID:      todo.ID,
+		PublicID:    todo.PublicID,
+		ClientID:    todo.ClientID,
+		UserID:      todo.UserID,
+		WorkspaceID: todo.WorkspaceID,
+		Deleted:     deleted,
+	}
+	if err := tx.Create(&change).Error; err != nil {
+		return err
+	}
+	return tx.Where("todo_id = ? AND id < ? AND (recipient_id IS NULL OR deleted = ?)", todo.ID, change.ID, false).Delete(&models.TodoChange{}).Error
+}
+
+// RecordMemberChanges 成员加入或离开工作区时，为该成员单独写入工作区内每个任务的变更：
+// 加入时补发任务，离开时写入墓碑，之前发给该成员的同类变更会被替换
+func RecordMemberChanges(tx *gorm.DB, workspaceID, userID uint, deleted bool) error {
+	if err := tx.Where("recipient_id = ? AND workspace_id = ?", userID, workspaceID).Delete(&models.TodoChange{}).Error; err != nil {
+		return err
+	}
+	var todos []models.Todo
+	if err := tx.Select("id", "public_id", "client_id", "user_id", "workspace_id").Where("workspace_id = ?", workspaceID).Find(&todos).Error; err != nil {
+		return err
+	}
+	if len(todos) == 0 {
+		return nil
+	}
+	changes := make([]models.TodoChange, 0, len(todos))
+	for _, todo := range todos {
+		changes = append(changes, models.TodoChange{
+			TodoID:      todo.ID,
+			PublicID:    todo.PublicID,
+			ClientID:    todo.ClientID,
+			UserID:      todo.UserID,
+			WorkspaceID: todo.WorkspaceID,
+			RecipientID: &userID,
+			Deleted:     deleted,
+		})
+	}
+	return tx.CreateInBatches(changes, 100).Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"go-todo/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUserRepository 基于 GORM 的用户存储
+func NewGormUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).First(&user, id).Error
+	return user, err
+}
+
+func (r *gormUserRepository) FindByPublicID(ctx context.Context, publicID string) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("public_id = ?", publicID).First(&user).Error
+	return user, err
+}
+
+func (r *gormUserRepository) FindByUsername(ctx context.Context, username string) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	return user, err
+}
+
+func (r *gormUserRepository) UpdatePassword(ctx context.Context, id uint, hash string, tokenVersion uint) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		Select("Password", "MustResetPassword", "TokenVersion").
+		Updates(models.User{Password: hash, MustResetPassword: false, TokenVersion: tokenVersion}).Error
+}
+
+func (r *gormUserRepository) RevokeTokens(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		Update("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+func (r *gormUserRepository) RevokeToken(ctx context.Context, id uint, tokenID string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND expires_at <= ?", id, time.Now()).Delete(&models.RevokedToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.RevokedToken{TokenID: tokenID, UserID: id, ExpiresAt: expiresAt}).Error
+	})
+}
+
+func (r *gormUserRepository) TokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RevokedToken{}).Where("token_id = ?", tokenID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) UpdateProfile(ctx context.Context, id uint, profile models.Profile) error {
+	// 用 Select 显式指定列，保证 0 和空字符串也能被写入
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		Select("DisplayName", "TimeZone", "Locale", "DefaultProject", "WeekStart", "DefaultSort").
+		Updates(models.User{Profile: profile}).Error
+}
@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"go-todo/models"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryTodoRepository 保存在内存中的任务存储，用于测试，可以并发使用。
+// 不保存评论、附件和分享链接，评论数量总是 0；ctx 已结束时直接返回 ctx.Err()
+type MemoryTodoRepository struct {
+	mu         sync.Mutex
+	users      *MemoryUserRepository
+	nextID     uint
+	nextLinkID uint
+	todos      map[uint]models.Todo
+	assignees  []models.TodoAssignee
+	members    map[uint]map[uint]string
+	changes    []models.TodoChange
+}
+
+// NewMemoryTodoRepository 创建空的内存任务存储，
+// users 用来填充创建者和负责人的公开 ID、用户名，可以为 nil
+func NewMemoryTodoRepository(users *MemoryUserRepository) *MemoryTodoRepository {
+	return &MemoryTodoRepository{
+		users:   users,
+		todos:   make(map[uint]models.Todo),
+		members: make(map[uint]map[uint]string),
+	}
+}
+
+// SetMember 设置工作区成员的角色，role 为空表示移出工作区
+func (r *MemoryTodoRepository) SetMember(workspaceID, userID uint, role string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if role == "" {
+		delete(r.members[workspaceID], userID)
+		return
+	}
+	if r.members[workspaceID] == nil {
+		r.members[workspaceID] = make(map[uint]string)
+	}
+	r.members[workspaceID][userID] = role
+}
+
+// Changes 按写入顺序返回变更日志，每个任务只保留最新一条
+func (r *MemoryTodoRepository) Changes() []models.TodoChange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.TodoChange(nil), r.changes...)
+}
+
+func (r *MemoryTodoRepository) List(ctx context.Context, f TodoFilter) ([]models.Todo, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var todos []models.Todo
+	for _, t := range r.todos {
+		if r.matches(t, f) {
+			todos = append(todos, r.load(t))
+		}
+	}
+	sort.Slice(todos, todoLess(todos, f.Sort))
+
+	total := int64(len(todos))
+	if f.Limit > 0 {
+		if f.Offset >= len(todos) {
+			return []models.Todo{}, total, nil
+		}
+		todos = todos[f.Offset:]
+		if len(todos) > f.Limit {
+			todos = todos[:f.Limit]
+		}
+	}
+	return todos, total, nil
+}
+
+// matches 任务是否满足查询条件，和 GORM 实现的 SQL 条件一一对应
+func (r *MemoryTodoRepository) matches(t models.Todo, f TodoFilter) bool {
+	personal := t.UserID == f.UserID && t.WorkspaceID == nil
+	switch {
+	case f.AllWorkspaces:
+		if !personal && (t.WorkspaceID == nil || r.members[*t.WorkspaceID][f.UserID] == "") {
+			return false
+		}
+	case f.WorkspaceID == nil:
+		if !personal {
+			return false
+		}
+	default:
+		if t.WorkspaceID == nil || *t.WorkspaceID != *f.WorkspaceID {
+			return false
+		}
+	}
+	if f.Project != "" && t.Project != f.Project {
+		return false
+	}
+	if f.DueFrom != nil && (t.DueDate == nil || t.DueDate.Before(*f.DueFrom)) {
+		return false
+	}
+	if f.DueBefore != nil && (t.DueDate == nil || !t.DueDate.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.Undone && t.Status {
+		return false
+	}
+	if f.AssigneeID != nil && !r.assigned(t.ID, *f.AssigneeID) {
+		return false
+	}
+	if f.Unassigned && len(r.assigneesOf(t.ID)) > 0 {
+		return false
+	}
+	return true
+}
+
+// todoLess 与 TodoSorts 中的 SQL 排序一致；截止时间为空的任务总是排在最后
+func todoLess(todos []models.Todo, key string) func(i, j int) bool {
+	byDue := func(desc bool) func(i, j int) bool {
+		return func(i, j int) bool {
+			a, b := todos[i].DueDate, todos[j].DueDate
+			switch {
+			case a == nil || b == nil:
+				if (a == nil) != (b == nil) {
+					return b == nil
+				}
+			case !a.Equal(*b):
+				return a.Before(*b) != desc
+			}
+			return todos[i].ID < todos[j].ID
+		}
+	}
+	switch key {
+	case "created_desc":
+		return func(i, j int) bool { return todos[i].ID > todos[j].ID }
+	case "due_asc":
+		return byDue(false)
+	case "due_desc":
+		return byDue(true)
+	case "title_asc":
+		return func(i, j int) bool {
+			if todos[i].Title != todos[j].Title {
+				return todos[i].Title < todos[j].Title
+			}
+			return todos[i].ID < todos[j].ID
+		}
+	default:
+		return func(i, j int) bool { return todos[i].ID < todos[j].ID }
+	}
+}
+
+func (r *MemoryTodoRepository) FindByID(ctx context.Context, id uint) (models.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.todos[id]
+	if !ok {
+		return models.Todo{}, gorm.ErrRecordNotFound
+	}
+	return t, nil
+}
+
+func (r *MemoryTodoRepository) FindByPublicID(ctx context.Context, publicID string) (models.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.todos {
+		if t.PublicID == publicID {
+			return r.load(t), nil
+		}
+	}
+	return models.Todo{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryTodoRepository) Create(ctx context.Context, todo *models.Todo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	todo.ID = r.nextID
+	if todo.PublicID == "" {
+		todo.PublicID = models.NewPublicID()
+	}
+	now := time.Now()
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = now
+	}
+	if todo.UpdatedAt.IsZero() {
+		todo.UpdatedAt = now
+	}
+	r.store(*todo)
+	r.recordChange(*todo, false)
+	todo.CreatorID = r.publicID(todo.UserID)
+	return nil
+}
+
+func (r *MemoryTodoRepository) Update(ctx context.Context, todo *models.Todo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	todo.UpdatedAt = time.Now()
+	r.store(*todo)
+	r.recordChange(*todo, false)
+	todo.CreatorID = r.publicID(todo.UserID)
+	return nil
+}
+
+// store 保存任务本身，负责人、评论数量等关联数据查询时再填充
+func (r *MemoryTodoRepository) store(todo models.Todo) {
+	todo.Assignees = nil
+	todo.CreatorID = ""
+	todo.CommentCount = 0
+	r.todos[todo.ID] = todo
+}
+
+func (r *MemoryTodoRepository) Delete(ctx context.Context, todo models.Todo) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.todos[todo.ID]
+	if !ok {
+		return nil, nil
+	}
+	kept := r.assignees[:0]
+	for _, a := range r.assignees {
+		if a.TodoID != todo.ID {
+			kept = append(kept, a)
+		}
+	}
+	r.assignees = kept
+	r.recordChange(stored, true)
+	delete(r.todos, todo.ID)
+	return nil, nil
+}
+
+func (r *MemoryTodoRepository) AddAssignee(ctx context.Context, assignee models.TodoAssignee) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.assigned(assignee.TodoID, assignee.UserID) {
+		return nil
+	}
+	r.nextLinkID++
+	assignee.ID = r.nextLinkID
+	if assignee.CreatedAt.IsZero() {
+		assignee.CreatedAt = time.Now()
+	}
+	r.assignees = append(r.assignees, assignee)
+	return nil
+}
+
+func (r *MemoryTodoRepository) RemoveAssignee(ctx context.Context, todoID, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, a := range r.assignees {
+		if a.TodoID == todoID && a.UserID == userID {
+			r.assignees = append(r.assignees[:i], r.assignees[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *MemoryTodoRepository) RecordChange(ctx context.Context, todo models.Todo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recordChange(todo, false)
+	return nil
+}
+
+func (r *MemoryTodoRepository) MemberRole(ctx context.Context, workspaceID, userID uint) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role, ok := r.members[workspaceID][userID]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return role, nil
+}
+
+func (r *MemoryTodoRepository) Members(ctx context.Context, workspaceID uint) ([]uint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	userIDs := make([]uint, 0, len(r.members[workspaceID]))
+	for userID := range r.members[workspaceID] {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	return userIDs, nil
+}
+
+// recordChange 和 GORM 实现一样，每个任务只保留最新一条变更
+func (r *MemoryTodoRepository) recordChange(todo models.Todo, deleted bool) {
+	kept := r.changes[:0]
+	var lastID uint
+	for _, c := range r.changes {
+		if c.ID > lastID {
+			lastID = c.ID
+		}
+		if c.TodoID != todo.ID {
+			kept = append(kept, c)
+		}
+	}
+	r.changes = append(kept, models.TodoChange{
+		ID:          lastID + 1,
+		TodoID:      todo.ID,
+		PublicID:    todo.PublicID,
+		ClientID:    todo.ClientID,
+		UserID:      todo.UserID,
+		WorkspaceID: todo.WorkspaceID,
+		Deleted:     deleted,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// load 返回任务的副本，并填充负责人和创建者的公开 ID
+func (r *MemoryTodoRepository) load(t models.Todo) models.Todo {
+	t.Assignees = r.assigneesOf(t.ID)
+	t.CreatorID = r.publicID(t.UserID)
+	return t
+}
+
+func (r *MemoryTodoRepository) assigneesOf(todoID uint) []models.TodoAssignee {
+	var result []models.TodoAssignee
+	for _, a := range r.assignees {
+		if a.TodoID != todoID {
+			continue
+		}
+		if r.users != nil {
+			if u, err := r.users.find(func(u models.User) bool { return u.ID == a.UserID }); err == nil {
+				a.Username = u.Username
+				a.UserPublicID = u.PublicID
+			}
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+func (r *MemoryTodoRepository) assigned(todoID, userID uint) bool {
+	for _, a := range r.assignees {
+		if a.TodoID == todoID && a.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryTodoRepository) publicID(userID uint) string {
+	if r.users == nil {
+		return ""
+	}
+	u, err := r.users.find(func(u models.User) bool { return u.ID == userID })
+	if err != nil {
+		return ""
+	}
+	return u.PublicID
+}
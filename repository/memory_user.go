@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-todo/models"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryUserRepository 保存在内存中的用户存储，用于测试，可以并发使用；ctx 已结束时直接返回 ctx.Err()
+type MemoryUserRepository struct {
+	mu     sync.Mutex
+	nextID uint
+	users  map[uint]models.User
+	// 单独作废的 Token：jti → 过期时间
+	revoked map[string]time.Time
+}
+
+// NewMemoryUserRepository 创建空的内存用户存储
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: make(map[uint]models.User), revoked: make(map[string]time.Time)}
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Username == user.Username {
+			return errors.New("用户名已存在")
+		}
+	}
+	r.nextID++
+	user.ID = r.nextID
+	if user.PublicID == "" {
+		user.PublicID = models.NewPublicID()
+	}
+	// 与数据库的列默认值一致
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	if user.Profile.TimeZone == "" {
+		user.Profile.TimeZone = "UTC"
+	}
+	if user.Profile.Locale == "" {
+		user.Profile.Locale = "zh-CN"
+	}
+	if user.Profile.WeekStart == 0 {
+		user.Profile.WeekStart = 1
+	}
+	if user.Profile.DefaultSort == "" {
+		user.Profile.DefaultSort = DefaultTodoSort
+	}
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id uint) (models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return models.User{}, err
+	}
+	return r.find(func(u models.User) bool { return u.ID == id })
+}
+
+func (r *MemoryUserRepository) FindByPublicID(ctx context.Context, publicID string) (models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return models.User{}, err
+	}
+	return r.find(func(u models.User) bool { return u.PublicID == publicID })
+}
+
+func (r *MemoryUserRepository) FindByUsername(ctx context.Context, username string) (models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return models.User{}, err
+	}
+	return r.find(func(u models.User) bool { return u.Username == username })
+}
+
+func (r *MemoryUserRepository) find(match func(models.User) bool) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if match(u) {
+			return u, nil
+		}
+	}
+	return models.User{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryUserRepository) UpdatePassword(ctx context.Context, id uint, hash string, tokenVersion uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.update(id, func(u *models.User) {
+		u.Password = hash
+		u.MustResetPassword = false
+		u.TokenVersion = tokenVersion
+	})
+}
+
+func (r *MemoryUserRepository) UpdateProfile(ctx context.Context, id uint, profile models.Profile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.update(id, func(u *models.User) { u.Profile = profile })
+}
+
+func (r *MemoryUserRepository) RevokeTokens(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.update(id, func(u *models.User) { u.TokenVersion++ })
+}
+
+func (r *MemoryUserRepository) RevokeToken(ctx context.Context, id uint, tokenID string, expiresAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for jti, exp := range r.revoked {
+		if !exp.After(now) {
+			delete(r.revoked, jti)
+		}
+	}
+	r.revoked[tokenID] = expiresAt
+	return nil
+}
+
+func (r *MemoryUserRepository) TokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.revoked[tokenID]
+	return ok, nil
+}
+
+// Save 直接覆盖保存用户，测试中用来构造禁用、强制改密等状态
+func (r *MemoryUserRepository) Save(user models.User) error {
+	return r.update(user.ID, func(u *models.User) { *u = user })
+}
+
+// update 修改已存在的用户；和 GORM 的 Updates 一样，用户不存在时什么也不做
+func (r *MemoryUserRepository) update(id uint, change func(*models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil
+	}
+	change(&u)
+	u.UpdatedAt = time.Now()
+	r.users[id] = u
+	return nil
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-todo/migrations"
+	"go-todo/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// backend 一套存储实现，addMember 用来准备工作区成员
+type backend struct {
+	name      string
+	users     UserRepository
+	todos     TodoRepository
+	addMember func(workspaceID, userID uint, role string)
+}
+
+// backends GORM（SQLite 内存库）和内存两种实现，同一组测试在两者上运行，保证行为一致
+func backends(t *testing.T) []backend {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrations.New(db)
+	if err == nil {
+		_, err = m.Up()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := NewMemoryUserRepository()
+	todos := NewMemoryTodoRepository(users)
+	return []backend{
+		{
+			name:  "gorm",
+			users: NewGormUserRepository(db),
+			todos: NewGormTodoRepository(db),
+			addMember: func(workspaceID, userID uint, role string) {
+				db.Create(&models.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role})
+			},
+		},
+		{name: "memory", users: users, todos: todos, addMember: todos.SetMember},
+	}
+}
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			user := models.User{Username: "alice", Password: "hash"}
+			if err := b.users.Create(ctx, &user); err != nil {
+				t.Fatal(err)
+			}
+			if user.ID == 0 || user.PublicID == "" {
+				t.Fatalf("创建后应该有内部 ID 和公开 ID: %+v", user)
+			}
+			if err := b.users.Create(ctx, &models.User{Username: "alice"}); err == nil {
+				t.Error("用户名重复时应该返回错误")
+			}
+
+			found, err := b.users.FindByPublicID(ctx, user.PublicID)
+			if err != nil || found.ID != user.ID {
+				t.Fatalf("按公开 ID 查询失败: %v", err)
+			}
+			if found.Role != models.RoleUser || found.Profile.TimeZone != "UTC" || found.Profile.WeekStart != 1 {
+				t.Errorf("应该使用默认的角色和偏好: %+v", found)
+			}
+			if _, err := b.users.FindByUsername(ctx, "bob"); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("期望 ErrRecordNotFound，但得到了 %v", err)
+			}
+
+			if err := b.users.UpdatePassword(ctx, user.ID, "new-hash", 3); err != nil {
+				t.Fatal(err)
+			}
+			// 0 值也要写入
+			if err := b.users.UpdateProfile(ctx, user.ID, models.Profile{TimeZone: "Asia/Shanghai", WeekStart: 0}); err != nil {
+				t.Fatal(err)
+			}
+			found, _ = b.users.FindByID(ctx, user.ID)
+			if found.Password != "new-hash" || found.TokenVersion != 3 {
+				t.Errorf("密码没有更新: %+v", found)
+			}
+			if err := b.users.RevokeTokens(ctx, user.ID); err != nil {
+				t.Fatal(err)
+			}
+			if found, _ = b.users.FindByID(ctx, user.ID); found.TokenVersion != 4 {
+				t.Errorf("期望 Token 版本加一，但得到了 %d", found.TokenVersion)
+			}
+			if found.Profile.TimeZone != "Asia/Shanghai" || found.Profile.WeekStart != 0 {
+				t.Errorf("个人资料没有更新: %+v", found.Profile)
+			}
+
+			// 单独作废一个 Token，过期的记录在下一次作废时被清理
+			if err := b.users.RevokeToken(ctx, user.ID, "expired", time.Now().Add(-time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.users.RevokeToken(ctx, user.ID, "session", time.Now().Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if revoked, err := b.users.TokenRevoked(ctx, "session"); err != nil || !revoked {
+				t.Errorf("期望 Token 已作废，但得到了 %v %v", revoked, err)
+			}
+			if revoked, _ := b.users.TokenRevoked(ctx, "other"); revoked {
+				t.Error("其他 Token 不应该被作废")
+			}
+			if revoked, _ := b.users.TokenRevoked(ctx, "expired"); revoked {
+				t.Error("过期的记录应该被清理")
+			}
+		})
+	}
+}
+
+func TestTodoRepository(t *testing.T) {
+	ctx := context.Background()
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			alice := models.User{Username: "alice"}
+			bob := models.User{Username: "bob"}
+			b.users.Create(ctx, &alice)
+			b.users.Create(ctx, &bob)
+			ws := uint(7)
+			b.addMember(ws, alice.ID, models.WorkspaceOwner)
+
+			base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+			due := func(days int) *time.Time {
+				d := base.AddDate(0, 0, days)
+				return &d
+			}
+			todos := []*models.Todo{
+				{Title: "b", UserID: alice.ID, Project: "工作", DueDate: due(2)},
+				{Title: "a", UserID: alice.ID, DueDate: due(1), Status: true},
+				{Title: "c", UserID: alice.ID},
+				{Title: "d", UserID: bob.ID, WorkspaceID: &ws, DueDate: due(1)},
+				{Title: "e", UserID: bob.ID},
+			}
+			for _, todo := range todos {
+				if err := b.todos.Create(ctx, todo); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if todos[0].CreatorID != alice.PublicID {
+				t.Errorf("创建后应该填充创建者公开 ID，但得到了 %q", todos[0].CreatorID)
+			}
+			if err := b.todos.AddAssignee(ctx, models.TodoAssignee{TodoID: todos[2].ID, UserID: alice.ID}); err != nil {
+				t.Fatal(err)
+			}
+			// 重复添加不报错
+			if err := b.todos.AddAssignee(ctx, models.TodoAssignee{TodoID: todos[2].ID, UserID: alice.ID}); err != nil {
+				t.Fatal(err)
+			}
+
+			titles := func(f TodoFilter) string {
+				t.Helper()
+				list, total, err := b.todos.List(ctx, f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s := fmt.Sprint(total, ":")
+				for _, todo := range list {
+					s += todo.Title
+				}
+				return s
+			}
+			cases := []struct {
+				name string
+				f    TodoFilter
+				want string
+			}{
+				{"个人任务", TodoFilter{UserID: alice.ID}, "3:bac"},
+				{"工作区", TodoFilter{UserID: alice.ID, WorkspaceID: &ws}, "1:d"},
+				{"跨工作区", TodoFilter{UserID: alice.ID, AllWorkspaces: true}, "4:bacd"},
+				{"项目", TodoFilter{UserID: alice.ID, Project: "工作"}, "1:b"},
+				{"截止时间", TodoFilter{UserID: alice.ID, DueFrom: due(1), DueBefore: due(2)}, "1:a"},
+				{"未完成", TodoFilter{UserID: alice.ID, DueBefore: due(3), Undone: true}, "1:b"},
+				{"负责人", TodoFilter{UserID: alice.ID, AssigneeID: &alice.ID}, "1:c"},
+				{"无负责人", TodoFilter{UserID: alice.ID, Unassigned: true}, "2:ba"},
+				{"分页", TodoFilter{UserID: alice.ID, Offset: 1, Limit: 1}, "3:a"},
+				{"超出范围", TodoFilter{UserID: alice.ID, Offset: 5, Limit: 1}, "3:"},
+				{"created_desc", TodoFilter{UserID: alice.ID, Sort: "created_desc"}, "3:cab"},
+				{"due_asc", TodoFilter{UserID: alice.ID, AllWorkspaces: true, Sort: "due_asc"}, "4:adbc"},
+				{"due_desc", TodoFilter{UserID: alice.ID, AllWorkspaces: true, Sort: "due_desc"}, "4:badc"},
+				{"title_asc", TodoFilter{UserID: alice.ID, Sort: "title_asc"}, "3:abc"},
+			}
+			tested := map[string]bool{DefaultTodoSort: true}
+			for _, c := range cases {
+				tested[c.f.Sort] = true
+				if got := titles(c.f); got != c.want {
+					t.Errorf("%s: 期望 %s，但得到了 %s", c.name, c.want, got)
+				}
+			}
+			for key := range TodoSorts {
+				if !tested[key] {
+					t.Errorf("排序 %s 没有测试，新增排序时需要同时实现内存版本", key)
+				}
+			}
+
+			found, err := b.todos.FindByPublicID(ctx, todos[2].PublicID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(found.Assignees) != 1 || found.Assignees[0].Username != "alice" || found.Assignees[0].UserPublicID != alice.PublicID {
+				t.Errorf("应该带上负责人的用户名和公开 ID: %+v", found.Assignees)
+			}
+			if err := b.todos.RemoveAssignee(ctx, todos[2].ID, alice.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			if role, err := b.todos.MemberRole(ctx, ws, alice.ID); err != nil || role != models.WorkspaceOwner {
+				t.Errorf("期望 owner，但得到了 %q %v", role, err)
+			}
+			if _, err := b.todos.MemberRole(ctx, ws, bob.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("不是成员时期望 ErrRecordNotFound，但得到了 %v", err)
+			}
+			if members, _ := b.todos.Members(ctx, ws); len(members) != 1 || members[0] != alice.ID {
+				t.Errorf("成员列表不正确: %v", members)
+			}
+
+			if _, err := b.todos.Delete(ctx, *todos[0]); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := b.todos.FindByPublicID(ctx, todos[0].PublicID); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("删除后期望 ErrRecordNotFound，但得到了 %v", err)
+			}
+			if got := titles(TodoFilter{UserID: alice.ID}); got != "2:ac" {
+				t.Errorf("删除后期望 2:ac，但得到了 %s", got)
+			}
+		})
+	}
+}
+
+// TestRepositoryCanceled ctx 已结束时两种实现都不再执行，返回 ctx.Err()
+func TestRepositoryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			if err := b.users.Create(ctx, &models.User{Username: "alice"}); !errors.Is(err, context.Canceled) {
+				t.Errorf("期望 context.Canceled，但得到了 %v", err)
+			}
+			if _, err := b.users.FindByUsername(context.Background(), "alice"); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("取消的请求不应该写入数据: %v", err)
+			}
+			if _, _, err := b.todos.List(ctx, TodoFilter{UserID: 1}); !errors.Is(err, context.Canceled) {
+				t.Errorf("期望 context.Canceled，但得到了 %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Package repository 封装任务和用户的数据访问。
+// 服务层只依赖这里的接口，生产环境使用 GORM 实现，测试可以使用内存实现；
+// 两种实现找不到记录时都返回 gorm.ErrRecordNotFound，调用方不需要区分
+package repository
+
+import (
+	"context"
+	"go-todo/models"
+	"time"
+)
+
+// TodoSorts 支持的排序方式，key 会出现在用户偏好和查询参数中
+var TodoSorts = map[string]string{
+	"created_asc":  "id ASC",
+	"created_desc": "id DESC",
+	"due_asc":      "due_date IS NULL, due_date ASC, id ASC",
+	"due_desc":     "due_date IS NULL, due_date DESC, id ASC",
+	"title_asc":    "title ASC, id ASC",
+}
+
+// DefaultTodoSort 未指定排序时的默认值（与最早的按 ID 顺序返回保持一致）
+const DefaultTodoSort = "created_asc"
+
+// TodoFilter 任务列表的查询条件，权限校验由服务层在查询之前完成
+type TodoFilter struct {
+	// 当前用户
+	UserID uint
+	// 工作区 ID，为空表示查询个人任务
+	WorkspaceID *uint
+	// 跨工作区查询：个人任务加上所有已加入工作区的任务，此时忽略 WorkspaceID
+	AllWorkspaces bool
+	// 按项目过滤，为空表示不过滤
+	Project string
+	// 按负责人过滤
+	AssigneeID *uint
+	// 只返回没有负责人的任务
+	Unassigned bool
+	// 截止时间范围 [DueFrom, DueBefore)，为空表示不限制
+	DueFrom   *time.Time
+	DueBefore *time.Time
+	// 只返回未完成的任务
+	Undone bool
+	// 排序方式，取值见 TodoSorts
+	Sort string
+	// 分页，Limit 为 0 表示返回全部
+	Offset int
+	Limit  int
+}
+
+// TodoRepository 任务的存储，ctx 结束后放弃执行，返回的错误包含 ctx.Err()
+type TodoRepository interface {
+	// List 按条件查询一页任务和满足条件的总数，结果包含负责人、评论数量和创建者的公开 ID
+	List(ctx context.Context, f TodoFilter) ([]models.Todo, int64, error)
+	// FindByID 按内部 ID 查询任务，不包含关联数据
+	FindByID(ctx context.Context, id uint) (models.Todo, error)
+	// FindByPublicID 按公开 ID 查询任务，结果包含负责人和创建者的公开 ID
+	FindByPublicID(ctx context.Context, publicID string) (models.Todo, error)
+	// Create 保存新任务（不保存负责人），写入变更日志并填充创建者的公开 ID
+	Create(ctx context.Context, todo *models.Todo) error
+	// Update 保存任务的所有字段（不保存负责人），写入变更日志并填充创建者的公开 ID
+	Update(ctx context.Context, todo *models.Todo) error
+	// Delete 删除任务及其负责人、分享链接、评论和附件记录并留下墓碑，
+	// 返回需要从存储中删除的附件文件
+	Delete(ctx context.Context, todo models.Todo) ([]string, error)
+	// AddAssignee 添加负责人，已经是负责人时什么也不做
+	AddAssignee(ctx context.Context, assignee models.TodoAssignee) error
+	// RemoveAssignee 移除负责人
+	RemoveAssignee(ctx context.Context, todoID, userID uint) error
+	// RecordChange 写入一条变更日志，用于负责人这类不修改任务本身的变化
+	RecordChange(ctx context.Context, todo models.Todo) error
+	// MemberRole 用户在工作区中的角色，不是成员时返回 gorm.ErrRecordNotFound
+	MemberRole(ctx context.Context, workspaceID, userID uint) (string, error)
+	// Members 工作区所有成员的用户 ID
+	Members(ctx context.Context, workspaceID uint) ([]uint, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"go-todo/models"
+	"time"
+)
+
+// UserRepository 用户的存储，ctx 结束后放弃执行，返回的错误包含 ctx.Err()
+type UserRepository interface {
+	// Create 保存新用户，用户名已存在时返回错误
+	Create(ctx context.Context, user *models.User) error
+	// FindByID 按内部 ID 查询用户
+	FindByID(ctx context.Context, id uint) (models.User, error)
+	// FindByPublicID 按公开 ID 查询用户
+	FindByPublicID(ctx context.Context, publicID string) (models.User, error)
+	// FindByUsername 按用户名查询用户
+	FindByUsername(ctx context.Context, username string) (models.User, error)
+	// UpdatePassword 保存新的密码哈希和 Token 版本，并清除强制改密标记
+	UpdatePassword(ctx context.Context, id uint, hash string, tokenVersion uint) error
+	// UpdateProfile 保存个人资料与偏好，0 和空字符串也会写入
+	UpdateProfile(ctx context.Context, id uint, profile models.Profile) error
+	// RevokeTokens 把 Token 版本加一，作废之前签发的所有 Token
+	RevokeTokens(ctx context.Context, id uint) error
+	// RevokeToken 只作废 jti 为 tokenID 的一个 Token，记录保留到它过期，
+	// 同时清理这个用户已经过期的记录
+	RevokeToken(ctx context.Context, id uint, tokenID string, expiresAt time.Time) error
+	// TokenRevoked jti 为 tokenID 的 Token 是否已被单独作废
+	TokenRevoked(ctx context.Context, tokenID string) (bool, error)
+}
@@ -1,41 +1,175 @@
 package routes
 
 import (
+	"go-todo/config"
 	"go-todo/controllers" // 导入控制器包
+	"go-todo/metrics"
 	"go-todo/middleware"
+	"go-todo/models"
+	"go-todo/service"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter() *gin.Engine {
+// SetupRouter 创建路由，任务、用户和健康检查相关的控制器使用 main 中创建的服务
+func SetupRouter(todos *service.TodoService, users *service.UserService, health *service.HealthService) *gin.Engine {
+	todoController := controllers.NewTodoController(todos, users)
+	userController := controllers.NewUserController(users)
+	workspaceController := controllers.NewWorkspaceController(users)
+	adminController := controllers.NewAdminController(users)
+	healthController := controllers.NewHealthController(health)
+
     r := gin.New()
+	// 只信任配置的反向代理传来的 X-Forwarded-For，客户端 IP 用于按 IP 限流和日志
+	if err := r.SetTrustedProxies(config.TrustedProxies()); err != nil {
+		panic("🔥 server.trusted_proxies 配置有误: " + err.Error())
+	}
+	// 请求 ID 最先生成，之后的访问日志、SQL 日志都会带上
+	r.Use(middleware.RequestID())
+	// 链路追踪的 server span 包住之后的所有中间件和处理函数
+	r.Use(middleware.Tracing())
 	r.Use(middleware.Logger())
-	r.Use(middleware.Cors())
+	r.Use(middleware.Metrics())
+	// 跨域：按 cors.* 配置允许的来源，登录注册、分享链接和实时事件可以在 cors.groups.<分组> 中单独配置
+	r.Use(middleware.Cors(config.CORS(""),
+		middleware.CorsGroup{Prefix: "/api/v1/auth", Config: config.CORS("auth")},
+		middleware.CorsGroup{Prefix: "/api/v1/public", Config: config.CORS("public")},
+		middleware.CorsGroup{Prefix: "/api/v1/events", Config: config.CORS("events")},
+	))
 	//初始化Gin引擎
-	r.Use(gin.Recovery())
+	r.Use(middleware.Recovery())
 	// 捕获和处理运行时发生的 panic 错误，防止程序因未捕获的 panic 而崩溃，
 	// 转而返回一个标准的 HTTP 500 错误响应给客户端，常用于生产环境确保应用的健壮性。 
 
+	// 请求处理时限，按 server.timeouts.<名称> 配置
+	timeout := func(name string) gin.HandlerFunc {
+		return middleware.Timeout(config.RequestTimeout(name))
+	}
+	// 限流，按 ratelimit.<分组> 配置；放在 AuthMiddleware 之后时按用户限制，否则按 IP
+	limit := func(group string) gin.HandlerFunc {
+		return middleware.RateLimit(group, config.RateLimit(group), config.RateLimiter)
+	}
+	// Cookie 登录：没有 Authorization 头时从会话 Cookie 读取 Token，放在 AuthMiddleware 之前
+	cookieToken := middleware.CookieToken(config.SessionCookie())
+
+	// 健康检查：给负载均衡和编排系统的探针使用，不需要登录
+	r.GET("/healthz", healthController.Healthz)
+	r.GET("/readyz", timeout("health"), healthController.Readyz)
+	// 运行状态：只有管理员可以查看
+	r.GET("/debug/status", timeout("health"), cookieToken, middleware.AuthMiddleware(users), middleware.RequireRole(models.RoleAdmin), healthController.GetStatus)
+	// Prometheus 指标：需要携带 metrics.token，没有配置 token 时只有明确允许才开放
+	if config.MetricsEnabled() {
+		if token := config.MetricsToken(); token != "" || config.MetricsAllowUnauthenticated() {
+			r.GET("/metrics", middleware.MetricsToken(token), gin.WrapH(metrics.Handler()))
+		} else {
+			slog.Warn("未配置 metrics.token，不开放 /metrics；只在内网抓取时可以设置 metrics.allow_unauthenticated=true")
+		}
+	}
+
 	//公开接口（注册 登录）
-	auth := r.Group("/api/v1/auth")
+	auth := r.Group("/api/v1/auth", timeout("auth"), limit("auth"))
 	{
-		auth.POST("/register", controllers.Register)
-        auth.POST("/login", controllers.Login)	
+		auth.POST("/register", userController.Register)
+        auth.POST("/login", userController.Login)	
+		// 退出登录作废 Token，需要登录，Cookie 登录时还要校验 CSRF Token
+		auth.POST("/logout", cookieToken, middleware.AuthMiddleware(users), userController.Logout)
+		auth.POST("/logout-all", cookieToken, middleware.AuthMiddleware(users), userController.LogoutAll)
+	}
+
+	// 公开接口（分享链接），不经过 AuthMiddleware
+	public := r.Group("/api/v1/public", timeout("public"), limit("public"))
+	{
+		public.GET("/shares/:token", controllers.GetSharedContent)
+		public.GET("/shares/:token/todos/:todoID/comments", controllers.GetSharedComments)
+		public.POST("/shares/:token/todos/:todoID/comments", controllers.CreateSharedComment)
+	}
+
+	// 实时事件流：浏览器的 EventSource/WebSocket 不能设置请求头，允许用查询参数传 Token；
+	// 长连接不设置处理时限
+	stream := r.Group("/api/v1/events", middleware.QueryToken(), cookieToken, middleware.AuthMiddleware(users), limit("api"))
+	{
+		stream.GET("", controllers.StreamEvents)
+		stream.GET("/ws", controllers.StreamEventsWS)
 	}
 
     v1 := r.Group("/api/v1")//路由分组
 	//前缀管理：在这个组下面定义的路由，都会自动带上/api/v1
 	//版本控制
-	v1.Use(middleware.AuthMiddleware())
+	v1.Use(timeout("api"), cookieToken, middleware.AuthMiddleware(users), limit("api"), middleware.Idempotency())
     {
         // 这里的 controllers.GetTodos 对应上面定义的函数
-        v1.POST("/todos", controllers.CreateTask)
-		v1.GET("/todos", controllers.GetTodos)
-		v1.GET("/todos/:id", controllers.GetTodo)     // 查询单个
-    	v1.DELETE("/todos/:id", controllers.DeleteTodo) // 删除
-		v1.PUT("/todos/:id",controllers.UpdateTodo)
+        v1.POST("/todos", todoController.CreateTask)
+		v1.GET("/todos", todoController.GetTodos)
+		v1.GET("/todos/:id", todoController.GetTodo)     // 查询单个
+    	v1.DELETE("/todos/:id", todoController.DeleteTodo) // 删除
+		v1.PUT("/todos/:id",todoController.UpdateTodo)
+		v1.POST("/todos/:id/assignees", todoController.AssignTodo)
+		v1.DELETE("/todos/:id/assignees/:userID", todoController.UnassignTodo)
+		v1.GET("/todos/:id/comments", controllers.GetComments)
+		v1.POST("/todos/:id/comments", controllers.CreateComment)
+		v1.PUT("/todos/:id/comments/:commentID", controllers.UpdateComment)
+		v1.DELETE("/todos/:id/comments/:commentID", controllers.DeleteComment)
+		v1.GET("/todos/:id/attachments", controllers.GetAttachments)
+		v1.POST("/todos/:id/attachments", timeout("attachments"), controllers.UploadAttachment)
+		v1.GET("/todos/:id/attachments/:attachmentID", timeout("attachments"), controllers.DownloadAttachment)
+		v1.DELETE("/todos/:id/attachments/:attachmentID", controllers.DeleteAttachment)
+
+		// 离线客户端增量同步
+		v1.GET("/sync", controllers.GetSync)
+		v1.POST("/sync", controllers.PostSync)
+
+		// 当前用户
+		v1.GET("/me", userController.GetMe)
+		v1.PUT("/me/profile", userController.UpdateProfile)
+		v1.PUT("/me/password", userController.ChangePassword)
+		v1.GET("/me/assigned", todoController.GetMyAssigned)
+		v1.GET("/me/export", timeout("export"), controllers.ExportMe)
+		v1.DELETE("/me", controllers.DeleteMe)
+
+		// 工作区
+		v1.POST("/workspaces", workspaceController.CreateWorkspace)
+		v1.GET("/workspaces", workspaceController.GetWorkspaces)
+		v1.GET("/workspaces/:id", workspaceController.GetWorkspace)
+		v1.PUT("/workspaces/:id", workspaceController.UpdateWorkspace)
+		v1.DELETE("/workspaces/:id", workspaceController.DeleteWorkspace)
+		v1.GET("/workspaces/:id/members", workspaceController.GetWorkspaceMembers)
+		v1.PUT("/workspaces/:id/members/:userID", workspaceController.SetWorkspaceMemberRole)
+		v1.DELETE("/workspaces/:id/members/:userID", workspaceController.RemoveWorkspaceMember)
+		v1.POST("/workspaces/:id/invites", workspaceController.CreateWorkspaceInvite)
+		v1.GET("/workspaces/:id/invites", workspaceController.GetWorkspaceInvites)
+		v1.DELETE("/workspaces/:id/invites/:inviteID", workspaceController.RevokeWorkspaceInvite)
+		v1.GET("/invites", workspaceController.GetMyInvites)
+		v1.POST("/invites/:token/accept", workspaceController.AcceptInvite)
+		v1.POST("/invites/:token/decline", workspaceController.DeclineInvite)
+
+		// 分享链接
+		v1.POST("/shares", controllers.CreateShare)
+		v1.GET("/shares", controllers.GetShares)
+		v1.DELETE("/shares/:id", controllers.RevokeShare)
+
+		// Webhook
+		v1.POST("/webhooks", controllers.CreateWebhook)
+		v1.GET("/webhooks", controllers.GetWebhooks)
+		v1.GET("/webhooks/:id", controllers.GetWebhook)
+		v1.PUT("/webhooks/:id", controllers.UpdateWebhook)
+		v1.DELETE("/webhooks/:id", controllers.DeleteWebhook)
+		v1.GET("/webhooks/:id/deliveries", controllers.GetWebhookDeliveries)
+		v1.POST("/webhooks/:id/test", controllers.TestWebhook)
 
     }
 
+	// 管理员接口：在登录校验之后再校验角色
+	admin := v1.Group("/admin")
+	admin.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		admin.GET("/users", adminController.ListUsers)
+		admin.POST("/users/:id/disable", adminController.DisableUser)
+		admin.POST("/users/:id/enable", adminController.EnableUser)
+		admin.PUT("/users/:id/role", adminController.SetUserRole)
+		admin.POST("/users/:id/force-password-reset", adminController.ForcePasswordReset)
+		admin.GET("/stats", adminController.GetUsageStats)
+	}
+
     return r
 }
\ No newline at end of file
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go-todo/config"
+	"go-todo/service"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// serve 启动 HTTP 服务和后台任务，直到收到 SIGINT/SIGTERM：
+// 先停止接收新请求并在 server.shutdown_timeout 内等待进行中的请求完成，
+// 再停止后台任务（请求中产生的 Webhook 投递已经写入队列），然后关闭数据库连接池，最后发送剩余的链路追踪数据
+func serve(handler http.Handler, workers *service.Workers) error {
+	cfg := config.Server()
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	// Shutdown 不会等待事件流这种长连接结束，先把它们关掉
+	srv.RegisterOnShutdown(config.Events.Close)
+
+	useTLS := cfg.TLSCertFile != "" || cfg.TLSKeyFile != ""
+	if useTLS {
+		certs, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: certs.GetCertificate}
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workers.Start(workerCtx)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if useTLS {
+			serveErr <- srv.ListenAndServeTLS("", "")
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+	slog.Info("服务已启动", "addr", cfg.Addr, "tls", useTLS)
+
+	var err error
+	select {
+	case err = <-serveErr:
+		// 监听失败（如端口被占用），没有进行中的请求需要等待
+	case <-ctx.Done():
+		stop() // 再次收到信号时直接退出
+		slog.Info("收到退出信号，等待进行中的请求完成", "timeout", cfg.ShutdownTimeout.String())
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		if err = srv.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("等待请求完成超时，强制关闭剩余连接", "error", err)
+			srv.Close()
+		}
+	}
+
+	stopWorkers()
+	workers.Wait()
+	if closeErr := config.CloseDatabase(); closeErr != nil {
+		slog.Error("关闭数据库连接失败", "error", closeErr)
+	}
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if flushErr := config.ShutdownTracing(flushCtx); flushErr != nil {
+		slog.Error("发送链路追踪数据失败", "error", flushErr)
+	}
+	if errors.Is(err, http.ErrServerClosed) || errors.Is(err, context.DeadlineExceeded) {
+		err = nil
+	}
+	if err == nil {
+		slog.Info("服务已退出")
+	}
+	return err
+}
+
+// certCheckInterval 检查证书文件是否变化的最短间隔
+var certCheckInterval = 10 * time.Second
+
+// certReloader 在 TLS 握手时提供证书，证书或私钥文件变化后自动重新加载，
+// 证书续期不需要重启服务；新文件加载失败时继续使用旧证书
+type certReloader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// newCertReloader 加载证书，文件不存在或不匹配时返回错误
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("server.tls.cert_file 和 server.tls.key_file 需要同时配置")
+	}
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	modTime, err := r.latestModTime()
+	if err == nil {
+		err = r.load(modTime)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("加载 TLS 证书失败: %w", err)
+	}
+	return r, nil
+}
+
+// GetCertificate 用作 tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checked) >= certCheckInterval {
+		r.checked = time.Now()
+		if modTime, err := r.latestModTime(); err == nil && !modTime.Equal(r.modTime) {
+			if err := r.load(modTime); err != nil {
+				slog.Error("重新加载 TLS 证书失败，继续使用旧证书", "error", err)
+			} else {
+				slog.Info("TLS 证书已重新加载")
+			}
+		}
+	}
+	return r.cert, nil
+}
+
+// latestModTime 证书和私钥文件中较晚的修改时间
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert, r.modTime, r.checked = &cert, modTime, time.Now()
+	return nil
+}
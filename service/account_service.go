@@ -0,0 +1,247 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-todo/config"
+	"go-todo/models"
+	"go-todo/repository"
+	"go-todo/storage"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// AccountService 处理个人数据导出和账号注销
+type AccountService struct{}
+
+// exportFile 导出压缩包中的一个文件
+type exportFile struct {
+	Name string
+	Data interface{}
+}
+
+// Export 把用户的全部数据打包成 ZIP，每类数据一个 JSON 文件
+func (s *AccountService) Export(ctx context.Context, userID uint) ([]byte, error) {
+	var user models.User
+	if err := config.DB.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	var todos []models.Todo
+	err := config.DB.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	if err = repository.FillCreators(config.DB.WithContext(ctx), todos); err != nil {
+		return nil, err
+	}
+
+	var workspaces []models.Workspace
+	var wsService WorkspaceService
+	if workspaces, err = wsService.List(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var assignments []models.TodoAssignee
+	err = config.DB.WithContext(ctx).Select("todo_assignees.*, todos.public_id AS todo_public_id").
+		Joins("JOIN todos ON todos.id = todo_assignees.todo_id").
+		Where("todo_assignees.user_id = ?", userID).Order("todo_assignees.id ASC").Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var shares []models.ShareLink
+	var shareService ShareService
+	if shares, err = shareService.List(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var comments []models.Comment
+	err = config.DB.WithContext(ctx).Select("comments.*, users.public_id AS author_public_id, todos.public_id AS todo_public_id").
+		Joins("JOIN users ON users.id = comments.author_id").
+		Joins("JOIN todos ON todos.id = comments.todo_id").
+		Where("comments.author_id = ?", userID).Preload("Mentions").Order("comments.id ASC").Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []models.Attachment
+	err = config.DB.WithContext(ctx).Select("attachments.*, todos.public_id AS todo_public_id").
+		Joins("JOIN todos ON todos.id = attachments.todo_id").
+		Where("attachments.uploader_id = ?", userID).Order("attachments.id ASC").Find(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var webhooks []models.Webhook
+	var hookService WebhookService
+	if webhooks, err = hookService.List(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	files := []exportFile{
+		{Name: "profile.json", Data: map[string]interface{}{
+			"id":         user.PublicID,
+			"username":   user.Username,
+			"created_at": user.CreatedAt,
+			"updated_at": user.UpdatedAt,
+			"profile":    user.Profile,
+		}},
+		{Name: "todos.json", Data: todos},
+		{Name: "workspaces.json", Data: workspaces},
+		{Name: "assignments.json", Data: assignments},
+		{Name: "share_links.json", Data: shares},
+		{Name: "comments.json", Data: comments},
+		{Name: "attachments.json", Data: attachments},
+		{Name: "webhooks.json", Data: webhooks},
+	}
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	files = append([]exportFile{{Name: "manifest.json", Data: map[string]interface{}{
+		"user_id":     user.PublicID,
+		"exported_at": time.Now(),
+		"files":       names,
+	}}}, files...)
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, f := range files {
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.Data); err != nil {
+			return nil, err
+		}
+	}
+	// 附件文件本身放在 attachments/<附件ID>_<文件名>
+	for _, a := range attachments {
+		if err := exportBlob(ctx, zw, fmt.Sprintf("attachments/%d_%s", a.ID, a.Filename), a.StorageKey); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// exportBlob 把存储中的一个文件写入压缩包；文件已丢失时跳过
+func exportBlob(ctx context.Context, zw *zip.Writer, name, key string) error {
+	rc, err := config.Storage.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, rc)
+	return err
+}
+
+// Delete 校验密码后彻底删除用户及其所有数据（不是软删除）
+// 用户记录被删除后，AuthMiddleware 的 CheckToken 会拒绝该用户所有已签发的 Token。
+// 只有自己一个成员的工作区会被一起删除；仍有其他成员的工作区需要先转让，
+// 用户在共享工作区中创建的任务属于工作区，会被保留并转给工作区的拥有者
+func (s *AccountService) Delete(ctx context.Context, userID uint, password string) error {
+	var user models.User
+	if err := config.DB.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrWrongPassword
+	}
+
+	var owned []models.Workspace
+	if err := config.DB.WithContext(ctx).Where("owner_id = ?", userID).Find(&owned).Error; err != nil {
+		return err
+	}
+	for _, ws := range owned {
+		var members int64
+		if err := config.DB.WithContext(ctx).Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND user_id <> ?", ws.ID, userID).Count(&members).Error; err != nil {
+			return err
+		}
+		if members > 0 {
+			return ErrOwnsSharedWorkspace
+		}
+	}
+
+	var blobs []string
+	err := config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, ws := range owned {
+			keys, err := deleteWorkspace(tx, ws.ID)
+			if err != nil {
+				return err
+			}
+			blobs = append(blobs, keys...)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("invitee_id = ?", userID).Delete(&models.WorkspaceInvite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TodoAssignee{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("owner_id = ?", userID).Delete(&models.ShareLink{}).Error; err != nil {
+			return err
+		}
+		if err := repository.DeleteComments(tx, "author_id = ?", userID); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.CommentMention{}).Error; err != nil {
+			return err
+		}
+		if err := deleteWebhooks(tx, "user_id = ?", userID); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.IdempotencyKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RevokedToken{}).Error; err != nil {
+			return err
+		}
+		var todoIDs []uint
+		if err := tx.Model(&models.Todo{}).Where("user_id = ? AND workspace_id IS NULL", userID).Pluck("id", &todoIDs).Error; err != nil {
+			return err
+		}
+		keys, err := repository.DeleteTodos(tx, todoIDs)
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, keys...)
+		// 个人任务的墓碑和单独发给本人的变更只有本人能看到，一起删除
+		if err := tx.Where("(user_id = ? AND workspace_id IS NULL) OR recipient_id = ?", userID, userID).Delete(&models.TodoChange{}).Error; err != nil {
+			return err
+		}
+		// 共享工作区中的任务转给工作区的拥有者，否则删除用户会违反任务的外键约束
+		owner := tx.Model(&models.Workspace{}).Select("owner_id").Where("workspaces.id = todos.workspace_id")
+		if err := tx.Model(&models.Todo{}).Where("user_id = ? AND workspace_id IS NOT NULL", userID).UpdateColumn("user_id", owner).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, userID).Error
+	})
+	if err != nil {
+		return err
+	}
+	removeBlobs(ctx, blobs)
+	return nil
+}
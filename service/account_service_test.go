@@ -0,0 +1,121 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"go-todo/common"
+	"go-todo/config"
+	"go-todo/models"
+)
+
+// createTestUser 注册一个用户并返回它
+func createTestUser(t *testing.T, username string) models.User {
+	t.Helper()
+	us := newTestUserService(config.DB)
+	if err := us.Register(t.Context(), username, "password123"); err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+	var user models.User
+	config.DB.Where("username = ?", username).First(&user)
+	return user
+}
+
+// TestExport 测试导出的 ZIP 中包含个人资料和任务
+func TestExport(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &AccountService{}
+
+	user := createTestUser(t, "alice")
+	db.Create(&models.Todo{Title: "任务1", UserID: user.ID})
+
+	data, err := s.Export(t.Context(), user.ID)
+	if err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("导出的内容不是合法的 ZIP: %v", err)
+	}
+
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	for _, want := range []string{"manifest.json", "profile.json", "todos.json"} {
+		if !names[want] {
+			t.Errorf("期望导出包含 %s，但没有找到", want)
+		}
+	}
+}
+
+// TestDelete_Account 测试注销账号会删除用户、任务并使 Token 失效
+func TestDelete_Account(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &AccountService{}
+	us := newTestUserService(db)
+
+	user := createTestUser(t, "alice")
+	other := createTestUser(t, "bob")
+	db.Create(&models.Todo{Title: "alice 的任务", UserID: user.ID})
+	db.Create(&models.Todo{Title: "bob 的任务", UserID: other.ID})
+	claims := &common.MyCustomClaims{UserID: user.PublicID}
+
+	if err := s.Delete(t.Context(), user.ID, "wrong"); err != ErrWrongPassword {
+		t.Fatalf("期望密码错误时返回 ErrWrongPassword，但得到了 %v", err)
+	}
+	if _, err := us.CheckToken(t.Context(), claims); err != nil {
+		t.Fatalf("密码错误时不应该删除账号: %v", err)
+	}
+
+	if err := s.Delete(t.Context(), user.ID, "password123"); err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+
+	var count int64
+	db.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Count(&count)
+	if count != 0 {
+		t.Error("期望用户记录被彻底删除")
+	}
+	db.Model(&models.Todo{}).Where("user_id = ?", user.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("期望用户的任务被删除，但还剩 %d 条", count)
+	}
+	db.Model(&models.Todo{}).Where("user_id = ?", other.ID).Count(&count)
+	if count != 1 {
+		t.Error("不应该删除其他用户的任务")
+	}
+	if _, err := us.CheckToken(t.Context(), claims); err == nil {
+		t.Error("期望注销后 Token 失效")
+	}
+}
+
+// TestDelete_AccountWithSharedTodos 在别人的工作区中创建过任务的用户也可以注销，任务转给工作区的拥有者
+func TestDelete_AccountWithSharedTodos(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &AccountService{}
+	ws := &WorkspaceService{}
+	ts := newTestTodoService(db)
+
+	owner := createTestUser(t, "owner")
+	member := createTestUser(t, "member")
+	workspace, _ := ws.Create(t.Context(), owner.ID, "研发组")
+	invite, _ := ws.Invite(t.Context(), owner.ID, workspace.ID, "member", models.WorkspaceEditor, 0)
+	ws.AcceptInvite(t.Context(), member.ID, invite.Token)
+	todo := &models.Todo{Title: "共享任务", WorkspaceID: &workspace.ID}
+	if err := ts.Create(t.Context(), member.ID, todo); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Delete(t.Context(), member.ID, "password123"); err != nil {
+		t.Fatalf("期望可以注销，但得到了 %v", err)
+	}
+	saved, err := ts.GetByID(t.Context(), owner.ID, todo.PublicID)
+	if err != nil || saved.UserID != owner.ID {
+		t.Errorf("期望共享任务保留并转给拥有者，但得到了 %+v, %v", saved, err)
+	}
+}
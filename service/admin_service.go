@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"go-todo/config"
+	"go-todo/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AdminService 管理员对用户的管理操作
+type AdminService struct{}
+
+// UserSummary 管理后台的用户列表项
+type UserSummary struct {
+	ID                string    `json:"id" example:"01928f3a-5a0b-7c1d-8e2f-3a4b5c6d7e8f"`
+	Username          string    `json:"username" example:"john_doe"`
+	DisplayName       string    `json:"display_name" example:"John"`
+	Role              string    `json:"role" example:"user"`
+	Disabled          bool      `json:"disabled" example:"false"`
+	MustResetPassword bool      `json:"must_reset_password" example:"false"`
+	TodoCount         int64     `json:"todo_count" example:"12"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// UsageStats 系统使用统计
+type UsageStats struct {
+	Users          int64 `json:"users" example:"100"`
+	DisabledUsers  int64 `json:"disabled_users" example:"2"`
+	Admins         int64 `json:"admins" example:"1"`
+	NewUsers7d     int64 `json:"new_users_7d" example:"5"`
+	Todos          int64 `json:"todos" example:"1200"`
+	CompletedTodos int64 `json:"completed_todos" example:"800"`
+	NewTodos7d     int64 `json:"new_todos_7d" example:"90"`
+}
+
+// escapeLike 转义 LIKE 的通配符，配合 ESCAPE '!' 使用（MySQL 默认把反斜杠当作字符串转义，不用它）
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// ListUsers 分页列出用户，keyword 按用户名或显示名称模糊搜索，每页最多 MaxPageSize 条
+func (s *AdminService) ListUsers(keyword string, page, pageSize int) ([]UserSummary, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	query := config.DB.Model(&models.User{})
+	if keyword != "" {
+		// PostgreSQL 的 LIKE 区分大小写，统一转成小写比较，三种数据库的结果一致；
+		// 关键字中的 % 和 _ 按普通字符匹配
+		like := "%" + escapeLike(strings.ToLower(keyword)) + "%"
+		query = query.Where("LOWER(username) LIKE ? ESCAPE '!' OR LOWER(display_name) LIKE ? ESCAPE '!'", like, like)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	if err := query.Order("id ASC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 一次性统计这一页用户的任务数量
+	ids := make([]uint, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	var counts []struct {
+		UserID uint
+		Count  int64
+	}
+	if len(ids) > 0 {
+		err := config.DB.Model(&models.Todo{}).Select("user_id, COUNT(*) AS count").
+			Where("user_id IN ?", ids).Group("user_id").Scan(&counts).Error
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	countByUser := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		countByUser[c.UserID] = c.Count
+	}
+
+	result := make([]UserSummary, 0, len(users))
+	for _, u := range users {
+		result = append(result, UserSummary{
+			ID:                u.PublicID,
+			Username:          u.Username,
+			DisplayName:       u.Profile.DisplayName,
+			Role:              u.Role,
+			Disabled:          u.Disabled,
+			MustResetPassword: u.MustResetPassword,
+			TodoCount:         countByUser[u.ID],
+			CreatedAt:         u.CreatedAt,
+		})
+	}
+	return result, total, nil
+}
+
+// SetDisabled 禁用或启用账号，管理员不能禁用自己
+func (s *AdminService) SetDisabled(adminID, userID uint, disabled bool) error {
+	if adminID == userID {
+		return errors.New("不能禁用或启用自己的账号")
+	}
+	return updateUser(userID, map[string]interface{}{"disabled": disabled})
+}
+
+// SetRole 修改用户角色，管理员不能修改自己的角色
+func (s *AdminService) SetRole(adminID, userID uint, role string) error {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return errors.New("不支持的角色: " + role)
+	}
+	if adminID == userID {
+		return errors.New("不能修改自己的角色")
+	}
+	return updateUser(userID, map[string]interface{}{"role": role})
+}
+
+// ForcePasswordReset 强制用户重置密码：作废所有 Token，重新登录后只能先修改密码
+func (s *AdminService) ForcePasswordReset(userID uint) error {
+	return updateUser(userID, map[string]interface{}{
+		"must_reset_password": true,
+		"token_version":       gorm.Expr("token_version + 1"),
+	})
+}
+
+// Promote 把指定用户名设为管理员，用于初始化第一个管理员
+func (s *AdminService) Promote(username string) error {
+	return config.DB.Model(&models.User{}).Where("username = ?", username).Update("role", models.RoleAdmin).Error
+}
+
+// Stats 统计系统使用情况
+func (s *AdminService) Stats() (UsageStats, error) {
+	var stats UsageStats
+	weekAgo := time.Now().AddDate(0, 0, -7)
+
+	counts := []struct {
+		dest  *int64
+		query *gorm.DB
+	}{
+		{&stats.Users, config.DB.Model(&models.User{})},
+		{&stats.DisabledUsers, config.DB.Model(&models.User{}).Where("disabled = ?", true)},
+		{&stats.Admins, config.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin)},
+		{&stats.NewUsers7d, config.DB.Model(&models.User{}).Where("created_at >= ?", weekAgo)},
+		{&stats.Todos, config.DB.Model(&models.Todo{})},
+		{&stats.CompletedTodos, config.DB.Model(&models.Todo{}).Where("status = ?", true)},
+		{&stats.NewTodos7d, config.DB.Model(&models.Todo{}).Where("created_at >= ?", weekAgo)},
+	}
+	for _, c := range counts {
+		if err := c.query.Count(c.dest).Error; err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// updateUser 更新用户的部分字段，用户不存在时返回错误
+func updateUser(userID uint, fields map[string]interface{}) error {
+	var user models.User
+	if err := config.DB.Select("id").First(&user, userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+	return config.DB.Model(&user).Updates(fields).Error
+}
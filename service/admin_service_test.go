@@ -0,0 +1,138 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"go-todo/common"
+	"go-todo/config"
+	"go-todo/models"
+)
+
+// TestListUsers 测试搜索用户并统计任务数
+func TestListUsers(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &AdminService{}
+
+	alice := createTestUser(t, "alice")
+	createTestUser(t, "bob")
+	db.Create(&models.Todo{Title: "任务1", UserID: alice.ID})
+	db.Create(&models.Todo{Title: "任务2", UserID: alice.ID})
+
+	users, total, err := s.ListUsers("ali", 1, 10)
+	if err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+	if total != 1 || len(users) != 1 || users[0].Username != "alice" {
+		t.Fatalf("期望只搜索到 alice，但得到了 %+v", users)
+	}
+	if users[0].TodoCount != 2 {
+		t.Errorf("期望 alice 有 2 条任务，但得到了 %d", users[0].TodoCount)
+	}
+
+	// 通配符按普通字符匹配
+	createTestUser(t, "a_ice")
+	if users, _, _ := s.ListUsers("a_i", 1, 10); len(users) != 1 || users[0].Username != "a_ice" {
+		t.Errorf("期望 _ 不作为通配符，但得到了 %+v", users)
+	}
+	if _, total, _ := s.ListUsers("%", 1, 10); total != 0 {
+		t.Errorf("期望 %% 不作为通配符，但搜索到了 %d 个用户", total)
+	}
+
+	for i := 0; i < MaxPageSize+5; i++ {
+		db.Create(&models.User{Username: "user" + strconv.Itoa(i)})
+	}
+	if users, _, _ := s.ListUsers("", 1, 1000); len(users) != MaxPageSize {
+		t.Errorf("期望每页最多 %d 条，但得到了 %d 条", MaxPageSize, len(users))
+	}
+}
+
+// TestSetDisabled 测试禁用账号后无法登录，且已签发的 Token 失效
+func TestSetDisabled(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &AdminService{}
+	us := newTestUserService(db)
+
+	admin := createTestUser(t, "admin")
+	alice := createTestUser(t, "alice")
+
+	if err := s.SetDisabled(admin.ID, admin.ID, true); err == nil {
+		t.Error("期望管理员不能禁用自己")
+	}
+	if err := s.SetDisabled(admin.ID, 999, true); err != ErrUserNotFound {
+		t.Errorf("期望返回 ErrUserNotFound，但得到了 %v", err)
+	}
+
+	if err := s.SetDisabled(admin.ID, alice.ID, true); err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+	if _, err := us.Login(t.Context(), "alice", "password123"); err != ErrUserDisabled {
+		t.Errorf("期望被禁用的用户无法登录，但得到了 %v", err)
+	}
+	if _, err := us.CheckToken(t.Context(), &common.MyCustomClaims{UserID: alice.PublicID}); err != ErrUserDisabled {
+		t.Errorf("期望被禁用用户的 Token 失效，但得到了 %v", err)
+	}
+
+	if err := s.SetDisabled(admin.ID, alice.ID, false); err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+	if _, err := us.Login(t.Context(), "alice", "password123"); err != nil {
+		t.Errorf("期望启用后可以登录，但得到了 %v", err)
+	}
+}
+
+// TestForcePasswordReset 测试强制重置密码会作废旧 Token，修改密码后恢复正常
+func TestForcePasswordReset(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &AdminService{}
+	us := newTestUserService(db)
+
+	alice := createTestUser(t, "alice")
+	oldClaims := &common.MyCustomClaims{UserID: alice.PublicID, TokenVersion: alice.TokenVersion}
+
+	if err := s.ForcePasswordReset(alice.ID); err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+	if _, err := us.CheckToken(t.Context(), oldClaims); err == nil {
+		t.Error("期望强制重置后旧 Token 失效")
+	}
+
+	user, _ := us.GetByID(t.Context(), alice.ID)
+	if !user.MustResetPassword {
+		t.Fatal("期望用户被标记为需要修改密码")
+	}
+
+	token, err := us.ChangePassword(t.Context(), alice.ID, "password123", "newpassword")
+	if err != nil {
+		t.Fatalf("修改密码失败: %v", err)
+	}
+	claims, _ := common.ParseToken(token)
+	user, err = us.CheckToken(t.Context(), claims)
+	if err != nil || user.MustResetPassword {
+		t.Errorf("期望修改密码后新 Token 可用且不再需要改密，得到 %+v, %v", user, err)
+	}
+}
+
+// TestStats 测试使用统计
+func TestStats(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &AdminService{}
+
+	alice := createTestUser(t, "alice")
+	createTestUser(t, "bob")
+	s.Promote("alice")
+	db.Create(&models.Todo{Title: "任务1", Status: true, UserID: alice.ID})
+	db.Create(&models.Todo{Title: "任务2", UserID: alice.ID})
+
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+	if stats.Users != 2 || stats.Admins != 1 || stats.Todos != 2 || stats.CompletedTodos != 1 {
+		t.Errorf("统计结果不正确: %+v", stats)
+	}
+}
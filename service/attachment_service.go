@@ -0,0 +1,154 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go-todo/config"
+	"go-todo/models"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// AttachmentService 任务附件的上传、下载和删除
+type AttachmentService struct{}
+
+// List 列出任务的附件
+func (s *AttachmentService) List(ctx context.Context, userID uint, todoID string) ([]models.Attachment, error) {
+	ts := defaultTodoService()
+	todo, err := ts.GetByID(ctx, userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	var attachments []models.Attachment
+	err = config.DB.WithContext(ctx).Where("todo_id = ?", todo.ID).Order("id ASC").Find(&attachments).Error
+	return attachments, err
+}
+
+// Upload 上传附件，需要任务的编辑权限；文件类型根据内容识别，而不是相信客户端
+func (s *AttachmentService) Upload(ctx context.Context, userID uint, todoID string, filename string, r io.Reader, size int64) (models.Attachment, error) {
+	var attachment models.Attachment
+	ts := defaultTodoService()
+	todo, err := ts.GetByID(ctx, userID, todoID)
+	if err != nil {
+		return attachment, err
+	}
+	if err := ts.authorize(ctx, userID, todo, true); err != nil {
+		return attachment, err
+	}
+
+	if max := config.AttachmentMaxSize(); size > max {
+		return attachment, fmt.Errorf("%w（最大 %d 字节）", ErrFileTooLarge, max)
+	}
+
+	// 读取文件头识别 MIME 类型
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return attachment, err
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !allowedType(contentType) {
+		return attachment, fmt.Errorf("%w: %s", ErrFileType, contentType)
+	}
+
+	token, err := randomToken(16)
+	if err != nil {
+		return attachment, err
+	}
+	attachment = models.Attachment{
+		TodoID:      todo.ID,
+		UploaderID:  userID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  fmt.Sprintf("todos/%d/%s", todo.ID, token),
+	}
+
+	if err := config.Storage.Put(ctx, attachment.StorageKey, io.MultiReader(bytes.NewReader(head), r), size, contentType); err != nil {
+		return attachment, err
+	}
+	if err := config.DB.WithContext(ctx).Create(&attachment).Error; err != nil {
+		// 请求超时导致保存失败时也要删掉已经上传的文件
+		config.Storage.Delete(context.WithoutCancel(ctx), attachment.StorageKey)
+		return attachment, err
+	}
+	return attachment, nil
+}
+
+// Open 打开附件用于下载，调用方负责关闭返回的 ReadCloser
+func (s *AttachmentService) Open(ctx context.Context, userID uint, todoID string, attachmentID uint) (models.Attachment, io.ReadCloser, error) {
+	attachment, _, err := findAttachment(ctx, userID, todoID, attachmentID)
+	if err != nil {
+		return attachment, nil, err
+	}
+	rc, err := config.Storage.Get(ctx, attachment.StorageKey)
+	return attachment, rc, err
+}
+
+// Delete 删除附件，需要任务的编辑权限
+func (s *AttachmentService) Delete(ctx context.Context, userID uint, todoID string, attachmentID uint) error {
+	attachment, todo, err := findAttachment(ctx, userID, todoID, attachmentID)
+	if err != nil {
+		return err
+	}
+	ts := defaultTodoService()
+	if err := ts.authorize(ctx, userID, todo, true); err != nil {
+		return err
+	}
+	if err := config.DB.WithContext(ctx).Delete(&attachment).Error; err != nil {
+		return err
+	}
+	removeBlobs(ctx, []string{attachment.StorageKey})
+	return nil
+}
+
+// findAttachment 查找任务下的附件，同时校验用户能否查看该任务
+func findAttachment(ctx context.Context, userID uint, todoID string, attachmentID uint) (models.Attachment, models.Todo, error) {
+	var attachment models.Attachment
+	ts := defaultTodoService()
+	todo, err := ts.GetByID(ctx, userID, todoID)
+	if err != nil {
+		return attachment, todo, err
+	}
+	err = config.DB.WithContext(ctx).Where("todo_id = ?", todo.ID).First(&attachment, attachmentID).Error
+	return attachment, todo, err
+}
+
+// removeBlobs 删除存储中的文件；失败只记录日志，留下的孤儿文件不影响业务。
+// 数据库记录已经删除，请求超时或客户端断开也要继续删除文件
+func removeBlobs(ctx context.Context, keys []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		if err := config.Storage.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "删除附件文件失败", "key", key, "error", err)
+		}
+	}
+}
+
+func allowedType(contentType string) bool {
+	for _, t := range config.AttachmentAllowedTypes() {
+		if strings.EqualFold(t, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFilename 只保留文件名部分，避免路径字符
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		name = "file"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
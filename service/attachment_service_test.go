@@ -0,0 +1,86 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go-todo/config"
+	"go-todo/models"
+	"go-todo/storage"
+
+	"github.com/spf13/viper"
+)
+
+// pngHeader 足以被识别为 image/png 的文件头
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// TestAttachments 测试附件的上传、类型和大小限制、下载以及随任务删除
+func TestAttachments(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	local, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Storage = local
+	viper.Set("attachments.max_size", 1024)
+	defer viper.Set("attachments.max_size", nil)
+
+	s := &AttachmentService{}
+	ts := newTestTodoService(db)
+	owner := createTestUser(t, "owner")
+	other := createTestUser(t, "other")
+	todo := &models.Todo{Title: "截图"}
+	ts.Create(t.Context(), owner.ID, todo)
+	id := todo.PublicID
+
+	attachment, err := s.Upload(t.Context(), owner.ID, id, "../../shot.png", bytes.NewReader(pngHeader), int64(len(pngHeader)))
+	if err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	if attachment.Filename != "shot.png" || attachment.ContentType != "image/png" {
+		t.Errorf("文件名或类型不正确: %+v", attachment)
+	}
+
+	// 类型按内容识别，扩展名不可信
+	exe := []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff")
+	if _, err := s.Upload(t.Context(), owner.ID, id, "fake.png", bytes.NewReader(append(exe, make([]byte, 600)...)), 614); !errors.Is(err, ErrFileType) {
+		t.Errorf("期望拒绝不允许的类型，但得到了 %v", err)
+	}
+	if _, err := s.Upload(t.Context(), owner.ID, id, "big.txt", strings.NewReader(strings.Repeat("a", 2048)), 2048); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("期望拒绝过大的文件，但得到了 %v", err)
+	}
+	if _, err := s.Upload(t.Context(), other.ID, id, "x.txt", strings.NewReader("hi"), 2); err == nil {
+		t.Error("期望其他用户不能上传")
+	}
+
+	a, rc, err := s.Open(t.Context(), owner.ID, id, attachment.ID)
+	if err != nil {
+		t.Fatalf("下载失败: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(data, pngHeader) || a.Size != int64(len(pngHeader)) {
+		t.Errorf("下载内容不一致: %q", data)
+	}
+	if list, _ := s.List(t.Context(), owner.ID, id); len(list) != 1 {
+		t.Errorf("期望 1 个附件，但得到了 %d 个", len(list))
+	}
+
+	// 删除任务时附件文件一起删除
+	if err := ts.Delete(t.Context(), owner.ID, id); err != nil {
+		t.Fatalf("删除任务失败: %v", err)
+	}
+	if _, err := local.Get(context.Background(), attachment.StorageKey); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("期望附件文件被删除，但得到了 %v", err)
+	}
+	var count int64
+	db.Model(&models.Attachment{}).Count(&count)
+	if count != 0 {
+		t.Errorf("期望附件记录被删除，但还剩 %d 条", count)
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-todo/config"
+	"go-todo/models"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CommentService 任务评论
+type CommentService struct{}
+
+// MaxCommentLength 评论正文的最大字符数
+const MaxCommentLength = 10000
+
+// mentionPattern 匹配正文中的 @username
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.\-]+)`)
+
+// List 列出任务的评论，能查看任务的用户都能查看评论
+func (s *CommentService) List(ctx context.Context, userID uint, todoID string) ([]models.Comment, error) {
+	ts := defaultTodoService()
+	todo, err := ts.GetByID(ctx, userID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	return commentsOf(ctx, todo.ID)
+}
+
+// Create 发表评论，能查看任务的用户（包括工作区 viewer）都可以评论
+func (s *CommentService) Create(ctx context.Context, userID uint, todoID string, body string) (models.Comment, error) {
+	var comment models.Comment
+	ts := defaultTodoService()
+	todo, err := ts.GetByID(ctx, userID, todoID)
+	if err != nil {
+		return comment, err
+	}
+	var author models.User
+	if err := config.DB.WithContext(ctx).First(&author, userID).Error; err != nil {
+		return comment, err
+	}
+
+	comment = models.Comment{TodoID: todo.ID, AuthorID: &userID, AuthorPublicID: &author.PublicID, AuthorName: author.Username, Body: body}
+	return comment, saveComment(ctx, &comment, todo)
+}
+
+// CreateShared 通过可评论的分享链接发表访客评论
+func (s *CommentService) CreateShared(ctx context.Context, link models.ShareLink, todoID string, name, body string) (models.Comment, error) {
+	var comment models.Comment
+	if link.Permission != models.SharePermissionComment {
+		return comment, ErrForbidden
+	}
+	todo, err := sharedTodo(ctx, link, todoID)
+	if err != nil {
+		return comment, err
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = "访客"
+	}
+
+	comment = models.Comment{TodoID: todo.ID, AuthorName: name, ShareLinkID: &link.ID, Body: body}
+	return comment, saveComment(ctx, &comment, todo)
+}
+
+// ListShared 列出分享中某个任务的评论
+func (s *CommentService) ListShared(ctx context.Context, link models.ShareLink, todoID string) ([]models.Comment, error) {
+	todo, err := sharedTodo(ctx, link, todoID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := commentsOf(ctx, todo.ID)
+	// 分享链接的访客只能看到作者名称，不返回注册用户的 ID
+	for i := range comments {
+		comments[i].AuthorPublicID = nil
+	}
+	return comments, err
+}
+
+// Update 修改评论，只有作者本人可以修改
+func (s *CommentService) Update(ctx context.Context, userID uint, todoID string, commentID uint, body string) (models.Comment, error) {
+	comment, todo, err := findComment(ctx, userID, todoID, commentID)
+	if err != nil {
+		return comment, err
+	}
+	if comment.AuthorID == nil || *comment.AuthorID != userID {
+		return comment, ErrForbidden
+	}
+	comment.Body = body
+	return comment, saveComment(ctx, &comment, todo)
+}
+
+// Delete 删除评论：作者本人、个人任务的所有者或工作区 owner 可以删除
+func (s *CommentService) Delete(ctx context.Context, userID uint, todoID string, commentID uint) error {
+	comment, todo, err := findComment(ctx, userID, todoID, commentID)
+	if err != nil {
+		return err
+	}
+	allowed := comment.AuthorID != nil && *comment.AuthorID == userID
+	if !allowed && todo.WorkspaceID == nil {
+		allowed = todo.UserID == userID
+	}
+	if !allowed && todo.WorkspaceID != nil {
+		role, _ := workspaceRole(ctx, userID, *todo.WorkspaceID)
+		allowed = role == models.WorkspaceOwner
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentMention{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&comment).Error
+	})
+}
+
+// findComment 查找任务下的评论，同时校验用户能否查看该任务
+func findComment(ctx context.Context, userID uint, todoID string, commentID uint) (models.Comment, models.Todo, error) {
+	var comment models.Comment
+	ts := defaultTodoService()
+	todo, err := ts.GetByID(ctx, userID, todoID)
+	if err != nil {
+		return comment, todo, err
+	}
+	err = config.DB.WithContext(ctx).Scopes(withAuthorID).Where("comments.todo_id = ? AND comments.id = ?", todo.ID, commentID).First(&comment).Error
+	return comment, todo, err
+}
+
+// sharedTodo 按公开 ID 返回分享范围内的任务
+func sharedTodo(ctx context.Context, link models.ShareLink, todoID string) (models.Todo, error) {
+	var todo models.Todo
+	if link.TodoID != nil {
+		err := config.DB.WithContext(ctx).Where("public_id = ?", todoID).First(&todo).Error
+		if err == nil && todo.ID != *link.TodoID {
+			return todo, gorm.ErrRecordNotFound
+		}
+		return todo, err
+	}
+	err := sharedProjectScope(ctx, link).Where("public_id = ?", todoID).First(&todo).Error
+	return todo, err
+}
+
+// commentsOf 按时间顺序列出任务的评论
+func commentsOf(ctx context.Context, todoID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := config.DB.WithContext(ctx).Scopes(withAuthorID).Where("comments.todo_id = ?", todoID).Preload("Mentions").Order("comments.id ASC").Find(&comments).Error
+	return comments, err
+}
+
+// withAuthorID 查询评论时一并查出作者的公开 ID
+func withAuthorID(db *gorm.DB) *gorm.DB {
+	return db.Select("comments.*, users.public_id AS author_public_id").
+		Joins("LEFT JOIN users ON users.id = comments.author_id")
+}
+
+// saveComment 校验正文，解析 @ 提到的用户并保存评论
+func saveComment(ctx context.Context, comment *models.Comment, todo models.Todo) error {
+	comment.Body = strings.TrimSpace(comment.Body)
+	if comment.Body == "" {
+		return errors.New("评论内容不能为空")
+	}
+	if utf8.RuneCountInString(comment.Body) > MaxCommentLength {
+		return errors.New("评论内容过长")
+	}
+
+	mentions, err := resolveMentions(ctx, comment.Body, todo)
+	if err != nil {
+		return err
+	}
+
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(comment).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentMention{}).Error; err != nil {
+			return err
+		}
+		for i := range mentions {
+			mentions[i].CommentID = comment.ID
+		}
+		if len(mentions) > 0 {
+			if err := tx.Create(&mentions).Error; err != nil {
+				return err
+			}
+		}
+		comment.Mentions = mentions
+		return nil
+	})
+}
+
+// resolveMentions 把正文中的 @username 解析为用户，只保留能看到该任务的用户
+func resolveMentions(ctx context.Context, body string, todo models.Todo) ([]models.CommentMention, error) {
+	var names []string
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// 去掉句末的标点，例如 "@jane." 中的 "."
+		name := strings.TrimRight(m[1], ".-")
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	mentions := []models.CommentMention{}
+	if len(names) == 0 {
+		return mentions, nil
+	}
+
+	query := config.DB.WithContext(ctx).Model(&models.User{}).Where("username IN ?", names)
+	if todo.WorkspaceID != nil {
+		members := config.DB.WithContext(ctx).Model(&models.WorkspaceMember{}).Select("user_id").Where("workspace_id = ?", *todo.WorkspaceID)
+		query = query.Where("id IN (?)", members)
+	} else {
+		query = query.Where("id = ?", todo.UserID)
+	}
+	var users []models.User
+	if err := query.Order("id ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		mentions = append(mentions, models.CommentMention{UserID: u.ID, Username: u.Username})
+	}
+	return mentions, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-todo/config"
+	"go-todo/models"
+)
+
+// TestComments 测试评论、@ 提及和评论数量
+func TestComments(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &CommentService{}
+	ws := &WorkspaceService{}
+	ts := newTestTodoService(db)
+
+	owner := createTestUser(t, "owner")
+	viewer := createTestUser(t, "viewer")
+	createTestUser(t, "outsider")
+	workspace, _ := ws.Create(t.Context(), owner.ID, "研发组")
+	invite, _ := ws.Invite(t.Context(), owner.ID, workspace.ID, "viewer", models.WorkspaceViewer, 0)
+	ws.AcceptInvite(t.Context(), viewer.ID, invite.Token)
+
+	todo := &models.Todo{Title: "讨论", WorkspaceID: &workspace.ID}
+	ts.Create(t.Context(), owner.ID, todo)
+
+	// viewer 也可以评论；只有工作区成员会被解析为提及
+	comment, err := s.Create(t.Context(), viewer.ID, todo.PublicID, "@owner 看一下，顺便 @outsider。")
+	if err != nil {
+		t.Fatalf("发表评论失败: %v", err)
+	}
+	if comment.AuthorName != "viewer" || len(comment.Mentions) != 1 || comment.Mentions[0].Username != "owner" {
+		t.Errorf("评论作者或提及不正确: %+v", comment)
+	}
+
+	if _, err := s.Create(t.Context(), viewer.ID, todo.PublicID, "   "); err == nil {
+		t.Error("期望空评论被拒绝")
+	}
+
+	// 只有作者可以修改
+	if _, err := s.Update(t.Context(), owner.ID, todo.PublicID, comment.ID, "改一下"); err != ErrForbidden {
+		t.Errorf("期望非作者不能修改评论，但得到了 %v", err)
+	}
+	updated, err := s.Update(t.Context(), viewer.ID, todo.PublicID, comment.ID, "不用看了")
+	if err != nil || len(updated.Mentions) != 0 {
+		t.Errorf("期望修改后提及被清空，得到 %+v, %v", updated.Mentions, err)
+	}
+
+	s.Create(t.Context(), owner.ID, todo.PublicID, "第二条")
+	todos, _, _ := ts.List(t.Context(), owner.ID, TodoQuery{WorkspaceID: &workspace.ID})
+	if len(todos) != 1 || todos[0].CommentCount != 2 {
+		t.Errorf("期望任务列表返回评论数 2，但得到了 %+v", todos)
+	}
+
+	// 工作区 owner 可以删除任何评论
+	if err := s.Delete(t.Context(), owner.ID, todo.PublicID, comment.ID); err != nil {
+		t.Fatalf("owner 删除评论失败: %v", err)
+	}
+	comments, _ := s.List(t.Context(), viewer.ID, todo.PublicID)
+	if len(comments) != 1 {
+		t.Errorf("期望剩下 1 条评论，但得到了 %d", len(comments))
+	}
+
+	// 删除任务时评论一并删除
+	ts.Delete(t.Context(), owner.ID, todo.PublicID)
+	var count int64
+	db.Model(&models.Comment{}).Count(&count)
+	if count != 0 {
+		t.Errorf("期望任务删除后评论被清理，但还剩 %d 条", count)
+	}
+}
+
+// TestComments_Shared 测试通过分享链接发表访客评论
+func TestComments_Shared(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &CommentService{}
+	ss := &ShareService{}
+
+	alice := createTestUser(t, "alice")
+	todo := &models.Todo{Title: "官网首页", Project: "官网", UserID: alice.ID}
+	other := &models.Todo{Title: "私事", UserID: alice.ID}
+	db.Create(todo)
+	db.Create(other)
+
+	readOnly, _ := ss.Create(t.Context(), alice.ID, ShareRequest{TodoID: &todo.PublicID})
+	if _, err := s.CreateShared(t.Context(), readOnly, todo.PublicID, "客户", "很好"); err != ErrForbidden {
+		t.Errorf("期望只读分享不能评论，但得到了 %v", err)
+	}
+
+	link, _ := ss.Create(t.Context(), alice.ID, ShareRequest{Project: "官网", Permission: models.SharePermissionComment})
+	comment, err := s.CreateShared(t.Context(), link, todo.PublicID, "", "文案再改改 @alice")
+	if err != nil {
+		t.Fatalf("访客评论失败: %v", err)
+	}
+	if comment.AuthorID != nil || comment.AuthorName != "访客" || len(comment.Mentions) != 1 {
+		t.Errorf("访客评论不正确: %+v", comment)
+	}
+	if _, err := s.CreateShared(t.Context(), link, other.PublicID, "客户", "看不到的任务"); err == nil {
+		t.Error("期望不能评论分享范围之外的任务")
+	}
+
+	if _, err := s.Create(t.Context(), alice.ID, todo.PublicID, "收到"); err != nil {
+		t.Fatal(err)
+	}
+	comments, err := s.ListShared(t.Context(), link, todo.PublicID)
+	if err != nil || len(comments) != 2 {
+		t.Fatalf("期望分享中能看到 2 条评论，得到 %d, %v", len(comments), err)
+	}
+	if comments[1].AuthorName != "alice" || comments[1].AuthorPublicID != nil {
+		t.Errorf("分享中只应该返回作者名称，但得到了 %+v", comments[1])
+	}
+}
+
+// TestServicesCanceled 评论、附件和同步使用请求的 ctx，请求超时或断开后不再查询
+func TestServicesCanceled(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	ts := newTestTodoService(db)
+	user := createTestUser(t, "alice")
+	todo := &models.Todo{Title: "任务"}
+	ts.Create(t.Context(), user.ID, todo)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := (&CommentService{}).List(ctx, user.ID, todo.PublicID); !errors.Is(err, context.Canceled) {
+		t.Errorf("评论期望 context.Canceled，但得到了 %v", err)
+	}
+	if _, err := (&AttachmentService{}).List(ctx, user.ID, todo.PublicID); !errors.Is(err, context.Canceled) {
+		t.Errorf("附件期望 context.Canceled，但得到了 %v", err)
+	}
+	if _, err := (&SyncService{}).Pull(ctx, user.ID, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("同步期望 context.Canceled，但得到了 %v", err)
+	}
+	if _, err := (&WorkspaceService{}).List(ctx, user.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("工作区期望 context.Canceled，但得到了 %v", err)
+	}
+	if _, err := (&WebhookService{}).List(ctx, user.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("Webhook 期望 context.Canceled，但得到了 %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import "errors"
+
+// 可被控制器识别的业务错误，错误信息会直接返回给前端
+var (
+	ErrInvalidDueFilter = errors.New("due 参数只能是 today、week 或 overdue")
+	ErrWrongPassword    = errors.New("密码错误")
+	ErrUserDisabled     = errors.New("账号已被禁用")
+	ErrUserNotFound     = errors.New("用户不存在")
+	ErrForbidden        = errors.New("权限不足")
+	ErrMemberNotFound   = errors.New("该用户不是工作区成员")
+	ErrInviteInvalid    = errors.New("邀请不存在或已失效")
+	ErrInvalidAssignee  = errors.New("只能分配给自己（个人任务）或工作区成员")
+	ErrShareInvalid     = errors.New("分享链接不存在或已失效")
+	ErrSharePassword    = errors.New("需要正确的访问密码")
+	ErrFileTooLarge     = errors.New("文件过大")
+	ErrFileType         = errors.New("不支持的文件类型")
+	ErrWebhookInvalid   = errors.New("Webhook 参数错误")
+	ErrSyncToken        = errors.New("同步 token 无效，请重新进行全量同步")
+	ErrSyncInvalid      = errors.New("同步参数错误")
+
+	ErrOwnsSharedWorkspace   = errors.New("你拥有仍有其他成员的工作区，请先转让或删除后再注销")
+	ErrIdempotencyMismatch   = errors.New("Idempotency-Key 已用于不同的请求")
+	ErrIdempotencyInProgress = errors.New("相同 Idempotency-Key 的请求正在处理")
+)
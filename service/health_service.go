@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go-todo/config"
+	"go-todo/migrations"
+	"runtime"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BuildInfo 构建信息，由 main 通过 -ldflags 注入
+type BuildInfo struct {
+	Version   string `json:"version" example:"1.4.0"`
+	Commit    string `json:"commit" example:"f459193"`
+	BuildTime string `json:"build_time" example:"2026-10-19T08:00:00Z"`
+}
+
+// HealthService 存活、就绪检查和运行状态
+type HealthService struct {
+	db      *gorm.DB
+	workers *Workers
+	build   BuildInfo
+	started time.Time
+}
+
+// NewHealthService 创建 HealthService，workers 是需要一直运行的后台任务
+func NewHealthService(db *gorm.DB, workers *Workers, build BuildInfo) *HealthService {
+	return &HealthService{db: db, workers: workers, build: build, started: time.Now()}
+}
+
+// HealthCheck 一项就绪检查的结果
+type HealthCheck struct {
+	Name  string `json:"name" example:"database"`
+	OK    bool   `json:"ok" example:"true"`
+	Error string `json:"error,omitempty" example:""`
+}
+
+// Readiness 就绪检查的结果，所有检查都通过时 Ready 为 true
+type Readiness struct {
+	Ready  bool          `json:"ready" example:"true"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// Ready 检查服务能否处理请求：数据库可以连接、迁移都已执行、后台任务都在运行
+func (s *HealthService) Ready(ctx context.Context) Readiness {
+	r := Readiness{Ready: true}
+	add := func(name string, err error) {
+		check := HealthCheck{Name: name, OK: err == nil}
+		if err != nil {
+			check.Error = err.Error()
+			r.Ready = false
+		}
+		r.Checks = append(r.Checks, check)
+	}
+
+	sqlDB, err := s.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	add("database", err)
+	if err == nil {
+		add("migrations", s.checkMigrations(ctx))
+	}
+	for _, w := range s.workers.Status() {
+		var err error
+		if !w.Running {
+			err = fmt.Errorf("后台任务 %s 没有运行", w.Name)
+		}
+		add("worker:"+w.Name, err)
+	}
+	return r
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) error {
+	m, err := migrations.New(s.db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("有 %d 个迁移尚未执行", len(pending))
+	}
+	return nil
+}
+
+// DatabaseStatus 数据库和连接池状态
+type DatabaseStatus struct {
+	Driver string `json:"driver" example:"mysql"`
+	// 已执行的最新迁移版本
+	SchemaVersion uint  `json:"schema_version" example:"2"`
+	MaxOpenConns  int   `json:"max_open_conns" example:"25"`
+	OpenConns     int   `json:"open_conns" example:"3"`
+	InUse         int   `json:"in_use" example:"1"`
+	Idle          int   `json:"idle" example:"2"`
+	WaitCount     int64 `json:"wait_count" example:"0"`
+	// 等待空闲连接的累计时间，如 "1.5s"
+	WaitDuration      string `json:"wait_duration" example:"0s"`
+	MaxIdleClosed     int64  `json:"max_idle_closed" example:"0"`
+	MaxLifetimeClosed int64  `json:"max_lifetime_closed" example:"0"`
+}
+
+// SystemStatus 管理员查看的运行状态
+type SystemStatus struct {
+	Build     BuildInfo `json:"build"`
+	GoVersion string    `json:"go_version" example:"go1.25.5"`
+	StartedAt time.Time `json:"started_at"`
+	// 运行时长，如 "72h3m0s"
+	Uptime     string         `json:"uptime" example:"72h3m0s"`
+	Goroutines int            `json:"goroutines" example:"42"`
+	Database   DatabaseStatus `json:"database"`
+	Workers    []WorkerStatus `json:"workers"`
+	// 实时事件流的连接数
+	EventConnections int       `json:"event_connections" example:"5"`
+	Readiness        Readiness `json:"readiness"`
+}
+
+// Status 构建版本、运行时长、连接池统计和就绪检查
+func (s *HealthService) Status(ctx context.Context) SystemStatus {
+	status := SystemStatus{
+		Build:            s.build,
+		GoVersion:        runtime.Version(),
+		StartedAt:        s.started,
+		Uptime:           time.Since(s.started).Round(time.Second).String(),
+		Goroutines:       runtime.NumGoroutine(),
+		Database:         DatabaseStatus{Driver: s.db.Dialector.Name()},
+		Workers:          s.workers.Status(),
+		EventConnections: config.Events.Connections(),
+		Readiness:        s.Ready(ctx),
+	}
+	if sqlDB, err := s.db.DB(); err == nil {
+		stats := sqlDB.Stats()
+		status.Database.MaxOpenConns = stats.MaxOpenConnections
+		status.Database.OpenConns = stats.OpenConnections
+		status.Database.InUse = stats.InUse
+		status.Database.Idle = stats.Idle
+		status.Database.WaitCount = stats.WaitCount
+		status.Database.WaitDuration = stats.WaitDuration.String()
+		status.Database.MaxIdleClosed = stats.MaxIdleClosed
+		status.Database.MaxLifetimeClosed = stats.MaxLifetimeClosed
+	}
+	if m, err := migrations.New(s.db.WithContext(ctx)); err == nil {
+		if list, err := m.Status(); err == nil {
+			for _, st := range list {
+				if st.AppliedAt != nil && st.Version > status.Database.SchemaVersion {
+					status.Database.SchemaVersion = st.Version
+				}
+			}
+		}
+	}
+	return status
+}
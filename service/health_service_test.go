@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestWorkers(t *testing.T) {
+	w := NewWorkers()
+	w.Add("loop", func(ctx context.Context) { <-ctx.Done() })
+	w.Add("crash", func(ctx context.Context) { panic("boom") })
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Start(ctx)
+
+	// 等 crash 任务退出
+	deadline := time.Now().Add(time.Second)
+	for w.Status()[0].Running && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	status := w.Status()
+	if len(status) != 2 || status[0].Name != "crash" || status[0].Running || !status[1].Running {
+		t.Errorf("期望 crash 已退出、loop 在运行，但得到了 %+v", status)
+	}
+
+	cancel()
+	w.Wait()
+	if w.Status()[1].Running {
+		t.Error("ctx 取消后任务应该退出")
+	}
+}
+
+func TestHealthReady(t *testing.T) {
+	workers := NewWorkers()
+	s := NewHealthService(setupTestDB(), workers, BuildInfo{Version: "1.0.0"})
+	if r := s.Ready(t.Context()); !r.Ready || len(r.Checks) != 2 {
+		t.Errorf("期望就绪，但得到了 %+v", r)
+	}
+
+	// 登记了但没有运行的后台任务
+	workers.Add("webhooks", func(context.Context) {})
+	if r := s.Ready(t.Context()); r.Ready || r.Checks[2].Name != "worker:webhooks" || r.Checks[2].OK {
+		t.Errorf("后台任务没有运行时期望未就绪，但得到了 %+v", r)
+	}
+
+	status := s.Status(t.Context())
+	if status.Build.Version != "1.0.0" || status.Database.Driver != "sqlite" || status.Database.SchemaVersion == 0 {
+		t.Errorf("运行状态不完整: %+v", status)
+	}
+
+	// 还没有执行迁移的数据库
+	db, _ := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	r := NewHealthService(db, NewWorkers(), BuildInfo{}).Ready(t.Context())
+	if r.Ready || r.Checks[1].Name != "migrations" || r.Checks[1].OK {
+		t.Errorf("有未执行的迁移时期望未就绪，但得到了 %+v", r)
+	}
+}
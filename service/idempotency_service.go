@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"go-todo/config"
+	"go-todo/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyService 保存和重放带 Idempotency-Key 的请求
+type IdempotencyService struct{}
+
+// Begin 开始处理一个带 Key 的请求。
+// replay 为 true 时返回的是已完成的记录，调用方应直接重放保存的响应；
+// 否则 Key 已被占用，调用方处理请求后调用 Complete 或 Abandon。
+// Key 被用于不同的请求时返回 ErrIdempotencyMismatch，同一 Key 的请求仍在处理时返回 ErrIdempotencyInProgress
+func (s *IdempotencyService) Begin(userID uint, key, fingerprint string, ttl time.Duration) (record models.IdempotencyKey, replay bool, err error) {
+	now := time.Now()
+	err = config.DB.Where("user_id = ? AND idempotency_key = ? AND expires_at > ?", userID, key, now).First(&record).Error
+	if err == nil {
+		switch {
+		case record.Fingerprint != fingerprint:
+			return record, false, ErrIdempotencyMismatch
+		case !record.Completed:
+			return record, false, ErrIdempotencyInProgress
+		default:
+			return record, true, nil
+		}
+	}
+
+	// 清理该用户已过期的 Key，然后占用这个 Key；并发插入时唯一索引保证只有一个成功
+	config.DB.Where("user_id = ? AND expires_at <= ?", userID, now).Delete(&models.IdempotencyKey{})
+	record = models.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint, ExpiresAt: now.Add(ttl)}
+	if err := config.DB.Create(&record).Error; err != nil {
+		if isDuplicateKey(err) {
+			// 相同 Key 的并发请求先占用了 Key
+			return record, false, ErrIdempotencyInProgress
+		}
+		return record, false, err
+	}
+	return record, false, nil
+}
+
+// isDuplicateKey 错误是否是唯一索引冲突；各数据库驱动的错误由 GORM 的 ErrorTranslator 统一转换
+func isDuplicateKey(err error) bool {
+	if t, ok := config.DB.Dialector.(gorm.ErrorTranslator); ok {
+		err = t.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// Complete 保存请求的响应
+func (s *IdempotencyService) Complete(id uint, status int, contentType string, body []byte) error {
+	return config.DB.Model(&models.IdempotencyKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"completed":     true,
+		"status_code":   status,
+		"content_type":  contentType,
+		"response_body": body,
+	}).Error
+}
+
+// Abandon 放弃保存（例如服务器错误），客户端可以用同一个 Key 重试
+func (s *IdempotencyService) Abandon(id uint) error {
+	return config.DB.Delete(&models.IdempotencyKey{}, id).Error
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-todo/config"
+	"go-todo/models"
+)
+
+// TestIdempotency 测试 Key 的占用、重放、指纹不一致和过期
+func TestIdempotency(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &IdempotencyService{}
+
+	record, replay, err := s.Begin(1, "key-1", "fp-a", time.Hour)
+	if err != nil || replay {
+		t.Fatalf("第一次请求应该占用 Key: %v", err)
+	}
+	if _, _, err := s.Begin(1, "key-1", "fp-a", time.Hour); err != ErrIdempotencyInProgress {
+		t.Errorf("期望处理中的请求被拒绝，但得到了 %v", err)
+	}
+	s.Complete(record.ID, 200, "application/json", []byte(`{"code":200}`))
+
+	saved, replay, err := s.Begin(1, "key-1", "fp-a", time.Hour)
+	if err != nil || !replay || string(saved.ResponseBody) != `{"code":200}` {
+		t.Errorf("期望重放保存的响应，但得到了 %+v, %v", saved, err)
+	}
+	if _, _, err := s.Begin(1, "key-1", "fp-b", time.Hour); err != ErrIdempotencyMismatch {
+		t.Errorf("期望不同的请求被拒绝，但得到了 %v", err)
+	}
+	// Key 按用户隔离
+	if _, replay, err := s.Begin(2, "key-1", "fp-b", time.Hour); err != nil || replay {
+		t.Errorf("其他用户应该可以使用相同的 Key: %v", err)
+	}
+
+	// 放弃后可以重试
+	record, _, _ = s.Begin(1, "key-2", "fp-a", time.Hour)
+	s.Abandon(record.ID)
+	if _, replay, err := s.Begin(1, "key-2", "fp-a", time.Hour); err != nil || replay {
+		t.Errorf("放弃后应该可以重新使用 Key: %v", err)
+	}
+
+	// 唯一索引冲突才算处理中，其他数据库错误原样返回
+	dup := models.IdempotencyKey{UserID: 1, Key: "key-2", Fingerprint: "fp-a", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := db.Create(&dup).Error; !isDuplicateKey(err) {
+		t.Errorf("期望识别为唯一索引冲突，但得到了 %v", err)
+	}
+
+	// 过期后可以用于新的请求
+	record, _, _ = s.Begin(1, "key-3", "fp-a", -time.Second)
+	s.Complete(record.ID, 200, "application/json", nil)
+	if _, replay, err := s.Begin(1, "key-3", "fp-b", time.Hour); err != nil || replay {
+		t.Errorf("过期的 Key 应该可以重新使用: %v", err)
+	}
+}
+
+// TestIdempotencyDatabaseError 数据库出错不能当成相同 Key 的请求正在处理
+func TestIdempotencyDatabaseError(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &IdempotencyService{}
+
+	if err := db.Migrator().DropTable(&models.IdempotencyKey{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Begin(1, "key-1", "fp-a", time.Hour); err == nil || errors.Is(err, ErrIdempotencyInProgress) {
+		t.Errorf("期望返回数据库错误，但得到了 %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-todo/config"
+	"go-todo/models"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ShareService 分享链接的创建、撤销和公开访问
+type ShareService struct{}
+
+// ShareRequest 创建分享链接的参数
+type ShareRequest struct {
+	// 分享单个任务时填写任务的公开 ID
+	TodoID *string
+	// 分享项目时填写，WorkspaceID 为空表示个人任务中的项目
+	WorkspaceID *uint
+	Project     string
+	Permission  string
+	Password    string
+	TTL         time.Duration
+}
+
+// SharedTodo 公开分享中返回的任务，不包含用户等内部信息
+type SharedTodo struct {
+	ID          string     `json:"id" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	Title       string     `json:"title" example:"完成项目文档"`
+	Description string     `json:"description" example:"编写详细的 README 和 API 文档"`
+	Status      bool       `json:"status" example:"false"`
+	Project     string     `json:"project" example:"工作"`
+	DueDate     *time.Time `json:"due_date"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// SharedContent 公开分享的内容
+type SharedContent struct {
+	// 权限：read / comment
+	Permission string `json:"permission" example:"read"`
+	// 分享的项目名称（分享单个任务时为空）
+	Project string `json:"project,omitempty" example:"工作"`
+	// 过期时间
+	ExpiresAt *time.Time `json:"expires_at"`
+	// 分享的任务列表，分享单个任务时只有一条
+	Todos []SharedTodo `json:"todos"`
+}
+
+// Create 创建分享链接，需要对分享的任务或工作区有编辑权限
+func (s *ShareService) Create(ctx context.Context, userID uint, req ShareRequest) (models.ShareLink, error) {
+	link := models.ShareLink{OwnerID: userID, Permission: req.Permission}
+	if link.Permission == "" {
+		link.Permission = models.SharePermissionRead
+	}
+	if link.Permission != models.SharePermissionRead && link.Permission != models.SharePermissionComment {
+		return link, errors.New("权限只能是 read 或 comment")
+	}
+
+	switch {
+	case req.TodoID != nil:
+		ts := defaultTodoService()
+		todo, err := ts.GetByID(ctx, userID, *req.TodoID)
+		if err != nil {
+			return link, err
+		}
+		if err := ts.authorize(ctx, userID, todo, true); err != nil {
+			return link, err
+		}
+		link.TodoID = &todo.ID
+		link.TodoPublicID = &todo.PublicID
+	case req.Project != "":
+		if req.WorkspaceID != nil {
+			role, err := workspaceRole(ctx, userID, *req.WorkspaceID)
+			if err != nil {
+				return link, err
+			}
+			if !canEdit(role) {
+				return link, ErrForbidden
+			}
+		}
+		link.WorkspaceID = req.WorkspaceID
+		link.Project = req.Project
+	default:
+		return link, errors.New("请指定要分享的任务或项目")
+	}
+
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return link, err
+		}
+		link.PasswordHash = string(hash)
+		link.HasPassword = true
+	}
+	if req.TTL > 0 {
+		expiresAt := time.Now().Add(req.TTL)
+		link.ExpiresAt = &expiresAt
+	}
+
+	token, err := randomToken(24)
+	if err != nil {
+		return link, err
+	}
+	link.Token = token
+	return link, config.DB.WithContext(ctx).Create(&link).Error
+}
+
+// List 列出当前用户创建的分享链接
+func (s *ShareService) List(ctx context.Context, userID uint) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	err := config.DB.WithContext(ctx).Select("share_links.*, todos.public_id AS todo_public_id").
+		Joins("LEFT JOIN todos ON todos.id = share_links.todo_id").
+		Where("share_links.owner_id = ?", userID).Order("share_links.id DESC").Find(&links).Error
+	for i := range links {
+		links[i].HasPassword = links[i].PasswordHash != ""
+	}
+	return links, err
+}
+
+// Revoke 撤销分享链接
+func (s *ShareService) Revoke(ctx context.Context, userID, shareID uint) error {
+	var link models.ShareLink
+	if err := config.DB.WithContext(ctx).Where("owner_id = ?", userID).First(&link, shareID).Error; err != nil {
+		return err
+	}
+	if link.RevokedAt != nil {
+		return nil
+	}
+	return config.DB.WithContext(ctx).Model(&link).Update("revoked_at", time.Now()).Error
+}
+
+// Resolve 校验分享口令和密码，返回有效的分享链接
+func (s *ShareService) Resolve(ctx context.Context, token, password string) (models.ShareLink, error) {
+	var link models.ShareLink
+	if err := config.DB.WithContext(ctx).Where("token = ?", token).First(&link).Error; err != nil {
+		return link, ErrShareInvalid
+	}
+	if link.RevokedAt != nil || (link.ExpiresAt != nil && link.ExpiresAt.Before(time.Now())) {
+		return link, ErrShareInvalid
+	}
+	if link.PasswordHash != "" {
+		if password == "" || bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+			return link, ErrSharePassword
+		}
+	}
+	// 创建者失去访问权限（如退出工作区）后，分享也随之失效
+	if link.WorkspaceID != nil {
+		if _, err := workspaceRole(ctx, link.OwnerID, *link.WorkspaceID); err != nil {
+			return link, ErrShareInvalid
+		}
+	}
+	return link, nil
+}
+
+// Content 返回分享的任务
+func (s *ShareService) Content(ctx context.Context, link models.ShareLink) (SharedContent, error) {
+	content := SharedContent{Permission: link.Permission, Project: link.Project, ExpiresAt: link.ExpiresAt}
+
+	var todos []models.Todo
+	if link.TodoID != nil {
+		var todo models.Todo
+		if err := config.DB.WithContext(ctx).First(&todo, *link.TodoID).Error; err != nil {
+			return content, ErrShareInvalid
+		}
+		ts := defaultTodoService()
+		if err := ts.authorize(ctx, link.OwnerID, todo, false); err != nil {
+			return content, ErrShareInvalid
+		}
+		todos = append(todos, todo)
+	} else {
+		if err := sharedProjectScope(ctx, link).Order("id ASC").Find(&todos).Error; err != nil {
+			return content, err
+		}
+	}
+
+	content.Todos = make([]SharedTodo, 0, len(todos))
+	for _, t := range todos {
+		content.Todos = append(content.Todos, SharedTodo{
+			ID:          t.PublicID,
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      t.Status,
+			Project:     t.Project,
+			DueDate:     t.DueDate,
+			UpdatedAt:   t.UpdatedAt,
+		})
+	}
+	return content, nil
+}
+
+// sharedProjectScope 分享项目时可以看到的任务范围
+func sharedProjectScope(ctx context.Context, link models.ShareLink) *gorm.DB {
+	query := config.DB.WithContext(ctx).Model(&models.Todo{}).Where("project = ?", link.Project)
+	if link.WorkspaceID != nil {
+		return query.Where("workspace_id = ?", *link.WorkspaceID)
+	}
+	return query.Where("user_id = ? AND workspace_id IS NULL", link.OwnerID)
+}
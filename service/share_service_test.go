@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go-todo/config"
+	"go-todo/models"
+)
+
+// TestShare_Todo 测试分享单个任务、密码校验和撤销
+func TestShare_Todo(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &ShareService{}
+
+	alice := createTestUser(t, "alice")
+	bob := createTestUser(t, "bob")
+	todo := &models.Todo{Title: "给客户看的任务", UserID: alice.ID}
+	db.Create(todo)
+
+	if _, err := s.Create(t.Context(), bob.ID, ShareRequest{TodoID: &todo.PublicID}); err == nil {
+		t.Error("期望不能分享别人的个人任务")
+	}
+
+	link, err := s.Create(t.Context(), alice.ID, ShareRequest{TodoID: &todo.PublicID, Password: "s3cret"})
+	if err != nil {
+		t.Fatalf("创建分享失败: %v", err)
+	}
+	if link.Permission != models.SharePermissionRead || !link.HasPassword {
+		t.Errorf("期望默认只读且有密码，但得到了 %+v", link)
+	}
+
+	if _, err := s.Resolve(t.Context(), link.Token, ""); err != ErrSharePassword {
+		t.Errorf("期望缺少密码时返回 ErrSharePassword，但得到了 %v", err)
+	}
+	resolved, err := s.Resolve(t.Context(), link.Token, "s3cret")
+	if err != nil {
+		t.Fatalf("期望密码正确时可以访问，但得到了 %v", err)
+	}
+	content, err := s.Content(t.Context(), resolved)
+	if err != nil || len(content.Todos) != 1 || content.Todos[0].Title != "给客户看的任务" {
+		t.Fatalf("分享内容不正确: %+v, %v", content, err)
+	}
+
+	if err := s.Revoke(t.Context(), alice.ID, link.ID); err != nil {
+		t.Fatalf("撤销失败: %v", err)
+	}
+	if _, err := s.Resolve(t.Context(), link.Token, "s3cret"); err != ErrShareInvalid {
+		t.Errorf("期望撤销后链接失效，但得到了 %v", err)
+	}
+}
+
+// TestShare_ProjectAndExpiry 测试分享项目和过期
+func TestShare_ProjectAndExpiry(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &ShareService{}
+
+	alice := createTestUser(t, "alice")
+	db.Create(&models.Todo{Title: "a", Project: "官网", UserID: alice.ID})
+	db.Create(&models.Todo{Title: "b", Project: "官网", UserID: alice.ID})
+	db.Create(&models.Todo{Title: "c", Project: "私事", UserID: alice.ID})
+	db.Create(&models.Todo{Title: "d", Project: "官网", UserID: 99})
+
+	link, err := s.Create(t.Context(), alice.ID, ShareRequest{Project: "官网", Permission: models.SharePermissionComment})
+	if err != nil {
+		t.Fatalf("创建分享失败: %v", err)
+	}
+	resolved, _ := s.Resolve(t.Context(), link.Token, "")
+	content, _ := s.Content(t.Context(), resolved)
+	if len(content.Todos) != 2 || content.Permission != models.SharePermissionComment {
+		t.Errorf("期望分享 2 条官网任务且可评论，但得到了 %+v", content)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	db.Model(&models.ShareLink{}).Where("id = ?", link.ID).Update("expires_at", past)
+	if _, err := s.Resolve(t.Context(), link.Token, ""); err != ErrShareInvalid {
+		t.Errorf("期望过期后链接失效，但得到了 %v", err)
+	}
+
+	if _, err := s.Create(t.Context(), alice.ID, ShareRequest{Project: "官网", Permission: "edit"}); err == nil {
+		t.Error("期望不支持的权限被拒绝")
+	}
+}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-todo/config"
+	"go-todo/models"
+	"go-todo/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SyncService 离线客户端的增量同步：拉取某个同步 token 之后的变更，
+// 并批量应用客户端的离线修改。所有写操作都通过 TodoService 完成
+type SyncService struct{}
+
+// 冲突解决策略
+const (
+	// ConflictLastWriteWins 整条任务比较修改时间，较新的一方获胜
+	ConflictLastWriteWins = "lww"
+	// ConflictFieldLevel 按字段三方合并：服务端没有改过的字段使用客户端的值
+	ConflictFieldLevel = "field"
+)
+
+// 客户端变更的操作类型
+const (
+	SyncUpsert = "upsert"
+	SyncDelete = "delete"
+)
+
+// 单条变更的处理结果
+const (
+	SyncApplied  = "applied"
+	SyncMerged   = "merged"
+	SyncConflict = "conflict"
+	SyncRejected = "rejected"
+)
+
+// SyncPageSize 每次最多返回的变更数量
+const SyncPageSize = 500
+
+// MaxSyncBatch 每次最多提交的客户端变更数量
+const MaxSyncBatch = 500
+
+// syncSettleWindow 同步 token 只推进到早于这个时间窗口的变更。
+// 自增 ID 在写入时分配、提交时才可见，ID 较小的事务可能晚于 ID 较大的事务提交，
+// 直接推进到最大 ID 会让客户端永久错过这类变更；窗口内的变更会在下一次同步中重复返回
+var syncSettleWindow = time.Minute
+
+// SyncFields 客户端可以修改的任务字段
+var SyncFields = []string{"title", "description", "status", "project", "due_date"}
+
+// SyncChange 客户端的一条离线修改
+// @Description 客户端变更，fields 只需要包含修改过的字段
+type SyncChange struct {
+	// 客户端生成的任务 ID，新建任务时必填
+	ClientID string `json:"client_id" example:"3f1c2a9e-8d4b-4c1e-9a57-0b6f3f2d1e7c"`
+	// 服务端任务 ID，已同步过的任务可以直接使用
+	ID string `json:"id" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	// 操作：upsert 或 delete
+	Op string `json:"op" example:"upsert"`
+	// 修改后的字段值（title、description、status、project、due_date）
+	Fields map[string]json.RawMessage `json:"fields" swaggertype:"object"`
+	// 字段级合并时，修改前的字段值
+	Base map[string]json.RawMessage `json:"base" swaggertype:"object"`
+	// 新建任务所属的工作区
+	WorkspaceID *uint `json:"workspace_id" example:"1"`
+	// 客户端修改时间，删除和最后写入获胜时必填
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SyncResult 一条客户端变更的处理结果
+// @Description 变更处理结果
+type SyncResult struct {
+	// 客户端 ID
+	ClientID string `json:"client_id" example:"3f1c2a9e-8d4b-4c1e-9a57-0b6f3f2d1e7c"`
+	// 服务端任务 ID
+	ID string `json:"id" example:"01928f3a-6b1c-7d2e-9f40-5a6b7c8d9e0f"`
+	// applied、merged（部分字段冲突）、conflict（服务端获胜）或 rejected
+	Status string `json:"status" example:"applied"`
+	// 发生冲突、保留了服务端值的字段
+	Conflicts []string `json:"conflicts,omitempty"`
+	// 拒绝原因
+	Error string `json:"error,omitempty"`
+	// 处理后服务端的任务，删除后为空
+	Todo *models.Todo `json:"todo,omitempty"`
+}
+
+// SyncPage 一次同步返回的变更
+// @Description 同步结果
+type SyncPage struct {
+	// 下次同步使用的 token
+	SyncToken string `json:"sync_token" example:"djE6MTI"`
+	// 还有更多变更时为 true，客户端应该立即用新 token 再次同步
+	HasMore bool `json:"has_more" example:"false"`
+	// 新建或修改过的任务
+	Todos []models.Todo `json:"todos"`
+	// 已删除的任务
+	Deleted []models.Tombstone `json:"deleted"`
+	// 客户端变更的处理结果（仅提交时返回）
+	Results []SyncResult `json:"results,omitempty"`
+}
+
+// Pull 返回 token 之后的变更；token 为空时返回全部可见任务的快照
+func (s *SyncService) Pull(ctx context.Context, userID uint, token string) (SyncPage, error) {
+	page := SyncPage{Todos: []models.Todo{}, Deleted: []models.Tombstone{}}
+	if token == "" {
+		return s.snapshot(ctx, userID)
+	}
+	since, err := decodeSyncToken(token)
+	if err != nil {
+		return page, err
+	}
+
+	joined := config.DB.WithContext(ctx).Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)
+	var changes []models.TodoChange
+	err = config.DB.WithContext(ctx).Where("id > ?", since).
+		Where("recipient_id = ? OR (recipient_id IS NULL AND ((user_id = ? AND workspace_id IS NULL) OR workspace_id IN (?)))", userID, userID, joined).
+		Order("id ASC").Limit(SyncPageSize + 1).Find(&changes).Error
+	if err != nil {
+		return page, err
+	}
+	if len(changes) > SyncPageSize {
+		changes = changes[:SyncPageSize]
+		page.HasMore = true
+	}
+
+	// 单独发给当前用户的变更和普通变更可能指向同一个任务，只返回最新的一条
+	latest := make(map[uint]uint, len(changes))
+	for _, c := range changes {
+		latest[c.TodoID] = c.ID
+	}
+	page.SyncToken = encodeSyncToken(since)
+	cutoff := time.Now().Add(-syncSettleWindow)
+	settled := true
+	var ids []uint
+	for _, c := range changes {
+		if settled && c.CreatedAt.After(cutoff) {
+			settled = false
+		}
+		if settled {
+			page.SyncToken = encodeSyncToken(c.ID)
+		}
+		if latest[c.TodoID] != c.ID {
+			continue
+		}
+		if c.Deleted {
+			page.Deleted = append(page.Deleted, models.Tombstone{ID: c.PublicID, ClientID: c.ClientID, DeletedAt: c.CreatedAt})
+		} else {
+			ids = append(ids, c.TodoID)
+		}
+	}
+	// token 停在窗口内的变更之前时，剩余的变更等下一次同步再返回
+	page.HasMore = page.HasMore && settled
+	if len(ids) > 0 {
+		// 读取期间被删除的任务会在下一次同步中以墓碑返回
+		err = config.DB.WithContext(ctx).Preload("Assignees", repository.PreloadAssignees).Where("id IN ?", ids).Order("id ASC").Find(&page.Todos).Error
+	}
+	if err == nil {
+		err = repository.FillCreators(config.DB.WithContext(ctx), page.Todos)
+	}
+	return page, err
+}
+
+// snapshot 首次同步：返回所有可见任务，token 取读取前早于 syncSettleWindow 的最新变更，
+// 之后的变更会在下一次同步中重复返回
+func (s *SyncService) snapshot(ctx context.Context, userID uint) (SyncPage, error) {
+	page := SyncPage{Todos: []models.Todo{}, Deleted: []models.Tombstone{}}
+	var latest uint
+	cutoff := time.Now().Add(-syncSettleWindow)
+	if err := config.DB.WithContext(ctx).Model(&models.TodoChange{}).Where("created_at <= ?", cutoff).Select("COALESCE(MAX(id), 0)").Scan(&latest).Error; err != nil {
+		return page, err
+	}
+	todos, err := defaultTodoService().Visible(ctx, userID)
+	if err != nil {
+		return page, err
+	}
+	if todos != nil {
+		page.Todos = todos
+	}
+	page.SyncToken = encodeSyncToken(latest)
+	return page, nil
+}
+
+// Sync 应用客户端的变更，再返回 token 之后的所有变更（包括本次提交产生的）
+func (s *SyncService) Sync(ctx context.Context, userID uint, token string, changes []SyncChange, strategy string) (SyncPage, error) {
+	// 先校验 token，避免变更已经应用却无法返回结果
+	if token != "" {
+		if _, err := decodeSyncToken(token); err != nil {
+			return SyncPage{}, err
+		}
+	}
+	results, err := s.Push(ctx, userID, changes, strategy)
+	if err != nil {
+		return SyncPage{}, err
+	}
+	page, err := s.Pull(ctx, userID, token)
+	page.Results = results
+	return page, err
+}
+
+// Push 依次应用客户端的变更，单条失败不影响其他变更
+func (s *SyncService) Push(ctx context.Context, userID uint, changes []SyncChange, strategy string) ([]SyncResult, error) {
+	if len(changes) > MaxSyncBatch {
+		return nil, fmt.Errorf("%w: 每次最多提交 %d 条变更", ErrSyncInvalid, MaxSyncBatch)
+	}
+	switch strategy {
+	case "":
+		strategy = ConflictLastWriteWins
+	case ConflictLastWriteWins, ConflictFieldLevel:
+	default:
+		return nil, fmt.Errorf("%w: 不支持的冲突策略 %s", ErrSyncInvalid, strategy)
+	}
+
+	results := make([]SyncResult, 0, len(changes))
+	for _, change := range changes {
+		result, err := s.apply(ctx, userID, change, strategy)
+		if err != nil {
+			result.Status = SyncRejected
+			result.Error = err.Error()
+			result.Todo = nil
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// errSyncNoUpdatedAt 删除或按 lww 修改已有任务时需要客户端的修改时间，零值总是比服务端旧，会一直冲突
+var errSyncNoUpdatedAt = fmt.Errorf("%w: 缺少 updated_at", ErrSyncInvalid)
+
+// apply 应用一条客户端变更
+func (s *SyncService) apply(ctx context.Context, userID uint, change SyncChange, strategy string) (SyncResult, error) {
+	result := SyncResult{ClientID: change.ClientID, ID: change.ID, Status: SyncApplied}
+	ts := defaultTodoService()
+	todo, err := findSyncTodo(ctx, userID, change)
+	exists := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return result, err
+	}
+	if exists {
+		result.ID = todo.PublicID
+		result.Todo = &todo
+	}
+
+	switch change.Op {
+	case SyncDelete:
+		if !exists {
+			return result, nil // 已经删除
+		}
+		if change.UpdatedAt.IsZero() {
+			return result, errSyncNoUpdatedAt
+		}
+		if change.UpdatedAt.Before(todo.UpdatedAt) {
+			// 客户端删除之后服务端又修改过，保留服务端的任务
+			result.Status = SyncConflict
+			return result, nil
+		}
+		result.Todo = nil
+		return result, ts.Delete(ctx, userID, todo.PublicID)
+
+	case SyncUpsert:
+		if !exists {
+			if change.ID != "" {
+				return result, errors.New("任务不存在或已被删除")
+			}
+			if change.ClientID == "" {
+				return result, errors.New("新建任务需要 client_id")
+			}
+			todo = models.Todo{ClientID: &change.ClientID, WorkspaceID: change.WorkspaceID}
+			if err := applySyncFields(&todo, change.Fields); err != nil {
+				return result, err
+			}
+			if err := ts.Create(ctx, userID, &todo); err != nil {
+				return result, err
+			}
+			result.ID = todo.PublicID
+			result.Todo = &todo
+			return result, nil
+		}
+
+		fields := change.Fields
+		if syncUnchanged(todo, fields) {
+			return result, nil // 重复提交或服务端已经是相同的值
+		}
+		if strategy == ConflictLastWriteWins {
+			if change.UpdatedAt.IsZero() {
+				return result, errSyncNoUpdatedAt
+			}
+			if change.UpdatedAt.Before(todo.UpdatedAt) {
+				result.Status = SyncConflict
+				return result, nil
+			}
+		} else {
+			fields, result.Conflicts = mergeSyncFields(todo, change.Fields, change.Base)
+			if len(result.Conflicts) > 0 {
+				result.Status = SyncMerged
+				if len(fields) == 0 {
+					result.Status = SyncConflict
+					return result, nil
+				}
+			}
+		}
+		if err := applySyncFields(&todo, fields); err != nil {
+			return result, err
+		}
+		if err := ts.Update(ctx, userID, &todo); err != nil {
+			return result, err
+		}
+		return result, nil
+
+	default:
+		return result, fmt.Errorf("不支持的操作 %q", change.Op)
+	}
+}
+
+// findSyncTodo 按服务端 ID 或客户端 ID 查找任务，并校验可见性。
+// 客户端 ID 只在同一个用户内唯一，只查找该用户自己创建的任务
+func findSyncTodo(ctx context.Context, userID uint, change SyncChange) (models.Todo, error) {
+	ts := defaultTodoService()
+	id := change.ID
+	if id == "" {
+		if change.ClientID == "" {
+			return models.Todo{}, gorm.ErrRecordNotFound
+		}
+		var found models.Todo
+		if err := config.DB.WithContext(ctx).Select("public_id").Where("user_id = ? AND client_id = ?", userID, change.ClientID).First(&found).Error; err != nil {
+			return found, err
+		}
+		id = found.PublicID
+	}
+	return ts.GetByID(ctx, userID, id)
+}
+
+// applySyncFields 把客户端的字段值写入任务，只接受 SyncFields 中的字段
+func applySyncFields(todo *models.Todo, fields map[string]json.RawMessage) error {
+	allowed := make(map[string]json.RawMessage)
+	for _, f := range SyncFields {
+		if v, ok := fields[f]; ok {
+			allowed[f] = v
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(allowed)
+	if err := json.Unmarshal(data, todo); err != nil {
+		return fmt.Errorf("字段格式错误: %v", err)
+	}
+	return nil
+}
+
+// mergeSyncFields 字段级三方合并：服务端当前值等于客户端的 base（服务端没改过），
+// 或者已经等于客户端的新值时采用客户端的值，否则该字段冲突、保留服务端的值
+func mergeSyncFields(todo models.Todo, fields, base map[string]json.RawMessage) (map[string]json.RawMessage, []string) {
+	merged := make(map[string]json.RawMessage)
+	var conflicts []string
+	for _, f := range SyncFields {
+		value, ok := fields[f]
+		if !ok {
+			continue
+		}
+		old, hasBase := base[f]
+		current := syncFieldValue(todo, f)
+		if !hasBase || syncRawValue(f, old) == current || syncRawValue(f, value) == current {
+			merged[f] = value
+		} else {
+			conflicts = append(conflicts, f)
+		}
+	}
+	return merged, conflicts
+}
+
+// syncUnchanged 客户端提交的字段是否都与服务端相同
+func syncUnchanged(todo models.Todo, fields map[string]json.RawMessage) bool {
+	for _, f := range SyncFields {
+		if value, ok := fields[f]; ok && syncRawValue(f, value) != syncFieldValue(todo, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// syncFieldValue 任务某个字段的可比较值
+func syncFieldValue(todo models.Todo, field string) interface{} {
+	switch field {
+	case "title":
+		return todo.Title
+	case "description":
+		return todo.Description
+	case "status":
+		return todo.Status
+	case "project":
+		return todo.Project
+	case "due_date":
+		if todo.DueDate == nil {
+			return nil
+		}
+		return todo.DueDate.UnixNano()
+	}
+	return nil
+}
+
+// syncRawValue 把客户端传来的 JSON 值解析成可比较的值（时间按时刻比较，忽略时区写法）
+func syncRawValue(field string, raw json.RawMessage) interface{} {
+	var todo models.Todo
+	if err := applySyncFields(&todo, map[string]json.RawMessage{field: raw}); err != nil {
+		return raw
+	}
+	return syncFieldValue(todo, field)
+}
+
+func encodeSyncToken(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("v1:" + strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeSyncToken(token string) (uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(raw), "v1:") {
+		return 0, ErrSyncToken
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(string(raw), "v1:"), 10, 64)
+	if err != nil {
+		return 0, ErrSyncToken
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-todo/config"
+	"go-todo/models"
+)
+
+// TestSyncPull 测试快照、增量变更和删除墓碑
+func TestSyncPull(t *testing.T) {
+	defer func(window time.Duration) { syncSettleWindow = window }(syncSettleWindow)
+	syncSettleWindow = 0
+	db := setupTestDB()
+	config.DB = db
+	s := &SyncService{}
+	ts := newTestTodoService(db)
+	user := createTestUser(t, "owner")
+	other := createTestUser(t, "other")
+
+	first := &models.Todo{Title: "第一个"}
+	ts.Create(t.Context(), user.ID, first)
+	ts.Create(t.Context(), other.ID, &models.Todo{Title: "别人的"})
+
+	page, err := s.Pull(t.Context(), user.ID, "")
+	if err != nil || len(page.Todos) != 1 || page.SyncToken == "" {
+		t.Fatalf("快照不正确: %+v, %v", page, err)
+	}
+
+	second := &models.Todo{Title: "第二个"}
+	ts.Create(t.Context(), user.ID, second)
+	first.Title = "第一个（改）"
+	ts.Update(t.Context(), user.ID, first)
+	ts.Update(t.Context(), user.ID, first) // 同一任务多次修改只返回一次
+	ts.Delete(t.Context(), user.ID, second.PublicID)
+
+	delta, err := s.Pull(t.Context(), user.ID, page.SyncToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delta.Todos) != 1 || delta.Todos[0].Title != "第一个（改）" {
+		t.Errorf("期望返回修改过的任务，但得到了 %+v", delta.Todos)
+	}
+	if len(delta.Deleted) != 1 || delta.Deleted[0].ID != second.PublicID {
+		t.Errorf("期望返回删除墓碑，但得到了 %+v", delta.Deleted)
+	}
+
+	// 没有新变更时 token 不变
+	again, _ := s.Pull(t.Context(), user.ID, delta.SyncToken)
+	if len(again.Todos)+len(again.Deleted) != 0 || again.SyncToken != delta.SyncToken {
+		t.Errorf("期望没有变更，但得到了 %+v", again)
+	}
+
+	if _, err := s.Pull(t.Context(), user.ID, "not-a-token"); err != ErrSyncToken {
+		t.Errorf("期望 token 无效，但得到了 %v", err)
+	}
+}
+
+// TestSyncPullSettleWindow 测试 token 不会推进到时间窗口内的变更，
+// 避免错过 ID 较小、提交较晚的变更
+func TestSyncPullSettleWindow(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &SyncService{}
+	ts := newTestTodoService(db)
+	user := createTestUser(t, "owner")
+
+	old := &models.Todo{Title: "旧任务"}
+	ts.Create(t.Context(), user.ID, old)
+	db.Model(&models.TodoChange{}).Where("todo_id = ?", old.ID).Update("created_at", time.Now().Add(-2*syncSettleWindow))
+	page, err := s.Pull(t.Context(), user.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := decodeSyncToken(page.SyncToken); token == 0 {
+		t.Errorf("期望快照 token 包含窗口之前的变更，但得到了 %d", token)
+	}
+
+	recent := &models.Todo{Title: "新任务"}
+	ts.Create(t.Context(), user.ID, recent)
+	delta, err := s.Pull(t.Context(), user.ID, page.SyncToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delta.Todos) != 1 || delta.Todos[0].ID != recent.ID {
+		t.Errorf("期望返回新变更，但得到了 %+v", delta.Todos)
+	}
+	if delta.SyncToken != page.SyncToken {
+		t.Error("token 不应该推进到窗口内的变更")
+	}
+
+	// 变更超出窗口后 token 才会推进
+	db.Model(&models.TodoChange{}).Where("todo_id = ?", recent.ID).Update("created_at", time.Now().Add(-2*syncSettleWindow))
+	delta, _ = s.Pull(t.Context(), user.ID, delta.SyncToken)
+	if len(delta.Todos) != 1 || delta.SyncToken == page.SyncToken {
+		t.Fatalf("期望 token 推进，但得到了 %+v", delta)
+	}
+	again, _ := s.Pull(t.Context(), user.ID, delta.SyncToken)
+	if len(again.Todos)+len(again.Deleted) != 0 {
+		t.Errorf("期望没有变更，但得到了 %+v", again)
+	}
+}
+
+// TestSyncWorkspaceMembership 测试加入工作区时补发已有任务，离开或工作区被删除时收到墓碑
+func TestSyncWorkspaceMembership(t *testing.T) {
+	defer func(window time.Duration) { syncSettleWindow = window }(syncSettleWindow)
+	syncSettleWindow = 0
+	db := setupTestDB()
+	config.DB = db
+	s := &SyncService{}
+	ws := &WorkspaceService{}
+	ts := newTestTodoService(db)
+	owner := createTestUser(t, "owner")
+	member := createTestUser(t, "member")
+
+	workspace, _ := ws.Create(t.Context(), owner.ID, "团队")
+	shared := &models.Todo{Title: "共享任务", WorkspaceID: &workspace.ID}
+	if err := ts.Create(t.Context(), owner.ID, shared); err != nil {
+		t.Fatal(err)
+	}
+	ownerPage, _ := s.Pull(t.Context(), owner.ID, "")
+	memberPage, _ := s.Pull(t.Context(), member.ID, "")
+	if len(memberPage.Todos) != 0 {
+		t.Fatalf("加入前不应该看到工作区任务: %+v", memberPage.Todos)
+	}
+
+	invite, _ := ws.Invite(t.Context(), owner.ID, workspace.ID, "member", models.WorkspaceEditor, 0)
+	if _, err := ws.AcceptInvite(t.Context(), member.ID, invite.Token); err != nil {
+		t.Fatal(err)
+	}
+	memberPage, err := s.Pull(t.Context(), member.ID, memberPage.SyncToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memberPage.Todos) != 1 || memberPage.Todos[0].ID != shared.ID {
+		t.Errorf("期望加入后补发工作区任务，但得到了 %+v", memberPage.Todos)
+	}
+	// 补发的变更只发给新成员
+	if again, _ := s.Pull(t.Context(), owner.ID, ownerPage.SyncToken); len(again.Todos) != 0 {
+		t.Errorf("补发的变更不应该发给其他成员: %+v", again.Todos)
+	}
+
+	if err := ws.RemoveMember(t.Context(), owner.ID, workspace.ID, member.ID); err != nil {
+		t.Fatal(err)
+	}
+	memberPage, _ = s.Pull(t.Context(), member.ID, memberPage.SyncToken)
+	if len(memberPage.Todos) != 0 || len(memberPage.Deleted) != 1 || memberPage.Deleted[0].ID != shared.PublicID {
+		t.Errorf("期望离开后收到墓碑，但得到了 %+v", memberPage)
+	}
+	// 之后的修改不会冲掉墓碑，也不会发给已离开的成员
+	removedToken := memberPage.SyncToken
+	shared.Title = "共享任务（改）"
+	ts.Update(t.Context(), owner.ID, shared)
+	if page, _ := s.Pull(t.Context(), member.ID, ""); len(page.Todos) != 0 {
+		t.Errorf("离开后不应该看到工作区任务: %+v", page.Todos)
+	}
+	if page, _ := s.Pull(t.Context(), member.ID, removedToken); len(page.Todos)+len(page.Deleted) != 0 {
+		t.Errorf("期望没有变更，但得到了 %+v", page)
+	}
+
+	// 重新加入后再删除工作区，成员同样收到墓碑
+	invite, _ = ws.Invite(t.Context(), owner.ID, workspace.ID, "member", models.WorkspaceEditor, 0)
+	ws.AcceptInvite(t.Context(), member.ID, invite.Token)
+	memberPage, _ = s.Pull(t.Context(), member.ID, removedToken)
+	if len(memberPage.Todos) != 1 || len(memberPage.Deleted) != 0 {
+		t.Fatalf("期望重新加入后补发任务，但得到了 %+v", memberPage)
+	}
+	if err := ws.Delete(t.Context(), owner.ID, workspace.ID); err != nil {
+		t.Fatal(err)
+	}
+	memberPage, _ = s.Pull(t.Context(), member.ID, memberPage.SyncToken)
+	if len(memberPage.Deleted) != 1 || memberPage.Deleted[0].ID != shared.PublicID {
+		t.Errorf("期望工作区删除后收到墓碑，但得到了 %+v", memberPage)
+	}
+}
+
+// TestSyncPush 测试客户端 ID 创建、最后写入获胜和字段级合并
+func TestSyncPush(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &SyncService{}
+	ts := newTestTodoService(db)
+	user := createTestUser(t, "owner")
+	raw := func(v interface{}) json.RawMessage {
+		b, _ := json.Marshal(v)
+		return b
+	}
+
+	// 离线新建，重复提交不会产生重复任务
+	create := SyncChange{ClientID: "c-1", Op: SyncUpsert, UpdatedAt: time.Now(),
+		Fields: map[string]json.RawMessage{"title": raw("离线任务"), "project": raw("工作")}}
+	page, err := s.Sync(t.Context(), user.ID, "", []SyncChange{create, create}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Results[0].Status != SyncApplied || page.Results[1].Status != SyncApplied || len(page.Todos) != 1 {
+		t.Fatalf("离线新建结果不正确: %+v", page)
+	}
+	todo := page.Todos[0]
+	if todo.ClientID == nil || *todo.ClientID != "c-1" || todo.Project != "工作" {
+		t.Errorf("任务字段不正确: %+v", todo)
+	}
+
+	// 最后写入获胜：客户端修改时间早于服务端时服务端获胜
+	stale := SyncChange{ClientID: "c-1", Op: SyncUpsert, UpdatedAt: todo.UpdatedAt.Add(-time.Hour),
+		Fields: map[string]json.RawMessage{"title": raw("旧标题")}}
+	results, _ := s.Push(t.Context(), user.ID, []SyncChange{stale}, ConflictLastWriteWins)
+	if results[0].Status != SyncConflict || results[0].Todo.Title != "离线任务" {
+		t.Errorf("期望服务端获胜，但得到了 %+v", results[0])
+	}
+
+	// 字段级合并：服务端改了标题，客户端同时改了标题和状态
+	server, _ := ts.GetByID(t.Context(), user.ID, todo.PublicID)
+	server.Title = "服务端标题"
+	ts.Update(t.Context(), user.ID, &server)
+	merge := SyncChange{ID: todo.PublicID, Op: SyncUpsert,
+		Fields: map[string]json.RawMessage{"title": raw("客户端标题"), "status": raw(true)},
+		Base:   map[string]json.RawMessage{"title": raw("离线任务"), "status": raw(false)}}
+	results, _ = s.Push(t.Context(), user.ID, []SyncChange{merge}, ConflictFieldLevel)
+	r := results[0]
+	if r.Status != SyncMerged || len(r.Conflicts) != 1 || r.Conflicts[0] != "title" {
+		t.Fatalf("期望标题冲突、状态合并，但得到了 %+v", r)
+	}
+	if r.Todo.Title != "服务端标题" || !r.Todo.Status {
+		t.Errorf("合并结果不正确: %+v", r.Todo)
+	}
+
+	// 删除
+	results, _ = s.Push(t.Context(), user.ID, []SyncChange{{ClientID: "c-1", Op: SyncDelete, UpdatedAt: time.Now().Add(time.Minute)}}, "")
+	if results[0].Status != SyncApplied {
+		t.Errorf("删除失败: %+v", results[0])
+	}
+	if _, err := ts.GetByID(t.Context(), user.ID, todo.PublicID); err == nil {
+		t.Error("期望任务已被删除")
+	}
+
+	if _, err := s.Push(t.Context(), user.ID, nil, "magic"); err == nil {
+		t.Error("期望拒绝未知的冲突策略")
+	}
+}
+
+// TestSyncPushClientIDPerUser 测试客户端 ID 只在同一个用户内唯一，以及缺少修改时间的变更被拒绝
+func TestSyncPushClientIDPerUser(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := &SyncService{}
+	user := createTestUser(t, "owner")
+	other := createTestUser(t, "other")
+	fields := map[string]json.RawMessage{"title": json.RawMessage(`"离线任务"`)}
+
+	create := SyncChange{ClientID: "c-1", Op: SyncUpsert, Fields: fields}
+	mine, _ := s.Push(t.Context(), user.ID, []SyncChange{create}, "")
+	theirs, _ := s.Push(t.Context(), other.ID, []SyncChange{create}, "")
+	if mine[0].Status != SyncApplied || theirs[0].Status != SyncApplied || mine[0].ID == theirs[0].ID {
+		t.Fatalf("不同用户使用相同的客户端 ID 应该各自新建任务: %+v, %+v", mine[0], theirs[0])
+	}
+
+	// 另一个用户的删除不会影响到自己的任务
+	del := SyncChange{ClientID: "c-1", Op: SyncDelete, UpdatedAt: time.Now().Add(time.Minute)}
+	s.Push(t.Context(), other.ID, []SyncChange{del}, "")
+	if _, err := newTestTodoService(db).GetByID(t.Context(), user.ID, mine[0].ID); err != nil {
+		t.Errorf("期望自己的任务仍然存在，但得到了 %v", err)
+	}
+
+	update := SyncChange{ClientID: "c-1", Op: SyncUpsert, Fields: map[string]json.RawMessage{"title": json.RawMessage(`"新标题"`)}}
+	for _, change := range []SyncChange{update, {ClientID: "c-1", Op: SyncDelete}} {
+		results, _ := s.Push(t.Context(), user.ID, []SyncChange{change}, ConflictLastWriteWins)
+		if results[0].Status != SyncRejected || results[0].Error == "" {
+			t.Errorf("期望缺少 updated_at 的 %s 被拒绝，但得到了 %+v", change.Op, results[0])
+		}
+	}
+}
@@ -1,58 +1,374 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"go-todo/config"
+	"go-todo/events"
+	"go-todo/metrics"
 	"go-todo/models"
+	"go-todo/repository"
+	"go-todo/tracing"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-// 定义一个结构体，方便以后扩展（比如注入不同的 DB）
-type TodoService struct{}
+// TodoService 任务的业务逻辑：权限校验、查询条件和变更通知，数据访问都通过 TodoRepository
+type TodoService struct {
+    todos   repository.TodoRepository
+    publish Publisher
+}
+
+// Publisher 发布任务事件，audience 是能看到该任务的用户
+type Publisher func(eventType string, data interface{}, audience []uint)
+
+// NewTodoService 创建 TodoService，publish 为 nil 时不发布事件
+func NewTodoService(todos repository.TodoRepository, publish Publisher) *TodoService {
+    return &TodoService{todos: todos, publish: publish}
+}
+
+// defaultTodoService 还没有改成依赖注入的服务（评论、附件、分享、同步）内部使用的 TodoService
+func defaultTodoService() *TodoService {
+    return NewTodoService(repository.NewGormTodoRepository(config.DB), PublishEvents)
+}
+
+// TodoSorts 支持的排序方式，key 会出现在用户偏好和查询参数中
+var TodoSorts = repository.TodoSorts
+
+// DefaultTodoSort 未指定排序时的默认值（与最早的按 ID 顺序返回保持一致）
+const DefaultTodoSort = repository.DefaultTodoSort
+
+// TodoQuery 任务列表的查询条件
+type TodoQuery struct {
+    Page     int
+    PageSize int
+    // 按项目过滤，为空表示不过滤
+    Project string
+    // 排序方式，取值见 TodoSorts
+    Sort string
+    // 截止时间过滤：today / week / overdue
+    Due string
+    // 工作区 ID，为空表示查询个人任务
+    WorkspaceID *uint
+    // 跨工作区查询：个人任务加上所有已加入工作区的任务，此时忽略 WorkspaceID
+    AllWorkspaces bool
+    // 按负责人过滤
+    AssigneeID *uint
+    // 只返回没有负责人的任务
+    Unassigned bool
+    // 解析日期时使用的时区和每周起始日（来自用户偏好）
+    Location  *time.Location
+    WeekStart int
+}
+
+func (s *TodoService) GetAll(ctx context.Context, userID uint, page int, pageSize int) ([]models.Todo, int64, error) {
+    ctx, span := tracing.Start(ctx, "TodoService.GetAll")
+    defer span.End()
+    return s.List(ctx, userID, TodoQuery{Page: page, PageSize: pageSize})
+}
+
+// MaxPageSize 分页查询每页最多返回的数量
+const MaxPageSize = 100
 
-func (s *TodoService) GetAll(userID uint, page int, pageSize int) ([]models.Todo, int64, error) {
-    var todos []models.Todo
-    var total int64
-    
+// List 按条件分页查询当前用户的任务
+func (s *TodoService) List(ctx context.Context, userID uint, q TodoQuery) ([]models.Todo, int64, error) {
+    ctx, span := tracing.Start(ctx, "TodoService.List")
+    defer span.End()
     // 计算分页的 offset
-    if page < 1 {
-        page = 1
+    if q.Page < 1 {
+        q.Page = 1
+    }
+    if q.PageSize < 1 {
+        q.PageSize = 10 // 默认每页 10 条
+    }
+    if q.PageSize > MaxPageSize {
+        q.PageSize = MaxPageSize
+    }
+
+    f := repository.TodoFilter{
+        UserID:        userID,
+        WorkspaceID:   q.WorkspaceID,
+        AllWorkspaces: q.AllWorkspaces,
+        Project:       q.Project,
+        AssigneeID:    q.AssigneeID,
+        Unassigned:    q.Unassigned,
+        Sort:          q.Sort,
+        Offset:        (q.Page - 1) * q.PageSize,
+        Limit:         q.PageSize,
+    }
+    // 查询工作区的任务需要是成员
+    if !q.AllWorkspaces && q.WorkspaceID != nil {
+        if _, err := s.role(ctx, userID, *q.WorkspaceID); err != nil {
+            return nil, 0, err
+        }
+    }
+    if q.Due != "" {
+        if err := applyDueFilter(&f, q); err != nil {
+            return nil, 0, err
+        }
+    }
+    return s.todos.List(ctx, f)
+}
+
+// Visible 用户能看到的所有任务：个人任务和所有已加入工作区的任务，按创建顺序返回
+func (s *TodoService) Visible(ctx context.Context, userID uint) ([]models.Todo, error) {
+    ctx, span := tracing.Start(ctx, "TodoService.Visible")
+    defer span.End()
+    todos, _, err := s.todos.List(ctx, repository.TodoFilter{UserID: userID, AllWorkspaces: true, Sort: DefaultTodoSort})
+    return todos, err
+}
+
+// ListAssigned 查询分配给当前用户的任务，范围包括个人任务和所有已加入的工作区
+func (s *TodoService) ListAssigned(ctx context.Context, userID uint, q TodoQuery) ([]models.Todo, int64, error) {
+    ctx, span := tracing.Start(ctx, "TodoService.ListAssigned")
+    defer span.End()
+    q.AllWorkspaces = true
+    q.AssigneeID = &userID
+    q.Unassigned = false
+    return s.List(ctx, userID, q)
+}
+
+// applyDueFilter 按用户时区计算"今天"/"本周"的边界
+func applyDueFilter(f *repository.TodoFilter, q TodoQuery) error {
+    loc := q.Location
+    if loc == nil {
+        loc = time.UTC
+    }
+    now := time.Now().In(loc)
+    today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+    switch q.Due {
+    case "today":
+        tomorrow := today.AddDate(0, 0, 1)
+        f.DueFrom, f.DueBefore = &today, &tomorrow
+    case "week":
+        offset := (int(today.Weekday()) - q.WeekStart + 7) % 7
+        start := today.AddDate(0, 0, -offset)
+        end := start.AddDate(0, 0, 7)
+        f.DueFrom, f.DueBefore = &start, &end
+    case "overdue":
+        f.DueBefore = &now
+        f.Undone = true
+    default:
+        return ErrInvalidDueFilter
+    }
+    return nil
+}
+
+// role 返回用户在工作区中的角色，不是成员时返回 ErrForbidden
+func (s *TodoService) role(ctx context.Context, userID, workspaceID uint) (string, error) {
+    role, err := s.todos.MemberRole(ctx, workspaceID, userID)
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return "", ErrForbidden
+    }
+    return role, err
+}
+
+// authorize 校验用户对任务的权限：个人任务只有创建者可以访问，
+// 工作区任务所有成员可读，owner/editor 可写
+func (s *TodoService) authorize(ctx context.Context, userID uint, todo models.Todo, write bool) error {
+    if todo.WorkspaceID == nil {
+        if todo.UserID != userID {
+            return gorm.ErrRecordNotFound
+        }
+        return nil
     }
-    if pageSize < 1 {
-        pageSize = 10 // 默认每页 10 条
+    role, err := s.role(ctx, userID, *todo.WorkspaceID)
+    if errors.Is(err, ErrForbidden) {
+        // 不是成员时和任务不存在一样处理，避免泄露任务是否存在
+        return gorm.ErrRecordNotFound
     }
-    offset := (page - 1) * pageSize
-    
-    // 先查询总数
-    err := config.DB.Where("user_id = ?", userID).Model(&models.Todo{}).Count(&total).Error
     if err != nil {
-        return nil, 0, err
+        return err
+    }
+    if write && !canEdit(role) {
+        return ErrForbidden
     }
-    
-    // 查询分页数据
-    err = config.DB.Where("user_id = ?", userID).Offset(offset).Limit(pageSize).Find(&todos).Error
-    return todos, total, err
+    return nil
 }
 
-func (s *TodoService) Create(userID uint, todo *models.Todo) error {
-    // 确保设置正确的用户ID
+func (s *TodoService) Create(ctx context.Context, userID uint, todo *models.Todo) error {
+    ctx, span := tracing.Start(ctx, "TodoService.Create")
+    defer span.End()
+    // 确保设置正确的用户ID，公开 ID 由服务端生成
     todo.UserID = userID
-    return config.DB.Create(todo).Error
+    todo.PublicID = ""
+    // 负责人需要通过单独的接口分配
+    todo.Assignees = nil
+    // 在工作区中创建任务需要 editor 以上角色
+    if todo.WorkspaceID != nil {
+        role, err := s.role(ctx, userID, *todo.WorkspaceID)
+        if err != nil {
+            return err
+        }
+        if !canEdit(role) {
+            return ErrForbidden
+        }
+    }
+    if err := s.todos.Create(ctx, todo); err != nil {
+        return err
+    }
+    metrics.TodosCreated.Inc()
+    s.publishTodo(ctx, events.TodoCreated, *todo)
+    return nil
 }
 
-func (s *TodoService) GetByID(userID uint, id string) (models.Todo, error) {
-    var todo models.Todo
-    // 添加 user_id 条件，确保只能访问自己的 todo
-    err := config.DB.Where("user_id = ?", userID).First(&todo, id).Error
+// GetByID 按公开 ID 查询任务
+func (s *TodoService) GetByID(ctx context.Context, userID uint, id string) (models.Todo, error) {
+    ctx, span := tracing.Start(ctx, "TodoService.GetByID")
+    defer span.End()
+    todo, err := s.todos.FindByPublicID(ctx, id)
+    if err != nil {
+        return todo, err
+    }
+    // 确保只能访问自己的或所在工作区的 todo
+    err = s.authorize(ctx, userID, todo, false)
     return todo, err
 }
 
-func (s *TodoService) Update(userID uint, todo *models.Todo) error {
-    // 确保 user_id 不被篡改
-    todo.UserID = userID
-    // 使用 Where 条件确保只能更新自己的 todo
-    return config.DB.Where("user_id = ?", userID).Save(todo).Error
+func (s *TodoService) Update(ctx context.Context, userID uint, todo *models.Todo) error {
+    ctx, span := tracing.Start(ctx, "TodoService.Update")
+    defer span.End()
+    existing, err := s.todos.FindByID(ctx, todo.ID)
+    if err != nil {
+        return err
+    }
+    if err := s.authorize(ctx, userID, existing, true); err != nil {
+        return err
+    }
+    // 确保创建者、所属工作区、公开 ID、客户端 ID 和创建时间不被篡改
+    todo.UserID = existing.UserID
+    todo.WorkspaceID = existing.WorkspaceID
+    todo.PublicID = existing.PublicID
+    todo.ClientID = existing.ClientID
+    todo.CreatedAt = existing.CreatedAt
+    if err := s.todos.Update(ctx, todo); err != nil {
+        return err
+    }
+    if todo.Status && !existing.Status {
+        metrics.TodosCompleted.Inc()
+    }
+    s.publishTodo(ctx, events.TodoUpdated, *todo)
+    return nil
+}
+
+// Delete 按公开 ID 删除任务
+func (s *TodoService) Delete(ctx context.Context, userID uint, id string) error {
+    ctx, span := tracing.Start(ctx, "TodoService.Delete")
+    defer span.End()
+    todo, err := s.todos.FindByPublicID(ctx, id)
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil // 删除是幂等的，任务不存在时直接返回
+    }
+    if err != nil {
+        return err
+    }
+    err = s.authorize(ctx, userID, todo, true)
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil // 看不到的任务同样视为不存在
+    }
+    if err != nil {
+        return err
+    }
+    blobs, err := s.todos.Delete(ctx, todo)
+    if err != nil {
+        return err
+    }
+    removeBlobs(ctx, blobs)
+    s.publishTodo(ctx, events.TodoDeleted, todo)
+    return nil
 }
 
-func (s *TodoService) Delete(userID uint, id string) error {
-    // 添加 user_id 条件，确保只能删除自己的 todo
-    return config.DB.Where("user_id = ?", userID).Delete(&models.Todo{}, id).Error
-}
\ No newline at end of file
+// Assign 把任务分配给某个用户：个人任务只能分配给自己，工作区任务可以分配给任意成员
+func (s *TodoService) Assign(ctx context.Context, userID uint, id string, assigneeID uint) (models.Todo, error) {
+    ctx, span := tracing.Start(ctx, "TodoService.Assign")
+    defer span.End()
+    todo, err := s.GetByID(ctx, userID, id)
+    if err != nil {
+        return todo, err
+    }
+    if err := s.authorize(ctx, userID, todo, true); err != nil {
+        return todo, err
+    }
+    if todo.WorkspaceID == nil {
+        if assigneeID != todo.UserID {
+            return todo, ErrInvalidAssignee
+        }
+    } else if _, err := s.role(ctx, assigneeID, *todo.WorkspaceID); err != nil {
+        return todo, ErrInvalidAssignee
+    }
+
+    assignee := models.TodoAssignee{TodoID: todo.ID, UserID: assigneeID, AssignedBy: userID}
+    if err := s.todos.AddAssignee(ctx, assignee); err != nil {
+        return todo, err
+    }
+    return s.reloadAndPublish(ctx, userID, id)
+}
+
+// Unassign 取消任务的某个负责人
+func (s *TodoService) Unassign(ctx context.Context, userID uint, id string, assigneeID uint) (models.Todo, error) {
+    ctx, span := tracing.Start(ctx, "TodoService.Unassign")
+    defer span.End()
+    todo, err := s.GetByID(ctx, userID, id)
+    if err != nil {
+        return todo, err
+    }
+    if err := s.authorize(ctx, userID, todo, true); err != nil {
+        return todo, err
+    }
+    if err := s.todos.RemoveAssignee(ctx, todo.ID, assigneeID); err != nil {
+        return todo, err
+    }
+    return s.reloadAndPublish(ctx, userID, id)
+}
+
+// reloadAndPublish 重新读取任务（包含负责人），记录变更并推送更新事件
+func (s *TodoService) reloadAndPublish(ctx context.Context, userID uint, id string) (models.Todo, error) {
+    todo, err := s.GetByID(ctx, userID, id)
+    if err != nil {
+        return todo, err
+    }
+    if err := s.todos.RecordChange(ctx, todo); err != nil {
+        return todo, err
+    }
+    s.publishTodo(ctx, events.TodoUpdated, todo)
+    return todo, nil
+}
+
+// audience 能看到任务的用户：个人任务只有创建者，工作区任务是所有成员
+func (s *TodoService) audience(ctx context.Context, todo models.Todo) []uint {
+    if todo.WorkspaceID == nil {
+        return []uint{todo.UserID}
+    }
+    userIDs, err := s.todos.Members(ctx, *todo.WorkspaceID)
+    if err != nil {
+        slog.ErrorContext(ctx, "查询工作区成员失败", "workspace_id", *todo.WorkspaceID, "error", err)
+    }
+    return userIDs
+}
+
+// publishTodo 把任务事件发布给能看到该任务的用户；删除事件只带任务 ID。
+// 任务已经写入，即使请求随后超时或被取消也要发布事件
+func (s *TodoService) publishTodo(ctx context.Context, eventType string, todo models.Todo) {
+    if s.publish == nil {
+        return
+    }
+    ctx = context.WithoutCancel(ctx)
+    var data interface{} = todo
+    if eventType == events.TodoDeleted {
+        data = map[string]interface{}{"id": todo.PublicID, "workspace_id": todo.WorkspaceID}
+    }
+    s.publish(eventType, data, s.audience(ctx, todo))
+}
+
+// PublishEvents 默认的 Publisher：推送实时事件，并投递给用户订阅的 Webhook
+func PublishEvents(eventType string, data interface{}, audience []uint) {
+    e, err := config.Events.Publish(eventType, data, audience...)
+    if err != nil {
+        slog.Error("推送任务事件失败", "type", eventType, "error", err)
+        return
+    }
+    enqueueWebhooks(e, audience)
+}
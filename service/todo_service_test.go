@@ -1,11 +1,17 @@
 package service
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"go-todo/config"
+	"go-todo/events"
+	"go-todo/migrations"
 	"go-todo/models"
+	"go-todo/repository"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
@@ -14,18 +20,44 @@ import (
 
 // 初始化测试用的数据库
 func setupTestDB() *gorm.DB {
-	db, _ := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+	// 与生产环境一样开启外键约束
+	db, _ := gorm.Open(sqlite.Open("file::memory:?_pragma=foreign_keys(1)"), &gorm.Config{
         Logger: logger.Default.LogMode(logger.Silent), 
     })
-    db.AutoMigrate(&models.Todo{})
+    // 与生产环境一样通过迁移建表
+    m, err := migrations.New(db)
+    if err == nil {
+        _, err = m.Up()
+    }
+    if err != nil {
+        panic(err)
+    }
     return db
 }
 
+// seedUsers 创建 ID 为 1 到 n 的用户，供直接按用户 ID 写入任务的测试使用
+func seedUsers(db *gorm.DB, n int) {
+	for i := 1; i <= n; i++ {
+		db.Create(&models.User{Username: "user" + strconv.Itoa(i)})
+	}
+}
+
+// newTestTodoService 基于测试数据库创建 TodoService
+func newTestTodoService(db *gorm.DB) *TodoService {
+	return NewTodoService(repository.NewGormTodoRepository(db), PublishEvents)
+}
+
+// newTestUserService 基于测试数据库创建 UserService
+func newTestUserService(db *gorm.DB) *UserService {
+	return NewUserService(repository.NewGormUserRepository(db))
+}
+
 // TestGetAll 测试获取用户的所有 todo（支持分页）
 func TestGetAll(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
 
 	// 为用户 1 创建测试数据
 	db.Create(&models.Todo{Title: "任务1", Status: false, UserID: 1})
@@ -33,7 +65,7 @@ func TestGetAll(t *testing.T) {
 	db.Create(&models.Todo{Title: "任务3", Status: false, UserID: 2}) // 其他用户的任务
 
 	// 测试获取用户 1 的所有任务（第1页，每页10条）
-	todos, total, err := s.GetAll(1, 1, 10)
+	todos, total, err := s.GetAll(t.Context(), 1, 1, 10)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -60,7 +92,8 @@ func TestGetAll(t *testing.T) {
 func TestGetAll_Pagination(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
 
 	// 为用户 1 创建 15 个任务
 	for i := 1; i <= 15; i++ {
@@ -69,7 +102,7 @@ func TestGetAll_Pagination(t *testing.T) {
 	}
 
 	// 测试第 1 页，每页 10 条
-	todos, total, err := s.GetAll(1, 1, 10)
+	todos, total, err := s.GetAll(t.Context(), 1, 1, 10)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -81,7 +114,7 @@ func TestGetAll_Pagination(t *testing.T) {
 	}
 
 	// 测试第 2 页，每页 10 条
-	todos, total, err = s.GetAll(1, 2, 10)
+	todos, total, err = s.GetAll(t.Context(), 1, 2, 10)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -97,7 +130,8 @@ func TestGetAll_Pagination(t *testing.T) {
 func TestGetAll_DefaultPageSize(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
 
 	// 为用户 1 创建 25 个任务
 	for i := 1; i <= 25; i++ {
@@ -106,7 +140,7 @@ func TestGetAll_DefaultPageSize(t *testing.T) {
 	}
 
 	// 测试画幅参数（page < 1 时默认至 1，pageSize < 1 时默认至10）
-	todos, total, err := s.GetAll(1, 0, 0)
+	todos, total, err := s.GetAll(t.Context(), 1, 0, 0)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -118,15 +152,67 @@ func TestGetAll_DefaultPageSize(t *testing.T) {
 	}
 }
 
+// TestList_ProjectAndSort 测试按项目过滤和排序
+func TestList_ProjectAndSort(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
+
+	db.Create(&models.Todo{Title: "b", Project: "工作", UserID: 1})
+	db.Create(&models.Todo{Title: "a", Project: "工作", UserID: 1})
+	db.Create(&models.Todo{Title: "c", Project: "生活", UserID: 1})
+
+	todos, total, err := s.List(t.Context(), 1, TodoQuery{Project: "工作", Sort: "title_asc"})
+	if err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+	if total != 2 || len(todos) != 2 {
+		t.Fatalf("期望返回 2 条工作任务，但得到了 %d 条（总数 %d）", len(todos), total)
+	}
+	if todos[0].Title != "a" || todos[1].Title != "b" {
+		t.Errorf("期望按标题排序为 a, b，但得到了 %s, %s", todos[0].Title, todos[1].Title)
+	}
+}
+
+// TestList_DueFilter 测试按用户时区过滤今天到期的任务
+func TestList_DueFilter(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
+
+	loc := time.FixedZone("UTC+8", 8*3600)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, loc)
+	tomorrow := today.AddDate(0, 0, 1)
+	db.Create(&models.Todo{Title: "今天", DueDate: &today, UserID: 1})
+	db.Create(&models.Todo{Title: "明天", DueDate: &tomorrow, UserID: 1})
+	db.Create(&models.Todo{Title: "无截止", UserID: 1})
+
+	todos, _, err := s.List(t.Context(), 1, TodoQuery{Due: "today", Location: loc})
+	if err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+	if len(todos) != 1 || todos[0].Title != "今天" {
+		t.Errorf("期望只返回今天到期的任务，但得到了 %v", todos)
+	}
+
+	if _, _, err := s.List(t.Context(), 1, TodoQuery{Due: "someday"}); err != ErrInvalidDueFilter {
+		t.Errorf("期望返回 ErrInvalidDueFilter，但得到了 %v", err)
+	}
+}
+
 // TestCreate 测试创建 todo
 func TestCreate(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
 
 	// 创建一个新的 todo
 	todo := &models.Todo{Title: "新任务", Status: false}
-	err := s.Create(1, todo)
+	err := s.Create(t.Context(), 1, todo)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -148,7 +234,8 @@ func TestCreate(t *testing.T) {
 func TestGetByID(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
 
 	// 为用户 1 创建一个任务
 	todo := &models.Todo{Title: "任务1", Status: false, UserID: 1}
@@ -159,7 +246,7 @@ func TestGetByID(t *testing.T) {
 	db.Create(todo2)
 
 	// 用户 1 可以获取自己的任务
-	retrievedTodo, err := s.GetByID(1, toString(todo.ID))
+	retrievedTodo, err := s.GetByID(t.Context(), 1, todo.PublicID)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -168,7 +255,7 @@ func TestGetByID(t *testing.T) {
 	}
 
 	// 用户 1 不能获取用户 2 的任务
-	_, err = s.GetByID(1, toString(todo2.ID))
+	_, err = s.GetByID(t.Context(), 1, todo2.PublicID)
 	if err == nil {
 		t.Error("期望用户 1 无法访问用户 2 的任务，但没有返回错误")
 	}
@@ -178,7 +265,8 @@ func TestGetByID(t *testing.T) {
 func TestUpdate(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
 
 	// 为用户 1 创建一个任务
 	todo := &models.Todo{Title: "原始标题", Status: false, UserID: 1}
@@ -187,7 +275,7 @@ func TestUpdate(t *testing.T) {
 	// 更新任务
 	todo.Title = "新标题"
 	todo.Status = true
-	err := s.Update(1, todo)
+	err := s.Update(t.Context(), 1, todo)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -207,7 +295,8 @@ func TestUpdate(t *testing.T) {
 func TestUpdate_UserIDProtection(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
 
 	// 为用户 1 创建一个任务
 	todo := &models.Todo{Title: "任务", Status: false, UserID: 1}
@@ -215,7 +304,7 @@ func TestUpdate_UserIDProtection(t *testing.T) {
 
 	// 尝试将任务的 UserID 改为 2
 	todo.UserID = 2
-	err := s.Update(1, todo)
+	err := s.Update(t.Context(), 1, todo)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -228,18 +317,80 @@ func TestUpdate_UserIDProtection(t *testing.T) {
 	}
 }
 
+// TestUpdate_CreatedAtProtection 更新时不能修改创建时间
+func TestUpdate_CreatedAtProtection(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
+
+	todo := &models.Todo{Title: "任务", UserID: 1}
+	db.Create(todo)
+	createdAt := todo.CreatedAt
+
+	todo.CreatedAt = createdAt.AddDate(-1, 0, 0)
+	if err := s.Update(t.Context(), 1, todo); err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+
+	var updatedTodo models.Todo
+	db.First(&updatedTodo, todo.ID)
+	if !updatedTodo.CreatedAt.Equal(createdAt) || !todo.CreatedAt.Equal(createdAt) {
+		t.Errorf("期望创建时间仍然是 %v，但得到了 %v（创建时间不应该被篡改）", createdAt, updatedTodo.CreatedAt)
+	}
+}
+
+// TestPublicID 对外只能使用公开 ID，客户端不能指定公开 ID
+func TestPublicID(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := newTestTodoService(db)
+
+	user := models.User{Username: "alice"}
+	db.Create(&user)
+	if len(user.PublicID) != 36 {
+		t.Fatalf("用户应该自动生成公开 ID，得到 %q", user.PublicID)
+	}
+
+	todo := &models.Todo{Title: "任务1", PublicID: "chosen-by-client"}
+	if err := s.Create(t.Context(), user.ID, todo); err != nil {
+		t.Fatal(err)
+	}
+	if todo.PublicID == "chosen-by-client" || len(todo.PublicID) != 36 {
+		t.Errorf("公开 ID 应该由服务端生成，得到 %q", todo.PublicID)
+	}
+	if todo.CreatorID != user.PublicID {
+		t.Errorf("创建者应该返回用户的公开 ID，得到 %q", todo.CreatorID)
+	}
+
+	// 自增主键不能再作为 URL 中的 ID
+	if _, err := s.GetByID(t.Context(), user.ID, strconv.FormatUint(uint64(todo.ID), 10)); err == nil {
+		t.Error("不应该能用自增主键查询任务")
+	}
+
+	// 更新时请求体里的 id 不能改掉公开 ID
+	publicID := todo.PublicID
+	todo.PublicID = "tampered"
+	if err := s.Update(t.Context(), user.ID, todo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetByID(t.Context(), user.ID, publicID); err != nil {
+		t.Errorf("更新后公开 ID 应该保持不变: %v", err)
+	}
+}
+
 // TestDelete 测试删除 todo
 func TestDelete(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
 
 	// 为用户 1 创建一个任务
 	todo := &models.Todo{Title: "待删除任务", Status: false, UserID: 1}
 	db.Create(todo)
 
 	// 删除任务
-	err := s.Delete(1, toString(todo.ID))
+	err := s.Delete(t.Context(), 1, todo.PublicID)
 	if err != nil {
 		t.Errorf("期望没有错误，但得到了: %v", err)
 	}
@@ -256,14 +407,15 @@ func TestDelete(t *testing.T) {
 func TestDelete_UserIDProtection(t *testing.T) {
 	db := setupTestDB()
 	config.DB = db
-	s := &TodoService{}
+	s := newTestTodoService(db)
+	seedUsers(db, 2)
 
 	// 为用户 1 创建一个任务
 	todo := &models.Todo{Title: "用户1的任务", Status: false, UserID: 1}
 	db.Create(todo)
 
 	// 用户 2 尝试删除用户 1 的任务
-	err := s.Delete(2, toString(todo.ID))
+	err := s.Delete(t.Context(), 2, todo.PublicID)
 	if err != nil {
 		t.Errorf("期望没有错误（因为 Where 条件不匹配，不会影响任何行），但得到了: %v", err)
 	}
@@ -276,7 +428,100 @@ func TestDelete_UserIDProtection(t *testing.T) {
 	}
 }
 
-// 辅助函数：uint 转 string
-func toString(id uint) string {
-    return strconv.FormatUint(uint64(id), 10)
-}
\ No newline at end of file
+// TestTodoEvents 测试任务变更事件推送给工作区的所有成员
+func TestTodoEvents(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	config.Events = events.NewHub(10)
+	s := newTestTodoService(db)
+	ws := &WorkspaceService{}
+
+	owner := createTestUser(t, "owner")
+	member := createTestUser(t, "member")
+	outsider := createTestUser(t, "outsider")
+	workspace, _ := ws.Create(t.Context(), owner.ID, "研发组")
+	invite, _ := ws.Invite(t.Context(), owner.ID, workspace.ID, "member", models.WorkspaceViewer, 0)
+	ws.AcceptInvite(t.Context(), member.ID, invite.Token)
+
+	sub, _ := config.Events.Subscribe(member.ID, 0)
+	defer sub.Close()
+	other, _ := config.Events.Subscribe(outsider.ID, 0)
+	defer other.Close()
+
+	todo := &models.Todo{Title: "共享任务", WorkspaceID: &workspace.ID}
+	s.Create(t.Context(), owner.ID, todo)
+	todo.Title = "改名"
+	s.Update(t.Context(), owner.ID, todo)
+	s.Delete(t.Context(), owner.ID, todo.PublicID)
+
+	for _, want := range []string{events.TodoCreated, events.TodoUpdated, events.TodoDeleted} {
+		if e := <-sub.C; e.Type != want {
+			t.Errorf("期望事件 %s，但得到了 %s", want, e.Type)
+		}
+	}
+	if len(other.C) != 0 {
+		t.Error("非成员不应该收到事件")
+	}
+}
+
+// TestTodoServiceMemoryRepository 使用内存存储测试权限和事件，不依赖 config.DB，可以并行运行
+func TestTodoServiceMemoryRepository(t *testing.T) {
+	t.Parallel()
+	users := repository.NewMemoryUserRepository()
+	repo := repository.NewMemoryTodoRepository(users)
+	var published []string
+	s := NewTodoService(repo, func(eventType string, data interface{}, audience []uint) {
+		published = append(published, eventType+":"+strconv.Itoa(len(audience)))
+	})
+
+	owner := models.User{Username: "owner"}
+	viewer := models.User{Username: "viewer"}
+	users.Create(t.Context(), &owner)
+	users.Create(t.Context(), &viewer)
+	ws := uint(1)
+	repo.SetMember(ws, owner.ID, models.WorkspaceOwner)
+	repo.SetMember(ws, viewer.ID, models.WorkspaceViewer)
+
+	personal := &models.Todo{Title: "个人任务"}
+	if err := s.Create(t.Context(), owner.ID, personal); err != nil {
+		t.Fatal(err)
+	}
+	if personal.CreatorID != owner.PublicID {
+		t.Errorf("期望创建者为 %s，但得到了 %s", owner.PublicID, personal.CreatorID)
+	}
+	if _, err := s.GetByID(t.Context(), viewer.ID, personal.PublicID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("其他用户不应该看到个人任务，但得到了 %v", err)
+	}
+	if err := s.Create(t.Context(), viewer.ID, &models.Todo{Title: "越权", WorkspaceID: &ws}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("viewer 不能在工作区创建任务，但得到了 %v", err)
+	}
+
+	shared := &models.Todo{Title: "共享任务", WorkspaceID: &ws}
+	if err := s.Create(t.Context(), owner.ID, shared); err != nil {
+		t.Fatal(err)
+	}
+	todo, err := s.Assign(t.Context(), owner.ID, shared.PublicID, viewer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todo.Assignees) != 1 || todo.Assignees[0].Username != "viewer" {
+		t.Errorf("期望负责人为 viewer，但得到了 %+v", todo.Assignees)
+	}
+	assigned, total, err := s.ListAssigned(t.Context(), viewer.ID, TodoQuery{})
+	if err != nil || total != 1 || assigned[0].PublicID != shared.PublicID {
+		t.Errorf("期望 viewer 负责 1 个任务，但得到了 %d 个 (%v)", total, err)
+	}
+
+	if err := s.Delete(t.Context(), owner.ID, shared.PublicID); err != nil {
+		t.Fatal(err)
+	}
+	changes := repo.Changes()
+	if last := changes[len(changes)-1]; !last.Deleted || last.PublicID != shared.PublicID {
+		t.Errorf("删除后应该留下墓碑，但得到了 %+v", last)
+	}
+
+	want := []string{"todo.created:1", "todo.created:2", "todo.updated:2", "todo.deleted:2"}
+	if strings.Join(published, ",") != strings.Join(want, ",") {
+		t.Errorf("期望事件 %v，但得到了 %v", want, published)
+	}
+}
@@ -1,27 +1,39 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go-todo/common"
-	"go-todo/config"
+	"go-todo/metrics"
 	"go-todo/models"
+	"go-todo/repository"
+	"go-todo/tracing"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-type UserService struct{}
+// UserService 注册、登录和个人资料，数据访问都通过 UserRepository
+type UserService struct {
+	users repository.UserRepository
+}
+
+// NewUserService 创建 UserService
+func NewUserService(users repository.UserRepository) *UserService {
+	return &UserService{users: users}
+}
 
-func (s *UserService) Register(username, password string) error {
+func (s *UserService) Register(ctx context.Context, username, password string) error {
+	ctx, span := tracing.Start(ctx, "UserService.Register")
+	defer span.End()
 	// 1. 检查用户名是否存在
-	var count int64
-	config.DB.Model(&models.User{}).Where("username = ?", username).Count(&count)
-	if count > 0 {
+	if _, err := s.users.FindByUsername(ctx, username); err == nil {
 		return errors.New("用户名已存在")
 	}
 
 	// 2. 密码加密 (Hash)
 	// Cost 设为 14 左右比较安全，但计算较慢；10 是默认值
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := hashPassword(ctx, password)
 	if err != nil {
 		return err
 	}
@@ -32,33 +44,173 @@ func (s *UserService) Register(username, password string) error {
 		Password: string(hashedPassword), // 存入的是加密后的乱码
 	}
 
-	return config.DB.Create(&user).Error
+	if err := s.users.Create(ctx, &user); err != nil {
+		return err
+	}
+	metrics.UsersRegistered.Inc()
+	return nil
 }
 
 
 
 // Login 登录逻辑
-func (s *UserService) Login(username, password string) (string, error) {
-	var user models.User
-	
+func (s *UserService) Login(ctx context.Context, username, password string) (string, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Login")
+	defer span.End()
 	// 1. 根据用户名找用户
-	if err := config.DB.Where("username = ?", username).First(&user).Error; err != nil {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		metrics.LoginFailures.WithLabelValues(metrics.LoginUserNotFound).Inc()
 		return "", errors.New("用户不存在")
 	}
 
 	// 2. 验证密码 (核心！)
 	//哪怕你拿到了数据库里的密码 user.Password (是乱码)，你也不能直接 == 对比
 	// 必须用 bcrypt.CompareHashAndPassword
-	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	err = comparePassword(ctx, user.Password, password)
 	if err != nil {
-		return "", errors.New("密码错误")
+		metrics.LoginFailures.WithLabelValues(metrics.LoginWrongPassword).Inc()
+		return "", ErrWrongPassword
+	}
+	if user.Disabled {
+		metrics.LoginFailures.WithLabelValues(metrics.LoginUserDisabled).Inc()
+		return "", ErrUserDisabled
 	}
 
 	// 3. 密码正确，生成 JWT Token
-	token, err := common.GenerateToken(user.ID)
+	token, err := common.GenerateToken(user.PublicID, user.TokenVersion)
 	if err != nil {
 		return "", err
 	}
 
 	return token, nil
-}
\ No newline at end of file
+}
+
+// GetByID 获取用户信息（含个人资料）
+func (s *UserService) GetByID(ctx context.Context, userID uint) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetByID")
+	defer span.End()
+	return s.users.FindByID(ctx, userID)
+}
+
+// CheckToken 校验 Token 对应的用户仍然存在、未被禁用，且 Token 没有被作废
+func (s *UserService) CheckToken(ctx context.Context, claims *common.MyCustomClaims) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CheckToken")
+	defer span.End()
+	user, err := s.users.FindByPublicID(ctx, claims.UserID)
+	if err != nil {
+		return user, errors.New("用户不存在")
+	}
+	if user.TokenVersion != claims.TokenVersion {
+		return user, errors.New("Token 已失效，请重新登录")
+	}
+	if claims.ID != "" {
+		revoked, err := s.users.TokenRevoked(ctx, claims.ID)
+		if err != nil {
+			return user, err
+		}
+		if revoked {
+			return user, errors.New("Token 已失效，请重新登录")
+		}
+	}
+	if user.Disabled {
+		return user, ErrUserDisabled
+	}
+	return user, nil
+}
+
+// ChangePassword 校验旧密码后设置新密码，并作废之前签发的所有 Token
+// 返回新 Token，当前客户端可以继续使用
+func (s *UserService) ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) (string, error) {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return "", errors.New("用户不存在")
+	}
+	if err := comparePassword(ctx, user.Password, oldPassword); err != nil {
+		return "", ErrWrongPassword
+	}
+
+	hashedPassword, err := hashPassword(ctx, newPassword)
+	if err != nil {
+		return "", err
+	}
+	version := user.TokenVersion + 1
+	if err := s.users.UpdatePassword(ctx, user.ID, string(hashedPassword), version); err != nil {
+		return "", err
+	}
+	return common.GenerateToken(user.PublicID, version)
+}
+
+// Logout 退出登录：只作废当前请求使用的 Token，其他设备和另一种登录方式的会话不受影响。
+// 没有 jti 的旧 Token 无法单独作废，退回到作废所有 Token
+func (s *UserService) Logout(ctx context.Context, userID uint, claims *common.MyCustomClaims) error {
+	ctx, span := tracing.Start(ctx, "UserService.Logout")
+	defer span.End()
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return s.users.RevokeTokens(ctx, userID)
+	}
+	return s.users.RevokeToken(ctx, userID, claims.ID, claims.ExpiresAt.Time)
+}
+
+// LogoutAll 退出所有设备：作废这个用户之前签发的所有 Token，包括被窃取的 Cookie 中的 Token
+func (s *UserService) LogoutAll(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Start(ctx, "UserService.LogoutAll")
+	defer span.End()
+	return s.users.RevokeTokens(ctx, userID)
+}
+
+// GetByPublicID 根据公开 ID 查找用户，URL 和请求体中的用户 ID 都是公开 ID
+func (s *UserService) GetByPublicID(ctx context.Context, publicID string) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetByPublicID")
+	defer span.End()
+	return s.users.FindByPublicID(ctx, publicID)
+}
+
+// GetByUsername 根据用户名查找用户
+func (s *UserService) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetByUsername")
+	defer span.End()
+	return s.users.FindByUsername(ctx, username)
+}
+
+// UpdateProfile 校验并保存用户的个人资料与偏好
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, profile models.Profile) (models.Profile, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateProfile")
+	defer span.End()
+	if profile.TimeZone == "" {
+		profile.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(profile.TimeZone); err != nil {
+		return profile, errors.New("无法识别的时区: " + profile.TimeZone)
+	}
+	if profile.Locale == "" {
+		profile.Locale = "zh-CN"
+	}
+	if profile.WeekStart < 0 || profile.WeekStart > 6 {
+		return profile, errors.New("week_start 必须在 0 (周日) 到 6 (周六) 之间")
+	}
+	if profile.DefaultSort == "" {
+		profile.DefaultSort = DefaultTodoSort
+	}
+	if _, ok := TodoSorts[profile.DefaultSort]; !ok {
+		return profile, errors.New("不支持的排序方式: " + profile.DefaultSort)
+	}
+
+	return profile, s.users.UpdateProfile(ctx, userID, profile)
+}
+
+// hashPassword 计算密码哈希。bcrypt 故意很慢，是注册和登录耗时的主要部分，单独记一个 span
+func hashPassword(ctx context.Context, password string) ([]byte, error) {
+	_, span := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// comparePassword 校验密码，同样单独记一个 span
+func comparePassword(ctx context.Context, hash, password string) error {
+	_, span := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"go-todo/common"
+	"go-todo/config"
+	"go-todo/models"
+	"go-todo/repository"
+)
+
+// TestUpdateProfile 测试保存个人资料，包括 0 值字段
+func TestUpdateProfile(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := newTestUserService(db)
+
+	if err := s.Register(t.Context(), "alice", "password123"); err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+	var user models.User
+	db.Where("username = ?", "alice").First(&user)
+	if user.Profile.WeekStart != 1 || user.Profile.TimeZone != "UTC" {
+		t.Errorf("期望新用户使用默认偏好，但得到了 %+v", user.Profile)
+	}
+
+	_, err := s.UpdateProfile(t.Context(), user.ID, models.Profile{
+		DisplayName:    "Alice",
+		TimeZone:       "Asia/Shanghai",
+		DefaultProject: "工作",
+		WeekStart:      0,
+		DefaultSort:    "due_asc",
+	})
+	if err != nil {
+		t.Fatalf("期望没有错误，但得到了: %v", err)
+	}
+
+	updated, _ := s.GetByID(t.Context(), user.ID)
+	if updated.Profile.DisplayName != "Alice" || updated.Profile.TimeZone != "Asia/Shanghai" {
+		t.Errorf("个人资料没有被保存: %+v", updated.Profile)
+	}
+	if updated.Profile.WeekStart != 0 {
+		t.Errorf("期望 week_start 为 0（周日），但得到了 %d", updated.Profile.WeekStart)
+	}
+}
+
+// TestUpdateProfile_Validation 测试非法偏好被拒绝
+func TestUpdateProfile_Validation(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	s := newTestUserService(db)
+
+	cases := []models.Profile{
+		{TimeZone: "Mars/Olympus"},
+		{WeekStart: 7},
+		{DefaultSort: "random"},
+	}
+	for _, p := range cases {
+		if _, err := s.UpdateProfile(t.Context(), 1, p); err == nil {
+			t.Errorf("期望 %+v 校验失败，但没有返回错误", p)
+		}
+	}
+}
+
+// TestUserServiceMemoryRepository 使用内存存储测试登录、禁用和改密作废旧 Token
+func TestUserServiceMemoryRepository(t *testing.T) {
+	t.Parallel()
+	users := repository.NewMemoryUserRepository()
+	s := NewUserService(users)
+
+	if err := s.Register(t.Context(), "alice", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register(t.Context(), "alice", "password123"); err == nil {
+		t.Error("重复注册应该失败")
+	}
+	if _, err := s.Login(t.Context(), "alice", "wrong"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("期望 ErrWrongPassword，但得到了 %v", err)
+	}
+	token, err := s.Login(t.Context(), "alice", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, _ := common.ParseToken(token)
+	user, err := s.CheckToken(t.Context(), claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ChangePassword(t.Context(), user.ID, "password123", "newpassword"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CheckToken(t.Context(), claims); err == nil {
+		t.Error("改密后旧 Token 应该失效")
+	}
+
+	user, _ = users.FindByID(t.Context(), user.ID)
+	user.Disabled = true
+	users.Save(user)
+	if _, err := s.Login(t.Context(), "alice", "newpassword"); !errors.Is(err, ErrUserDisabled) {
+		t.Errorf("期望 ErrUserDisabled，但得到了 %v", err)
+	}
+}
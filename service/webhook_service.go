@@ -0,0 +1,385 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-todo/config"
+	"go-todo/events"
+	"go-todo/metrics"
+	"go-todo/models"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookService 管理 Webhook 订阅和投递记录
+type WebhookService struct{}
+
+// WebhookEvents 可以订阅的事件类型
+var WebhookEvents = []string{events.TodoCreated, events.TodoUpdated, events.TodoDeleted}
+
+// WebhookTestEvent "发送测试事件"使用的事件类型
+const WebhookTestEvent = "webhook.test"
+
+// 投递请求头
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookBackoff 第一次重试的等待时间，之后每次翻倍
+var webhookBackoff = 30 * time.Second
+
+// WebhookRequest 创建/修改 Webhook 的参数
+type WebhookRequest struct {
+	URL    string
+	Events []string
+	Active *bool
+}
+
+func (r WebhookRequest) validate() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: URL 必须是 http 或 https 地址", ErrWebhookInvalid)
+	}
+	for _, e := range r.Events {
+		if !validWebhookEvent(e) {
+			return fmt.Errorf("%w: 不支持的事件类型 %s", ErrWebhookInvalid, e)
+		}
+	}
+	return nil
+}
+
+func validWebhookEvent(eventType string) bool {
+	for _, e := range WebhookEvents {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Create 创建 Webhook，密钥只在这里返回一次
+func (s *WebhookService) Create(ctx context.Context, userID uint, req WebhookRequest) (models.Webhook, error) {
+	var hook models.Webhook
+	if err := req.validate(); err != nil {
+		return hook, err
+	}
+	secret, err := randomToken(24)
+	if err != nil {
+		return hook, err
+	}
+	hook = models.Webhook{UserID: userID, URL: req.URL, Events: req.Events, Secret: "whsec_" + secret, Active: true}
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	err = config.DB.WithContext(ctx).Create(&hook).Error
+	return hook, err
+}
+
+// List 列出用户的 Webhook
+func (s *WebhookService) List(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	err := config.DB.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Omit("secret").Find(&hooks).Error
+	return hooks, err
+}
+
+// Get 获取用户的一个 Webhook（不含密钥）
+func (s *WebhookService) Get(ctx context.Context, userID, id uint) (models.Webhook, error) {
+	var hook models.Webhook
+	err := config.DB.WithContext(ctx).Where("user_id = ?", userID).Omit("secret").First(&hook, id).Error
+	return hook, err
+}
+
+// Update 修改 Webhook 的地址、事件类型和启用状态
+func (s *WebhookService) Update(ctx context.Context, userID, id uint, req WebhookRequest) (models.Webhook, error) {
+	hook, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return hook, err
+	}
+	if err := req.validate(); err != nil {
+		return hook, err
+	}
+	hook.URL = req.URL
+	hook.Events = req.Events
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	err = config.DB.WithContext(ctx).Model(&hook).Select("url", "events", "active").Updates(&hook).Error
+	return hook, err
+}
+
+// Delete 删除 Webhook 及其投递记录
+func (s *WebhookService) Delete(ctx context.Context, userID, id uint) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteWebhooks(tx, "id = ?", id)
+	})
+}
+
+// deleteWebhooks 在事务中删除符合条件的 Webhook 和它们的投递记录
+func deleteWebhooks(tx *gorm.DB, query string, args ...interface{}) error {
+	ids := tx.Model(&models.Webhook{}).Select("id").Where(query, args...)
+	if err := tx.Where("webhook_id IN (?)", ids).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return tx.Where(query, args...).Delete(&models.Webhook{}).Error
+}
+
+// Deliveries 最近的投递记录，最多 limit 条
+func (s *WebhookService) Deliveries(ctx context.Context, userID, id uint, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	var deliveries []models.WebhookDelivery
+	err := config.DB.WithContext(ctx).Where("webhook_id = ?", id).Order("id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Test 立即向 Webhook 发送一条测试事件并返回投递结果；失败时会和普通投递一样重试
+func (s *WebhookService) Test(ctx context.Context, userID, id uint) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	hook, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return delivery, err
+	}
+	e := events.Event{ID: uint64(time.Now().UnixMicro()), Type: WebhookTestEvent, CreatedAt: time.Now()}
+	e.Data, _ = json.Marshal(map[string]interface{}{"webhook_id": hook.ID})
+	delivery, err = newDelivery(hook.ID, e)
+	if err != nil {
+		return delivery, err
+	}
+	if err := config.DB.WithContext(ctx).Create(&delivery).Error; err != nil {
+		return delivery, err
+	}
+	// 在请求中同步发送，最多等待一次投递的超时时间，请求先超时或被取消时也随之放弃；
+	// 没有成功的测试事件和普通投递一样之后由 WebhookWorker 重试
+	w := NewWebhookWorker()
+	if w.claim(ctx, &delivery) {
+		sendCtx, cancel := context.WithTimeout(ctx, w.Client.Timeout)
+		defer cancel()
+		w.attempt(sendCtx, &delivery)
+	}
+	return delivery, nil
+}
+
+// enqueueWebhooks 为订阅了该事件的 Webhook 创建投递任务，由 WebhookWorker 异步发送
+func enqueueWebhooks(e events.Event, userIDs []uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	var hooks []models.Webhook
+	if err := config.DB.Where("user_id IN ? AND active = ?", userIDs, true).Find(&hooks).Error; err != nil {
+		slog.Error("查询 Webhook 失败", "error", err)
+		return
+	}
+	for _, hook := range hooks {
+		if !hook.Subscribed(e.Type) {
+			continue
+		}
+		delivery, err := newDelivery(hook.ID, e)
+		if err == nil {
+			err = config.DB.Create(&delivery).Error
+		}
+		if err != nil {
+			slog.Error("创建 Webhook 投递失败", "webhook_id", hook.ID, "error", err)
+		}
+	}
+}
+
+func newDelivery(webhookID uint, e events.Event) (models.WebhookDelivery, error) {
+	payload, err := json.Marshal(e)
+	return models.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       e.ID,
+		EventType:     e.Type,
+		Payload:       string(payload),
+		Status:        models.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}, err
+}
+
+// SignWebhook 计算投递签名：HMAC-SHA256(secret, "<timestamp>.<body>")，
+// 接收方用相同的方式计算并比较 X-Webhook-Signature 请求头
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookWorker 从投递队列中取出到期的投递并发送，失败后按指数退避重试
+type WebhookWorker struct {
+	Client      *http.Client
+	Interval    time.Duration
+	MaxAttempts int
+}
+
+// NewWebhookWorker 按配置创建投递工作者
+func NewWebhookWorker() *WebhookWorker {
+	return &WebhookWorker{
+		Client:      webhookClient(config.WebhookTimeout(), config.WebhookAllowPrivate()),
+		Interval:    config.WebhookPollInterval(),
+		MaxAttempts: config.WebhookMaxAttempts(),
+	}
+}
+
+// Run 定期处理投递队列，直到 ctx 被取消
+func (w *WebhookWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.ProcessDue(ctx)
+		}
+	}
+}
+
+// ProcessDue 发送所有到期的投递，返回处理的数量
+func (w *WebhookWorker) ProcessDue(ctx context.Context) int {
+	var due []models.WebhookDelivery
+	err := config.DB.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+		Order("next_attempt_at ASC").Limit(20).Find(&due).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "读取 Webhook 投递队列失败", "error", err)
+		return 0
+	}
+	n := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if w.claim(ctx, &due[i]) {
+			// 已经占用的投递发送完再退出，不把退出当成一次失败的尝试
+			w.attempt(context.WithoutCancel(ctx), &due[i])
+			n++
+		}
+	}
+	return n
+}
+
+// claim 占用一次投递：尝试次数作为版本号，多个实例同时处理时只有一个能成功；
+// 同时把下次尝试时间推后，实例在投递中途退出时其他实例稍后会接手
+func (w *WebhookWorker) claim(ctx context.Context, d *models.WebhookDelivery) bool {
+	lease := time.Now().Add(w.Client.Timeout + time.Minute)
+	result := config.DB.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", d.ID, models.DeliveryPending, d.Attempts).
+		Updates(map[string]interface{}{"attempts": d.Attempts + 1, "next_attempt_at": lease})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+	d.Attempts++
+	return true
+}
+
+// attempt 发送一次投递并记录结果；ctx 只限制发送，发送被取消时同样记录结果，等待之后重试
+func (w *WebhookWorker) attempt(ctx context.Context, d *models.WebhookDelivery) {
+	db := config.DB.WithContext(context.WithoutCancel(ctx))
+	var hook models.Webhook
+	err := db.First(&hook, d.WebhookID).Error
+	if err == nil && !hook.Active {
+		err = errors.New("Webhook 已停用")
+	}
+	status := 0
+	if err == nil {
+		status, err = w.send(ctx, hook, d)
+	}
+
+	now := time.Now()
+	d.ResponseStatus = status
+	switch {
+	case err == nil:
+		d.Status = models.DeliverySucceeded
+		d.LastError = ""
+		d.DeliveredAt = &now
+		metrics.WebhookDeliveries.WithLabelValues(metrics.DeliverySucceeded).Inc()
+	case d.Attempts >= w.MaxAttempts || !hook.Active:
+		d.Status = models.DeliveryFailed
+		d.LastError = err.Error()
+		metrics.WebhookDeliveries.WithLabelValues(metrics.DeliveryFailed).Inc()
+	default:
+		d.LastError = err.Error()
+		metrics.WebhookDeliveries.WithLabelValues(metrics.DeliveryRetrying).Inc()
+		d.NextAttemptAt = now.Add(webhookBackoff << (d.Attempts - 1))
+	}
+	if len(d.LastError) > 1024 {
+		d.LastError = d.LastError[:1024]
+	}
+	if err := db.Model(d).Select("status", "response_status", "last_error", "delivered_at", "next_attempt_at").Updates(d).Error; err != nil {
+		slog.ErrorContext(ctx, "保存 Webhook 投递结果失败", "delivery_id", d.ID, "error", err)
+	}
+}
+
+// send 发送请求，2xx 视为成功
+func (w *WebhookWorker) send(ctx context.Context, hook models.Webhook, d *models.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-todo-webhook/1.0")
+	req.Header.Set(WebhookEventHeader, d.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(hook.Secret, timestamp, body))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, snippet)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookClient 投递用的 HTTP 客户端：不跟随重定向，默认拒绝连接内网地址。
+// 地址检查放在建立连接时，DNS 解析到内网的域名同样会被拒绝
+func webhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+				return fmt.Errorf("不允许投递到内网地址 %s", host)
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
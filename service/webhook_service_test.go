@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go-todo/config"
+	"go-todo/events"
+	"go-todo/models"
+
+	"github.com/spf13/viper"
+)
+
+// receiver 本地的 Webhook 接收端，校验签名并记录收到的事件类型
+type receiver struct {
+	mu     sync.Mutex
+	secret string
+	fail   int // 前 fail 次请求返回 500
+	events []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := io.ReadAll(req.Body)
+	ts, _ := strconv.ParseInt(req.Header.Get(WebhookTimestampHeader), 10, 64)
+	if req.Header.Get(WebhookSignatureHeader) != SignWebhook(r.secret, ts, body) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+		return
+	}
+	if r.fail > 0 {
+		r.fail--
+		http.Error(w, "boom", http.StatusInternalServerError)
+		return
+	}
+	r.events = append(r.events, req.Header.Get(WebhookEventHeader))
+}
+
+// TestWebhooks 测试签名投递、事件过滤、失败重试和测试事件
+func TestWebhooks(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	config.Events = events.NewHub(10)
+	viper.Set("webhooks.allow_private", true)
+	defer viper.Set("webhooks.allow_private", false)
+	defer func(backoff time.Duration) { webhookBackoff = backoff }(webhookBackoff)
+	webhookBackoff = 0
+
+	s := &WebhookService{}
+	ts := newTestTodoService(db)
+	user := createTestUser(t, "owner")
+	rcv := &receiver{fail: 1}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	if _, err := s.Create(t.Context(), user.ID, WebhookRequest{URL: "ftp://example.com"}); err == nil {
+		t.Error("期望拒绝非 http 地址")
+	}
+	if _, err := s.Create(t.Context(), user.ID, WebhookRequest{URL: srv.URL, Events: []string{"todo.exploded"}}); err == nil {
+		t.Error("期望拒绝未知的事件类型")
+	}
+	hook, err := s.Create(t.Context(), user.ID, WebhookRequest{URL: srv.URL, Events: []string{events.TodoCreated, events.TodoDeleted}})
+	if err != nil || hook.Secret == "" {
+		t.Fatalf("创建 Webhook 失败: %v", err)
+	}
+	rcv.secret = hook.Secret
+	if listed, _ := s.List(t.Context(), user.ID); len(listed) != 1 || listed[0].Secret != "" {
+		t.Errorf("列表不应该返回密钥: %+v", listed)
+	}
+
+	todo := &models.Todo{Title: "部署"}
+	ts.Create(t.Context(), user.ID, todo)
+	todo.Title = "部署上线"
+	ts.Update(t.Context(), user.ID, todo) // 没有订阅 todo.updated
+
+	w := NewWebhookWorker()
+	// 第一次投递失败，立即重试后成功
+	if n := w.ProcessDue(context.Background()); n != 1 {
+		t.Fatalf("期望处理 1 个投递，但处理了 %d 个", n)
+	}
+	w.ProcessDue(context.Background())
+
+	deliveries, _ := s.Deliveries(t.Context(), user.ID, hook.ID, 10)
+	if len(deliveries) != 1 {
+		t.Fatalf("期望 1 条投递记录，但得到了 %d 条", len(deliveries))
+	}
+	d := deliveries[0]
+	if d.Status != models.DeliverySucceeded || d.Attempts != 2 || d.ResponseStatus != 200 {
+		t.Errorf("投递结果不正确: %+v", d)
+	}
+	if len(rcv.events) != 1 || rcv.events[0] != events.TodoCreated {
+		t.Errorf("接收端收到的事件不正确: %v", rcv.events)
+	}
+
+	// 测试事件同步投递
+	test, err := s.Test(t.Context(), user.ID, hook.ID)
+	if err != nil || test.Status != models.DeliverySucceeded {
+		t.Errorf("测试事件投递失败: %+v, %v", test, err)
+	}
+
+	// 超过最大次数后标记为失败
+	rcv.fail = 100
+	w.MaxAttempts = 2
+	ts.Delete(t.Context(), user.ID, todo.PublicID)
+	w.ProcessDue(context.Background())
+	w.ProcessDue(context.Background())
+	deliveries, _ = s.Deliveries(t.Context(), user.ID, hook.ID, 1)
+	if deliveries[0].Status != models.DeliveryFailed || deliveries[0].LastError == "" {
+		t.Errorf("期望投递最终失败，但得到了 %+v", deliveries[0])
+	}
+}
+
+// TestWebhookTestTimeout 接收端很慢时测试事件随请求一起超时，投递记录为失败并等待重试
+func TestWebhookTestTimeout(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	viper.Set("webhooks.allow_private", true)
+	defer viper.Set("webhooks.allow_private", false)
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	s := &WebhookService{}
+	user := createTestUser(t, "owner")
+	hook, _ := s.Create(t.Context(), user.ID, WebhookRequest{URL: srv.URL})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	delivery, err := s.Test(ctx, user.ID, hook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("期望测试事件随请求超时，但等待了 %v", elapsed)
+	}
+	deliveries, _ := s.Deliveries(t.Context(), user.ID, hook.ID, 1)
+	if delivery.Status != models.DeliveryPending || len(deliveries) != 1 || deliveries[0].LastError == "" {
+		t.Errorf("期望记录失败并等待重试，但得到了 %+v", deliveries)
+	}
+}
+
+// TestWebhookPrivateAddress 测试默认拒绝投递到内网地址
+func TestWebhookPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	client := webhookClient(time.Second, false)
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Error("期望拒绝连接本机地址")
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// Workers 管理后台任务（如 Webhook 投递），并记录每个任务是否还在运行，供 /readyz 检查
+type Workers struct {
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	tasks   map[string]func(context.Context)
+	running map[string]bool
+}
+
+// NewWorkers 创建空的后台任务列表
+func NewWorkers() *Workers {
+	return &Workers{tasks: make(map[string]func(context.Context)), running: make(map[string]bool)}
+}
+
+// Add 登记一个后台任务，run 应该一直运行到 ctx 被取消
+func (w *Workers) Add(name string, run func(context.Context)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tasks[name] = run
+}
+
+// Start 启动所有登记的任务，ctx 被取消后任务退出
+func (w *Workers) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for name, run := range w.tasks {
+		w.running[name] = true
+		w.wg.Add(1)
+		go w.run(ctx, name, run)
+	}
+}
+
+// run 运行一个任务；任务 panic 时记录下来，不影响其他任务和 HTTP 服务
+func (w *Workers) run(ctx context.Context, name string, run func(context.Context)) {
+	defer w.wg.Done()
+	defer func() {
+		if p := recover(); p != nil {
+			slog.Error("后台任务异常退出", "worker", name, "panic", p)
+		}
+		w.mu.Lock()
+		w.running[name] = false
+		w.mu.Unlock()
+	}()
+	run(ctx)
+}
+
+// Wait 等待所有任务退出
+func (w *Workers) Wait() {
+	w.wg.Wait()
+}
+
+// WorkerStatus 一个后台任务的状态
+type WorkerStatus struct {
+	Name    string `json:"name" example:"webhooks"`
+	Running bool   `json:"running" example:"true"`
+}
+
+// Status 按名称顺序返回所有登记的任务是否在运行
+func (w *Workers) Status() []WorkerStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := make([]WorkerStatus, 0, len(w.tasks))
+	for name := range w.tasks {
+		status = append(status, WorkerStatus{Name: name, Running: w.running[name]})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Name < status[j].Name })
+	return status
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"go-todo/config"
+	"go-todo/models"
+	"go-todo/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkspaceService 工作区、成员与邀请
+type WorkspaceService struct{}
+
+// workspaceRole 返回用户在工作区中的角色，不是成员时返回 ErrForbidden
+func workspaceRole(ctx context.Context, userID, workspaceID uint) (string, error) {
+	var member models.WorkspaceMember
+	err := config.DB.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrForbidden
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// canEdit 该角色是否可以创建、修改和删除任务
+func canEdit(role string) bool {
+	return role == models.WorkspaceOwner || role == models.WorkspaceEditor
+}
+
+// requireOwner 只有工作区拥有者可以管理工作区
+func requireOwner(ctx context.Context, userID, workspaceID uint) error {
+	role, err := workspaceRole(ctx, userID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if role != models.WorkspaceOwner {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Create 创建工作区，创建者成为拥有者
+func (s *WorkspaceService) Create(ctx context.Context, userID uint, name string) (models.Workspace, error) {
+	ws := models.Workspace{Name: name, OwnerID: userID}
+	err := config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ws).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.WorkspaceMember{WorkspaceID: ws.ID, UserID: userID, Role: models.WorkspaceOwner}).Error
+	})
+	ws.Role = models.WorkspaceOwner
+	return ws, err
+}
+
+// List 列出用户加入的所有工作区，附带用户在其中的角色
+func (s *WorkspaceService) List(ctx context.Context, userID uint) ([]models.Workspace, error) {
+	var list []models.Workspace
+	err := config.DB.WithContext(ctx).Model(&models.Workspace{}).
+		Select("workspaces.*, workspace_members.role").
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// Get 获取工作区详情，成员才能查看
+func (s *WorkspaceService) Get(ctx context.Context, userID, workspaceID uint) (models.Workspace, error) {
+	var ws models.Workspace
+	role, err := workspaceRole(ctx, userID, workspaceID)
+	if err != nil {
+		return ws, err
+	}
+	if err := config.DB.WithContext(ctx).First(&ws, workspaceID).Error; err != nil {
+		return ws, err
+	}
+	ws.Role = role
+	return ws, nil
+}
+
+// Rename 修改工作区名称
+func (s *WorkspaceService) Rename(ctx context.Context, userID, workspaceID uint, name string) error {
+	if err := requireOwner(ctx, userID, workspaceID); err != nil {
+		return err
+	}
+	return config.DB.WithContext(ctx).Model(&models.Workspace{}).Where("id = ?", workspaceID).Update("name", name).Error
+}
+
+// Delete 删除工作区及其中的任务、成员和邀请
+func (s *WorkspaceService) Delete(ctx context.Context, userID, workspaceID uint) error {
+	if err := requireOwner(ctx, userID, workspaceID); err != nil {
+		return err
+	}
+	var blobs []string
+	err := config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		blobs, err = deleteWorkspace(tx, workspaceID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	removeBlobs(ctx, blobs)
+	return nil
+}
+
+// deleteWorkspace 在事务中删除工作区及其关联数据，返回需要删除的附件文件
+func deleteWorkspace(tx *gorm.DB, workspaceID uint) ([]string, error) {
+	// 成员记录删除后普通的墓碑对他们不可见，先给每个成员单独写入墓碑
+	var memberIDs []uint
+	if err := tx.Model(&models.WorkspaceMember{}).Where("workspace_id = ?", workspaceID).Pluck("user_id", &memberIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, memberID := range memberIDs {
+		if err := repository.RecordMemberChanges(tx, workspaceID, memberID, true); err != nil {
+			return nil, err
+		}
+	}
+	var todoIDs []uint
+	if err := tx.Model(&models.Todo{}).Where("workspace_id = ?", workspaceID).Pluck("id", &todoIDs).Error; err != nil {
+		return nil, err
+	}
+	blobs, err := repository.DeleteTodos(tx, todoIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceInvite{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.ShareLink{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		return nil, err
+	}
+	return blobs, tx.Delete(&models.Workspace{}, workspaceID).Error
+}
+
+// Members 列出工作区成员
+func (s *WorkspaceService) Members(ctx context.Context, userID, workspaceID uint) ([]models.WorkspaceMember, error) {
+	if _, err := workspaceRole(ctx, userID, workspaceID); err != nil {
+		return nil, err
+	}
+	var members []models.WorkspaceMember
+	err := config.DB.WithContext(ctx).Model(&models.WorkspaceMember{}).
+		Select("workspace_members.*, users.username, users.public_id AS user_public_id").
+		Joins("JOIN users ON users.id = workspace_members.user_id").
+		Where("workspace_members.workspace_id = ?", workspaceID).
+		Order("workspace_members.id ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// SetMemberRole 修改成员角色；把角色设为 owner 表示转让工作区，原拥有者变为 editor
+func (s *WorkspaceService) SetMemberRole(ctx context.Context, userID, workspaceID, memberID uint, role string) error {
+	if role != models.WorkspaceOwner && role != models.WorkspaceEditor && role != models.WorkspaceViewer {
+		return errors.New("不支持的角色: " + role)
+	}
+	if err := requireOwner(ctx, userID, workspaceID); err != nil {
+		return err
+	}
+	if memberID == userID {
+		return errors.New("不能修改自己的角色，请把拥有者转让给其他成员")
+	}
+	if _, err := workspaceRole(ctx, memberID, workspaceID); err != nil {
+		return ErrMemberNotFound
+	}
+
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if role == models.WorkspaceOwner {
+			if err := tx.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+				Update("role", models.WorkspaceEditor).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Workspace{}).Where("id = ?", workspaceID).Update("owner_id", memberID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, memberID).
+			Update("role", role).Error
+	})
+}
+
+// RemoveMember 移除成员；成员也可以移除自己（退出工作区），拥有者不能退出
+func (s *WorkspaceService) RemoveMember(ctx context.Context, userID, workspaceID, memberID uint) error {
+	role, err := workspaceRole(ctx, userID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if memberID == userID {
+		if role == models.WorkspaceOwner {
+			return errors.New("拥有者不能退出工作区，请先转让或删除工作区")
+		}
+	} else if role != models.WorkspaceOwner {
+		return ErrForbidden
+	}
+
+	if _, err := workspaceRole(ctx, memberID, workspaceID); err != nil {
+		return ErrMemberNotFound
+	}
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_id = ? AND user_id = ?", workspaceID, memberID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		// 离开后看不到工作区的变更，单独发送墓碑让客户端删除这些任务
+		if err := repository.RecordMemberChanges(tx, workspaceID, memberID, true); err != nil {
+			return err
+		}
+		// 离开工作区后，该成员在工作区任务上的分配也一并取消
+		workspaceTodos := tx.Model(&models.Todo{}).Select("id").Where("workspace_id = ?", workspaceID)
+		return tx.Where("user_id = ? AND todo_id IN (?)", memberID, workspaceTodos).Delete(&models.TodoAssignee{}).Error
+	})
+}
+
+// Invite 创建邀请：指定 username 时只邀请该用户，否则生成一个邀请链接
+// ttl 为 0 表示不过期
+func (s *WorkspaceService) Invite(ctx context.Context, userID, workspaceID uint, username, role string, ttl time.Duration) (models.WorkspaceInvite, error) {
+	invite := models.WorkspaceInvite{WorkspaceID: workspaceID, Role: role, InvitedBy: userID}
+	if role != models.WorkspaceEditor && role != models.WorkspaceViewer {
+		return invite, errors.New("邀请的角色只能是 editor 或 viewer")
+	}
+	if err := requireOwner(ctx, userID, workspaceID); err != nil {
+		return invite, err
+	}
+
+	if username != "" {
+		var invitee models.User
+		if err := config.DB.WithContext(ctx).Where("username = ?", username).First(&invitee).Error; err != nil {
+			return invite, ErrUserNotFound
+		}
+		if _, err := workspaceRole(ctx, invitee.ID, workspaceID); err == nil {
+			return invite, errors.New("该用户已经是工作区成员")
+		}
+		invite.InviteeID = &invitee.ID
+		invite.Invitee = invitee.Username
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	token, err := randomToken(24)
+	if err != nil {
+		return invite, err
+	}
+	invite.Token = token
+	return invite, config.DB.WithContext(ctx).Create(&invite).Error
+}
+
+// Invites 列出工作区的所有邀请
+func (s *WorkspaceService) Invites(ctx context.Context, userID, workspaceID uint) ([]models.WorkspaceInvite, error) {
+	if err := requireOwner(ctx, userID, workspaceID); err != nil {
+		return nil, err
+	}
+	var invites []models.WorkspaceInvite
+	err := config.DB.WithContext(ctx).Select("workspace_invites.*, users.username AS invitee").
+		Joins("LEFT JOIN users ON users.id = workspace_invites.invitee_id").
+		Where("workspace_invites.workspace_id = ?", workspaceID).Order("workspace_invites.id ASC").Find(&invites).Error
+	return invites, err
+}
+
+// RevokeInvite 撤销邀请
+func (s *WorkspaceService) RevokeInvite(ctx context.Context, userID, workspaceID, inviteID uint) error {
+	if err := requireOwner(ctx, userID, workspaceID); err != nil {
+		return err
+	}
+	return config.DB.WithContext(ctx).Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceInvite{}, inviteID).Error
+}
+
+// PendingInvites 列出发给当前用户的邀请
+func (s *WorkspaceService) PendingInvites(ctx context.Context, userID uint) ([]models.WorkspaceInvite, error) {
+	var invites []models.WorkspaceInvite
+	err := config.DB.WithContext(ctx).Where("invitee_id = ?", userID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("id ASC").Find(&invites).Error
+	return invites, err
+}
+
+// AcceptInvite 接受邀请并加入工作区
+func (s *WorkspaceService) AcceptInvite(ctx context.Context, userID uint, token string) (models.Workspace, error) {
+	var ws models.Workspace
+	invite, err := findInvite(ctx, userID, token)
+	if err != nil {
+		return ws, err
+	}
+	if _, err := workspaceRole(ctx, userID, invite.WorkspaceID); err == nil {
+		return ws, errors.New("你已经是该工作区的成员")
+	}
+
+	err = config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		member := models.WorkspaceMember{WorkspaceID: invite.WorkspaceID, UserID: userID, Role: invite.Role}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		// 工作区里已有的任务不会再产生变更，单独补发给新成员
+		if err := repository.RecordMemberChanges(tx, invite.WorkspaceID, userID, false); err != nil {
+			return err
+		}
+		// 指定用户的邀请只能使用一次
+		if invite.InviteeID != nil {
+			return tx.Delete(&invite).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return ws, err
+	}
+	return s.Get(ctx, userID, invite.WorkspaceID)
+}
+
+// DeclineInvite 拒绝发给自己的邀请
+func (s *WorkspaceService) DeclineInvite(ctx context.Context, userID uint, token string) error {
+	invite, err := findInvite(ctx, userID, token)
+	if err != nil {
+		return err
+	}
+	if invite.InviteeID == nil {
+		return errors.New("链接邀请无需拒绝")
+	}
+	return config.DB.WithContext(ctx).Delete(&invite).Error
+}
+
+// findInvite 查找当前用户可以使用的有效邀请
+func findInvite(ctx context.Context, userID uint, token string) (models.WorkspaceInvite, error) {
+	var invite models.WorkspaceInvite
+	if err := config.DB.WithContext(ctx).Where("token = ?", token).First(&invite).Error; err != nil {
+		return invite, ErrInviteInvalid
+	}
+	if invite.ExpiresAt != nil && invite.ExpiresAt.Before(time.Now()) {
+		return invite, ErrInviteInvalid
+	}
+	if invite.InviteeID != nil && *invite.InviteeID != userID {
+		return invite, ErrInviteInvalid
+	}
+	return invite, nil
+}
+
+// randomToken 生成 n 字节的随机十六进制字符串
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
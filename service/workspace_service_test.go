@@ -0,0 +1,201 @@
+package service
+
+import (
+	"testing"
+
+	"go-todo/config"
+	"go-todo/models"
+)
+
+// TestWorkspace_InviteAndPermissions 测试邀请成员以及不同角色对任务的权限
+func TestWorkspace_InviteAndPermissions(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	ws := &WorkspaceService{}
+	ts := newTestTodoService(db)
+
+	owner := createTestUser(t, "owner")
+	editor := createTestUser(t, "editor")
+	viewer := createTestUser(t, "viewer")
+	outsider := createTestUser(t, "outsider")
+
+	workspace, err := ws.Create(t.Context(), owner.ID, "研发组")
+	if err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+
+	// 按用户名邀请 editor
+	invite, err := ws.Invite(t.Context(), owner.ID, workspace.ID, "editor", models.WorkspaceEditor, 0)
+	if err != nil {
+		t.Fatalf("邀请失败: %v", err)
+	}
+	if _, err := ws.AcceptInvite(t.Context(), outsider.ID, invite.Token); err != ErrInviteInvalid {
+		t.Errorf("期望其他用户不能使用指定用户的邀请，但得到了 %v", err)
+	}
+	if _, err := ws.AcceptInvite(t.Context(), editor.ID, invite.Token); err != nil {
+		t.Fatalf("接受邀请失败: %v", err)
+	}
+
+	// 通过链接邀请 viewer
+	link, _ := ws.Invite(t.Context(), owner.ID, workspace.ID, "", models.WorkspaceViewer, 0)
+	if _, err := ws.AcceptInvite(t.Context(), viewer.ID, link.Token); err != nil {
+		t.Fatalf("通过链接加入失败: %v", err)
+	}
+
+	// editor 可以在工作区中创建任务
+	todo := &models.Todo{Title: "共享任务", WorkspaceID: &workspace.ID}
+	if err := ts.Create(t.Context(), editor.ID, todo); err != nil {
+		t.Fatalf("editor 创建任务失败: %v", err)
+	}
+
+	// viewer 可以看到，但不能修改和创建
+	todos, total, err := ts.List(t.Context(), viewer.ID, TodoQuery{WorkspaceID: &workspace.ID})
+	if err != nil || total != 1 || len(todos) != 1 {
+		t.Fatalf("期望 viewer 看到 1 条任务，得到 %d 条, %v", total, err)
+	}
+	todo.Title = "viewer 改的"
+	if err := ts.Update(t.Context(), viewer.ID, todo); err != ErrForbidden {
+		t.Errorf("期望 viewer 不能修改任务，但得到了 %v", err)
+	}
+	if err := ts.Create(t.Context(), viewer.ID, &models.Todo{Title: "x", WorkspaceID: &workspace.ID}); err != ErrForbidden {
+		t.Errorf("期望 viewer 不能创建任务，但得到了 %v", err)
+	}
+	if err := ts.Delete(t.Context(), viewer.ID, todo.PublicID); err != ErrForbidden {
+		t.Errorf("期望 viewer 不能删除任务，但得到了 %v", err)
+	}
+
+	// 非成员看不到工作区的任务
+	if _, _, err := ts.List(t.Context(), outsider.ID, TodoQuery{WorkspaceID: &workspace.ID}); err != ErrForbidden {
+		t.Errorf("期望非成员无法查看工作区任务，但得到了 %v", err)
+	}
+	if _, err := ts.GetByID(t.Context(), outsider.ID, todo.PublicID); err == nil {
+		t.Error("期望非成员无法获取工作区任务")
+	}
+
+	// 个人任务列表中不包含工作区任务
+	_, total, _ = ts.List(t.Context(), editor.ID, TodoQuery{})
+	if total != 0 {
+		t.Errorf("期望个人任务列表为空，但得到了 %d 条", total)
+	}
+
+	// owner 也可以修改 editor 创建的任务，创建者保持不变
+	todo.Title = "owner 改的"
+	if err := ts.Update(t.Context(), owner.ID, todo); err != nil {
+		t.Fatalf("owner 修改任务失败: %v", err)
+	}
+	saved, _ := ts.GetByID(t.Context(), owner.ID, todo.PublicID)
+	if saved.UserID != editor.ID || saved.Title != "owner 改的" {
+		t.Errorf("期望创建者仍为 editor 且标题已修改，但得到了 %+v", saved)
+	}
+}
+
+// TestWorkspace_TransferAndLeave 测试转让工作区和退出工作区
+func TestWorkspace_TransferAndLeave(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	ws := &WorkspaceService{}
+	as := &AccountService{}
+
+	owner := createTestUser(t, "owner")
+	member := createTestUser(t, "member")
+	workspace, _ := ws.Create(t.Context(), owner.ID, "研发组")
+	invite, _ := ws.Invite(t.Context(), owner.ID, workspace.ID, "member", models.WorkspaceEditor, 0)
+	ws.AcceptInvite(t.Context(), member.ID, invite.Token)
+
+	if err := ws.RemoveMember(t.Context(), owner.ID, workspace.ID, owner.ID); err == nil {
+		t.Error("期望拥有者不能直接退出工作区")
+	}
+	if err := as.Delete(t.Context(), owner.ID, "password123"); err != ErrOwnsSharedWorkspace {
+		t.Errorf("期望拥有共享工作区时不能注销，但得到了 %v", err)
+	}
+
+	if err := ws.SetMemberRole(t.Context(), owner.ID, workspace.ID, member.ID, models.WorkspaceOwner); err != nil {
+		t.Fatalf("转让工作区失败: %v", err)
+	}
+	got, _ := ws.Get(t.Context(), member.ID, workspace.ID)
+	if got.OwnerID != member.ID || got.Role != models.WorkspaceOwner {
+		t.Errorf("期望 member 成为拥有者，但得到了 %+v", got)
+	}
+
+	// 原拥有者现在可以退出，并注销账号
+	if err := ws.RemoveMember(t.Context(), owner.ID, workspace.ID, owner.ID); err != nil {
+		t.Fatalf("退出工作区失败: %v", err)
+	}
+	if err := as.Delete(t.Context(), owner.ID, "password123"); err != nil {
+		t.Errorf("期望转让后可以注销，但得到了 %v", err)
+	}
+	if list, _ := ws.List(t.Context(), member.ID); len(list) != 1 {
+		t.Errorf("期望工作区保留，但得到了 %d 个", len(list))
+	}
+}
+
+// TestAssignees 测试分配负责人及负责人过滤
+func TestAssignees(t *testing.T) {
+	db := setupTestDB()
+	config.DB = db
+	ws := &WorkspaceService{}
+	ts := newTestTodoService(db)
+
+	owner := createTestUser(t, "owner")
+	member := createTestUser(t, "member")
+	outsider := createTestUser(t, "outsider")
+	workspace, _ := ws.Create(t.Context(), owner.ID, "研发组")
+	invite, _ := ws.Invite(t.Context(), owner.ID, workspace.ID, "member", models.WorkspaceEditor, 0)
+	ws.AcceptInvite(t.Context(), member.ID, invite.Token)
+
+	shared := &models.Todo{Title: "共享任务", WorkspaceID: &workspace.ID}
+	ts.Create(t.Context(), owner.ID, shared)
+	ts.Create(t.Context(), owner.ID, &models.Todo{Title: "无人负责", WorkspaceID: &workspace.ID})
+	personal := &models.Todo{Title: "member 的个人任务"}
+	ts.Create(t.Context(), member.ID, personal)
+
+	if _, err := ts.Assign(t.Context(), owner.ID, shared.PublicID, outsider.ID); err != ErrInvalidAssignee {
+		t.Errorf("期望不能分配给非成员，但得到了 %v", err)
+	}
+	todo, err := ts.Assign(t.Context(), owner.ID, shared.PublicID, member.ID)
+	if err != nil {
+		t.Fatalf("分配失败: %v", err)
+	}
+	if len(todo.Assignees) != 1 || todo.Assignees[0].Username != "member" {
+		t.Fatalf("期望负责人为 member，但得到了 %+v", todo.Assignees)
+	}
+	// 重复分配不会产生重复记录
+	todo, _ = ts.Assign(t.Context(), owner.ID, shared.PublicID, member.ID)
+	if len(todo.Assignees) != 1 {
+		t.Errorf("期望重复分配后仍只有 1 个负责人，但得到了 %d", len(todo.Assignees))
+	}
+
+	if _, err := ts.Assign(t.Context(), member.ID, personal.PublicID, owner.ID); err != ErrInvalidAssignee {
+		t.Errorf("期望个人任务只能分配给自己，但得到了 %v", err)
+	}
+	ts.Assign(t.Context(), member.ID, personal.PublicID, member.ID)
+
+	// 跨工作区查询分配给 member 的任务
+	todos, total, err := ts.ListAssigned(t.Context(), member.ID, TodoQuery{})
+	if err != nil || total != 2 {
+		t.Fatalf("期望 member 负责 2 条任务，得到 %d 条, %v", total, err)
+	}
+	for _, td := range todos {
+		if td.ID == shared.ID && len(td.Assignees) != 1 {
+			t.Error("期望列表中预加载负责人")
+		}
+	}
+
+	// 工作区中没有负责人的任务
+	todos, _, _ = ts.List(t.Context(), owner.ID, TodoQuery{WorkspaceID: &workspace.ID, Unassigned: true})
+	if len(todos) != 1 || todos[0].Title != "无人负责" {
+		t.Errorf("期望只返回无人负责的任务，但得到了 %v", todos)
+	}
+
+	// 成员离开工作区后分配被取消
+	ws.RemoveMember(t.Context(), owner.ID, workspace.ID, member.ID)
+	_, total, _ = ts.ListAssigned(t.Context(), member.ID, TodoQuery{})
+	if total != 1 {
+		t.Errorf("期望离开工作区后只剩个人任务，但得到了 %d 条", total)
+	}
+
+	todo, err = ts.Unassign(t.Context(), member.ID, personal.PublicID, member.ID)
+	if err != nil || len(todo.Assignees) != 0 {
+		t.Errorf("取消分配失败: %+v, %v", todo.Assignees, err)
+	}
+}
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 本服务创建的 span 的来源
+const instrumentationName = "go-todo"
+
+// 导出方式
+const (
+	// ExporterNone 不导出，只传递 traceparent
+	ExporterNone = "none"
+	// ExporterOTLP 通过 OTLP/HTTP 发送给 Collector 或 Jaeger、Tempo 等后端
+	ExporterOTLP = "otlp"
+	// ExporterStdout 以 JSON 打印到标准输出，用于本地调试
+	ExporterStdout = "stdout"
+)
+
+// Config 链路追踪配置
+type Config struct {
+	// Exporter 导出方式：none、otlp 或 stdout
+	Exporter string
+	// Endpoint OTLP/HTTP 地址，如 "localhost:4318"；为空时使用 OTEL_EXPORTER_OTLP_ENDPOINT 或默认地址
+	Endpoint string
+	// Insecure 使用 HTTP 而不是 HTTPS 连接 Endpoint
+	Insecure bool
+	// SampleRatio 没有上游决定时的采样比例，0 到 1
+	SampleRatio float64
+	// ServiceName、ServiceVersion 写入每个 span 的 resource
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Setup 设置全局的 TracerProvider 和 W3C traceparent/baggage 传播器。
+// 返回的 shutdown 在退出前调用，发送缓冲中的 span
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		err = fmt.Errorf("不支持的链路追踪导出方式: %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// 上游已经决定采样时跟随上游，保证一条链路要么完整要么没有
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer 本服务使用的 Tracer，Setup 之前获取的也会使用之后设置的 TracerProvider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 开始一个子 span，调用方负责 span.End()
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// RecordError 把错误记录到 span 上并标记为失败，err 为 nil 时什么也不做
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
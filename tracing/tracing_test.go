@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+// TestInstrumentGORM 每条 SQL 是请求 span 的子 span，带有表名和不含参数值的 SQL
+func TestInstrumentGORM(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InstrumentGORM(db); err != nil {
+		t.Fatal(err)
+	}
+	db.AutoMigrate(&widget{})
+
+	ctx, root := Start(t.Context(), "GET /widgets")
+	db.WithContext(ctx).Create(&widget{Name: "secret-value"})
+	db.WithContext(ctx).Table("widgets").Where("missing = 1").Find(&[]widget{})
+	root.End()
+
+	var children []sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Parent().SpanID() == root.SpanContext().SpanID() {
+			children = append(children, s)
+		}
+	}
+	if len(children) != 2 {
+		t.Fatalf("期望 2 个 SQL span 挂在请求下，但得到了 %d 个", len(children))
+	}
+
+	create := children[0]
+	if create.Name() != "gorm.create widgets" {
+		t.Errorf("期望 span 名称为 gorm.create widgets，但得到了 %s", create.Name())
+	}
+	attrs := map[string]string{}
+	for _, kv := range create.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs[string(semconv.DBCollectionNameKey)] != "widgets" || attrs[string(semconv.DBSystemNameKey)] != "sqlite" {
+		t.Errorf("缺少表名或数据库类型: %v", attrs)
+	}
+	if sql := attrs[string(semconv.DBQueryTextKey)]; sql == "" || strings.Contains(sql, "secret-value") {
+		t.Errorf("SQL 应该使用占位符，但得到了 %q", sql)
+	}
+	if create.Status().Code == codes.Error {
+		t.Errorf("成功的 SQL 不应该标记为失败")
+	}
+	if children[1].Status().Code != codes.Error {
+		t.Errorf("出错的 SQL 应该标记为失败")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	if _, err := Setup(t.Context(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("不支持的导出方式应该返回错误")
+	}
+	shutdown, err := Setup(t.Context(), Config{Exporter: ExporterStdout, SampleRatio: 1, ServiceName: "go-todo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(t.Context()); err != nil {
+		t.Error(err)
+	}
+}
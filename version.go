@@ -0,0 +1,30 @@
+package main
+
+import (
+	"go-todo/service"
+	"runtime/debug"
+)
+
+// 构建信息，发布时通过 -ldflags 注入，例如：
+//
+//	go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildTime = ""
+)
+
+// buildInfo 没有注入 commit 时使用 go build 自动记录的 Git 版本
+func buildInfo() service.BuildInfo {
+	info := service.BuildInfo{Version: version, Commit: commit, BuildTime: buildTime}
+	if info.Commit == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range bi.Settings {
+				if s.Key == "vcs.revision" {
+					info.Commit = s.Value
+				}
+			}
+		}
+	}
+	return info
+}